@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // часовые пояса для ?tz= даже без tzdata в образе
 
 	server "github.com/AronditFire/todo-app"
 	"github.com/AronditFire/todo-app/internal/cache"
@@ -13,6 +15,7 @@ import (
 	"github.com/AronditFire/todo-app/internal/handlers"
 	"github.com/AronditFire/todo-app/internal/repository"
 	"github.com/AronditFire/todo-app/internal/service"
+	"github.com/AronditFire/todo-app/internal/storage"
 	"github.com/AronditFire/todo-app/internal/utils"
 	"github.com/joho/godotenv"
 )
@@ -45,11 +48,16 @@ func main() {
 
 	repo := repository.NewRepository(database)
 	crepo := cache.NewRedisRepository(rdb, repo)
-	srv := service.NewService(crepo, repo, os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"), os.Getenv("REDIRECT_URL"))
+	srv := service.NewService(crepo, repo, blobStorage(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"), os.Getenv("REDIRECT_URL"), trashRetention())
 	handler := handlers.NewHander(srv)
 
 	healthHandlerFunc := utils.NewChecker(database, rdb)
 
+	// корзина чистится фоном раз в час
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	go service.RunTrashPurge(purgeCtx, srv.Trash, time.Hour)
+	go service.RunChangePrune(purgeCtx, srv.TaskList, time.Hour)
+
 	server := new(server.Server)
 	go func() {
 		log.Printf("Starting server at port: %s", string(os.Getenv("PORT")))
@@ -64,6 +72,8 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopPurge()
+	handler.CloseStreams()
 
 	if err := server.Shutdown(context.Background()); err != nil {
 		log.Fatalf("Failed to shutdown the server: %v", err)
@@ -80,3 +90,51 @@ func main() {
 
 	log.Println("Database connection successfully stopped")
 }
+
+// trashRetention reads TRASH_RETENTION (e.g. 720h), falls back to 30 days.
+func trashRetention() time.Duration {
+	raw := os.Getenv("TRASH_RETENTION")
+	if raw == "" {
+		return service.DefaultTrashRetention
+	}
+
+	retention, err := time.ParseDuration(raw)
+	if err != nil || retention <= 0 {
+		log.Fatalf("Invalid TRASH_RETENTION value: %q", raw)
+	}
+
+	return retention
+}
+
+// blobStorage picks the attachment storage by STORAGE_DRIVER: "local" (default)
+// keeps files in STORAGE_DIR, "s3" uses any S3 compatible service such as MinIO.
+func blobStorage() storage.Storage {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "./attachments"
+		}
+
+		store, err := storage.NewLocalStorage(dir)
+		if err != nil {
+			log.Fatalf("Could not init local storage: %v", err)
+		}
+		return store
+	case "s3":
+		store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			log.Fatalf("Could not init S3 storage: %v", err)
+		}
+		return store
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER: %q", driver)
+		return nil
+	}
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// TaskAssignee marks a member of the task's project as responsible for it,
+// a task can have several assignees.
+type TaskAssignee struct {
+	TaskID    int       `gorm:"primaryKey;autoIncrement:false" json:"task_id"`
+	UserID    int       `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// AssignRequest replaces the assignees of a task, an empty list unassigns everybody.
+type AssignRequest struct {
+	UserIDs []int `json:"user_ids"`
+}
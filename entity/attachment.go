@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrFileTooLarge = errors.New("File is too large")
+	ErrFileType     = errors.New("File type is not allowed")
+)
+
+// Attachment is a file attached to a task. The content lives in the blob
+// storage under StorageKey, the row only describes it.
+type Attachment struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	TaskID      int       `gorm:"not null;index" json:"task_id"`
+	UserID      int       `gorm:"not null" json:"user_id"` // кто загрузил
+	Name        string    `gorm:"size:255;not null" json:"name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// PurgedBlob is a blob whose attachment is already deleted from the database.
+// It is queued in the same transaction and removed from the storage afterwards,
+// so a failed storage call is retried instead of leaving the file behind.
+type PurgedBlob struct {
+	StorageKey string    `gorm:"primaryKey;size:255"`
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
@@ -0,0 +1,72 @@
+package entity
+
+import "encoding/json"
+
+// BatchOp is the kind of one operation of POST /api/batch.
+type BatchOp string
+
+const (
+	BatchCreate   BatchOp = "create"
+	BatchUpdate   BatchOp = "update"
+	BatchDelete   BatchOp = "delete"
+	BatchComplete BatchOp = "complete"
+)
+
+func (o BatchOp) IsValid() bool {
+	switch o {
+	case BatchCreate, BatchUpdate, BatchDelete, BatchComplete:
+		return true
+	}
+	return false
+}
+
+// BatchMode decides what happens to the batch when one of its operations fails.
+type BatchMode string
+
+const (
+	BatchAtomic     BatchMode = "atomic"      // всё или ничего, по умолчанию
+	BatchBestEffort BatchMode = "best_effort" // неудачные операции пропускаются, остальные применяются
+)
+
+func (m BatchMode) IsValid() bool {
+	switch m {
+	case "", BatchAtomic, BatchBestEffort:
+		return true
+	}
+	return false
+}
+
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode" enums:"atomic,best_effort"`
+	Operations []BatchOperation `json:"operations" binding:"required"`
+}
+
+// BatchOperation is one item of a batch. Create takes Task, update takes
+// a merge patch like PATCH /api/:id, the others only the task id.
+type BatchOperation struct {
+	Op      BatchOp         `json:"op" enums:"create,update,delete,complete"`
+	ID      int             `json:"id,omitempty"`
+	Version int             `json:"version,omitempty"` // как If-Match для update, delete и complete, 0 - без проверки
+	Task    *Task           `json:"task,omitempty"`
+	Patch   json.RawMessage `json:"patch,omitempty" swaggertype:"object"`
+}
+
+// BatchStatus is the outcome of one operation.
+type BatchStatus string
+
+const (
+	BatchOK      BatchStatus = "ok"
+	BatchFailed  BatchStatus = "failed"
+	BatchSkipped BatchStatus = "skipped" // не применена: атомарный пакет откатился из-за другой операции
+)
+
+// BatchResult reports one operation of the batch, in the order of the request.
+// ID is the created task for create and the next occurrence for complete.
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Op     BatchOp     `json:"op"`
+	Status BatchStatus `json:"status"`
+	ID     int         `json:"id,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Fields FieldErrors `json:"fields,omitempty"`
+}
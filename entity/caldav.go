@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnauthorized is returned for a wrong user name or app password.
+var ErrUnauthorized = errors.New("Invalid user name or app password")
+
+// AppPassword lets a CalDAV client sign in with HTTP Basic: clients can not
+// do the JWT flow, and the account password should not be stored in them.
+// Only the SHA-256 of the password is kept, the password is shown once.
+type AppPassword struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    int       `gorm:"not null;index" json:"-"`
+	Name      string    `gorm:"size:100;not null" json:"name"` // на каком устройстве, задаёт пользователь
+	Hash      string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+type AppPasswordRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AppPasswordResponse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// CalendarObject remembers the resource name and UID a CalDAV client chose
+// for a task it created. Other tasks are served as "task-<id>.ics" with a
+// UID made from the id. The row goes away when the task moves to the trash.
+type CalendarObject struct {
+	TaskID int    `gorm:"primaryKey;autoIncrement:false"`
+	UserID int    `gorm:"not null;uniqueIndex:idx_calendar_objects_user_name"`
+	Name   string `gorm:"size:255;not null;uniqueIndex:idx_calendar_objects_user_name"`
+	UID    string `gorm:"size:255;not null"`
+}
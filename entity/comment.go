@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Comment is a message in the thread of a task. Only the author can edit
+// it, the author and the owner of the task can delete it.
+type Comment struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	TaskID    int        `gorm:"not null;index" json:"task_id"`
+	UserID    int        `gorm:"not null" json:"user_id"` // автор
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"` // nil - не редактировался
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentCursor remembers the last comment of a page, comments go oldest first.
+type CommentCursor struct {
+	ID int `json:"i"`
+}
+
+func (c CommentCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCommentCursor(s string) (CommentCursor, error) {
+	var c CommentCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return CommentCursor{}, errors.New("malformed cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID <= 0 {
+		return CommentCursor{}, errors.New("malformed cursor")
+	}
+
+	return c, nil
+}
+
+// CommentPage is one page of a thread, NextCursor is empty on the last page.
+type CommentPage struct {
+	Comments   []Comment
+	NextCursor string
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// TaskCursor remembers the sort key of the last task of a page, the next
+// page starts right after it. Clients get it as an opaque string.
+type TaskCursor struct {
+	Order    TaskOrder  `json:"o"`
+	Desc     bool       `json:"d,omitempty"`
+	ID       int        `json:"i"`
+	Position float64    `json:"p,omitempty"`
+	Priority int        `json:"r,omitempty"`
+	Time     *time.Time `json:"t,omitempty"` // created_at или due_at, nil - срока нет
+}
+
+func NewTaskCursor(task Task, order TaskOrder, desc bool) TaskCursor {
+	c := TaskCursor{
+		Order: order.OrDefault(),
+		Desc:  desc,
+		ID:    task.ID,
+	}
+
+	switch c.Order {
+	case OrderPosition:
+		c.Position = task.Position
+	case OrderPriority:
+		c.Priority = task.Priority
+		c.Position = task.Position
+	case OrderCreatedAt:
+		createdAt := task.CreatedAt
+		c.Time = &createdAt
+	case OrderDueAt:
+		c.Time = task.DueAt
+	}
+
+	return c
+}
+
+func (c TaskCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeTaskCursor(s string) (TaskCursor, error) {
+	var c TaskCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TaskCursor{}, errors.New("malformed cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID <= 0 || !c.Order.IsValid() {
+		return TaskCursor{}, errors.New("malformed cursor")
+	}
+
+	return c, nil
+}
+
+// Matches reports whether the cursor was issued for the same sort order.
+func (c TaskCursor) Matches(order TaskOrder, desc bool) bool {
+	return c.Order == order.OrDefault() && c.Desc == desc
+}
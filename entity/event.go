@@ -0,0 +1,18 @@
+package entity
+
+// TaskEventType is the event name of a task event in GET /api/events.
+type TaskEventType string
+
+const (
+	EventTaskCreated TaskEventType = "task.created"
+	EventTaskUpdated TaskEventType = "task.updated"
+	EventTaskDeleted TaskEventType = "task.deleted"
+)
+
+// TaskEvent is one event of the task event stream. Task is the task after
+// the change, a deleted task comes with its id only.
+type TaskEvent struct {
+	Type   TaskEventType `json:"-"`
+	TaskID int           `json:"id"`
+	Task   *Task         `json:"task,omitempty"`
+}
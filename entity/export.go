@@ -0,0 +1,19 @@
+package entity
+
+// ExportFormat is the file format of GET /api/export.
+type ExportFormat string
+
+const (
+	ExportCSV      ExportFormat = "csv"  // те же колонки, что понимает импорт csv
+	ExportJSON     ExportFormat = "json" // массив задач как в GET /api/
+	ExportMarkdown ExportFormat = "md"   // список с чекбоксами
+	ExportICS      ExportFormat = "ics"  // iCalendar, VTODO для задач со сроком
+)
+
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportCSV, ExportJSON, ExportMarkdown, ExportICS:
+		return true
+	}
+	return false
+}
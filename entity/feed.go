@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFeedNotFound is returned for a feed token that was never issued or was revoked.
+var ErrFeedNotFound = errors.New("Feed not found")
+
+// FeedToken is the secret part of the user's calendar feed URL. Only the
+// SHA-256 of the token is stored, the token itself is shown once.
+type FeedToken struct {
+	UserID    int       `gorm:"primaryKey;autoIncrement:false"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// Feed is a rendered calendar feed with what conditional requests compare.
+type Feed struct {
+	ETag       string    `json:"etag"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Body       []byte    `json:"body"`
+}
+
+type FeedTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"` // путь от корня сервера, /feeds/<token>.ics
+}
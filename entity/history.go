@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type TaskAction string
+
+const (
+	ActionCreated  TaskAction = "created"
+	ActionUpdated  TaskAction = "updated"
+	ActionDeleted  TaskAction = "deleted"  // задача ушла в корзину
+	ActionRestored TaskAction = "restored" // задача вернулась из корзины
+	ActionAssigned TaskAction = "assigned" // поменялись исполнители
+)
+
+// TaskHistory is an immutable record of one change of a task. Before and
+// After hold only the fields that changed, for a created task Before is
+// null and After is the whole task, for a deleted one it is the other way round,
+// a restored task is recorded like a created one.
+// An assignment change holds the old and the new list of assignees.
+type TaskHistory struct {
+	ID        int             `gorm:"primaryKey" json:"id"`
+	TaskID    int             `gorm:"not null;index" json:"task_id"`
+	UserID    int             `gorm:"not null" json:"user_id"` // кто внёс изменение
+	Action    TaskAction      `gorm:"size:20;not null" json:"action"`
+	Before    json.RawMessage `gorm:"type:jsonb" json:"before" swaggertype:"object"`
+	After     json.RawMessage `gorm:"type:jsonb" json:"after" swaggertype:"object"`
+	CreatedAt time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
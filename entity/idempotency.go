@@ -0,0 +1,18 @@
+package entity
+
+import "errors"
+
+var (
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key was already used with another request")
+	ErrIdempotencyInProgress = errors.New("Request with this Idempotency-Key is still in progress")
+)
+
+// IdempotentResponse is a response stored under an Idempotency-Key to be
+// replayed on a retry. Pending means the first request is still running.
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Pending     bool   `json:"pending,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "errors"
+
+// ErrImportFile means the uploaded export can not be read as a whole.
+var ErrImportFile = errors.New("Could not read import file")
+
+// ImportFormat is the tool an imported file was exported from.
+type ImportFormat string
+
+const (
+	ImportCSV     ImportFormat = "csv"     // своя таблица: title, due_date, priority, labels, completed, checklist
+	ImportTodoist ImportFormat = "todoist" // CSV-выгрузка проекта Todoist
+	ImportTrello  ImportFormat = "trello"  // JSON-выгрузка доски Trello
+)
+
+func (f ImportFormat) IsValid() bool {
+	switch f {
+	case ImportCSV, ImportTodoist, ImportTrello:
+		return true
+	}
+	return false
+}
+
+// ImportTask is a task read from an export together with what is stored
+// apart from the task row: labels become tags (missing ones are created),
+// checklist items become subtasks.
+type ImportTask struct {
+	Row      int      `json:"row"` // строка CSV или номер карточки Trello
+	Task     Task     `json:"task"`
+	Labels   []string `json:"labels,omitempty"`
+	Subtasks []Task   `json:"subtasks,omitempty"`
+}
+
+// ImportError is a row of the file that was not imported.
+type ImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport lists the tasks that were (or with DryRun would be) created
+// and the rows that were skipped.
+type ImportReport struct {
+	DryRun bool          `json:"dry_run"`
+	Tasks  []ImportTask  `json:"tasks"`
+	Errors []ImportError `json:"errors"`
+}
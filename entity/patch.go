@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrPatchDocument is returned for a merge patch that is not a JSON object.
+var ErrPatchDocument = errors.New("Merge patch must be a JSON object")
+
+// FieldErrors is a field level validation report: JSON name of the field -> problem.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	problems := make([]string, len(fields))
+	for i, field := range fields {
+		problems[i] = field + ": " + e[field]
+	}
+
+	return "Invalid fields: " + strings.Join(problems, "; ")
+}
+
+// PatchField is one member of a merge patch. Set is false when the member is
+// missing from the document, a set field with nil Value is null.
+type PatchField[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	f.Value = nil
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	f.Value = &value
+
+	return nil
+}
+
+// TaskPatch is a JSON merge patch (RFC 7396) of a task: only the members
+// present in the document are changed, null clears the field.
+type TaskPatch struct {
+	Description PatchField[string]
+	Status      PatchField[TaskStatus]
+	DueAt       PatchField[time.Time]
+	RemindAt    PatchField[time.Time]
+	Priority    PatchField[int]
+	ProjectID   PatchField[int]
+	ParentID    PatchField[int]
+	Recurrence  PatchField[string]
+}
+
+// readOnlyTaskFields are members of a task that exist but can not be patched:
+// they are computed or have their own endpoints (move, tags).
+var readOnlyTaskFields = map[string]bool{
+	"id":            true,
+	"UserID":        true,
+	"completed_at":  true,
+	"position":      true,
+	"progress":      true,
+	"tags":          true,
+	"created_at":    true,
+	"version":       true,
+	"deleted_at":    true,
+	"comment_count": true,
+}
+
+// ParseTaskPatch decodes a merge patch document. Unknown and read-only members
+// and values of a wrong type are reported all at once as FieldErrors.
+func ParseTaskPatch(doc []byte) (TaskPatch, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &members); err != nil || members == nil {
+		return TaskPatch{}, ErrPatchDocument
+	}
+
+	var patch TaskPatch
+	fields := map[string]json.Unmarshaler{
+		"description": &patch.Description,
+		"status":      &patch.Status,
+		"due_at":      &patch.DueAt,
+		"remind_at":   &patch.RemindAt,
+		"priority":    &patch.Priority,
+		"project_id":  &patch.ProjectID,
+		"parent_id":   &patch.ParentID,
+		"recurrence":  &patch.Recurrence,
+	}
+
+	problems := FieldErrors{}
+	for name, raw := range members {
+		field, ok := fields[name]
+		switch {
+		case readOnlyTaskFields[name]:
+			problems[name] = "field is read-only"
+		case !ok:
+			problems[name] = "unknown field"
+		case field.UnmarshalJSON(raw) != nil:
+			problems[name] = "invalid value type"
+		}
+	}
+
+	if len(problems) > 0 {
+		return TaskPatch{}, problems
+	}
+
+	return patch, nil
+}
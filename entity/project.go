@@ -0,0 +1,36 @@
+package entity
+
+type Project struct {
+	ID       int    `gorm:"primaryKey" json:"id"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	Color    string `gorm:"size:7" json:"color"`
+	Archived bool   `gorm:"not null;default:false" json:"archived"`
+	UserID   int    `gorm:"not null;index" json:"-"`
+}
+
+type ProjectRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Color    string `json:"color" example:"#ff8800"`
+	Archived bool   `json:"archived"`
+}
+
+// TaskProjectRequest moves a task into a project, null moves it back to inbox.
+type TaskProjectRequest struct {
+	ProjectID *int `json:"project_id"`
+}
+
+// ProjectDeleteMode tells what happens to tasks of a deleted project.
+type ProjectDeleteMode string
+
+const (
+	ProjectDeleteCascade ProjectDeleteMode = "cascade" // удалить задачи вместе с проектом
+	ProjectDeleteMove    ProjectDeleteMode = "move"    // перенести задачи в другой проект или во входящие
+)
+
+func (m ProjectDeleteMode) IsValid() bool {
+	switch m {
+	case ProjectDeleteCascade, ProjectDeleteMove:
+		return true
+	}
+	return false
+}
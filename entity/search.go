@@ -0,0 +1,14 @@
+package entity
+
+import "errors"
+
+// ErrSearchQuery is returned for a search query that is empty or too long.
+var ErrSearchQuery = errors.New("Invalid search query length")
+
+// TaskSearchResult is a task found by full-text search. Snippet is HTML: a
+// part of the escaped description with matches wrapped into <mark></mark>.
+type TaskSearchResult struct {
+	Task
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
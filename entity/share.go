@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when an item does not exist or the user can not
+// see it. It is the error of gorm, so a row that is not found reports it too.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// ErrForbidden is returned when the user can see a task or a project
+// but their role does not allow the action.
+var ErrForbidden = errors.New("Not enough permissions")
+
+// Role is the access level of a user to a task or a project. The author
+// is always the owner, other users get a role when the item is shared.
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // только чтение
+	RoleEditor Role = "editor" // может менять задачи
+	RoleOwner  Role = "owner"  // может удалять и делиться
+)
+
+func (r Role) IsValid() bool {
+	return r.Level() > 0
+}
+
+// Level orders roles, 0 means no access. The same numbers are used by the
+// project_access view and the task_access_for and task_audience functions.
+func (r Role) Level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	}
+	return 0
+}
+
+// Allows reports whether the role is enough for an action that needs the given one.
+func (r Role) Allows(need Role) bool {
+	return r.Level() >= need.Level()
+}
+
+// RoleByLevel is the inverse of Level, "" for no access.
+func RoleByLevel(level int) Role {
+	switch {
+	case level >= 3:
+		return RoleOwner
+	case level == 2:
+		return RoleEditor
+	case level == 1:
+		return RoleViewer
+	}
+	return ""
+}
+
+// TaskShare gives a user access to a task and all of its subtasks.
+type TaskShare struct {
+	TaskID    int       `gorm:"primaryKey;autoIncrement:false" json:"task_id"`
+	UserID    int       `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	Role      Role      `gorm:"size:20;not null" json:"role"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// ProjectShare gives a user access to a project and all of its tasks.
+type ProjectShare struct {
+	ProjectID int       `gorm:"primaryKey;autoIncrement:false" json:"project_id"`
+	UserID    int       `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	Role      Role      `gorm:"size:20;not null" json:"role"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// ShareRequest grants a role to another user, sharing again changes the role.
+type ShareRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     Role   `json:"role" binding:"required" example:"editor"`
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// SyncToken marks how far a client has synced: the oldest transaction that
+// was still running when the previous delta was read. Every change of a later
+// transaction is returned next time, so a commit that lands late is never
+// skipped, at the cost of sending some changes twice. Zero means a full sync.
+type SyncToken int64
+
+// ErrSyncTokenExpired is returned for a token older than the change log
+// keeps, the client has to sync from scratch.
+var ErrSyncTokenExpired = errors.New("sync token expired")
+
+type syncToken struct {
+	XID int64 `json:"x"`
+}
+
+func (t SyncToken) Encode() string {
+	raw, _ := json.Marshal(syncToken{XID: int64(t)})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeSyncToken(s string) (SyncToken, error) {
+	var t syncToken
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("malformed sync token")
+	}
+	if err := json.Unmarshal(raw, &t); err != nil || t.XID <= 0 {
+		return 0, errors.New("malformed sync token")
+	}
+
+	return SyncToken(t.XID), nil
+}
+
+// TaskChanges is what changed for a user since a token: tasks to add or
+// replace, ids of tasks that went to the trash, were purged or are no longer
+// shared with the user, and the token of this read. Created are the ids of
+// Tasks created since the token.
+type TaskChanges struct {
+	Tasks   []Task
+	Created []int
+	Deleted []int
+	Token   SyncToken
+}
+
+// SyncResponse is the answer of GET /api/sync. A client replaces its copies
+// of Tasks, drops Deleted and sends Token next time. Created are the ids of
+// Tasks created since the token. The lists may repeat changes the client
+// already has.
+type SyncResponse struct {
+	Tasks   []Task `json:"tasks"`
+	Created []int  `json:"created"`
+	Deleted []int  `json:"deleted"`
+	Token   string `json:"token"`
+}
+
+// SyncPushRequest carries the changes a client made offline, in the order
+// they were made. They are operations of POST /api/batch, update, delete
+// and complete should carry the version the client saw.
+type SyncPushRequest struct {
+	Changes []BatchOperation `json:"changes" binding:"required"`
+}
+
+// SyncConflict is the status of a change made against an older version of the task.
+const SyncConflict BatchStatus = "conflict"
+
+// SyncResult reports one pushed change. For a conflict Task is the server's
+// copy, the client merges its change into it and pushes again with the new
+// version. Task is empty when the task is gone meanwhile.
+type SyncResult struct {
+	BatchResult
+	Task *Task `json:"task,omitempty"`
+}
+
+type SyncPushResponse struct {
+	Results []SyncResult `json:"results"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+type Tag struct {
+	ID     int    `gorm:"primaryKey" json:"id"`
+	Name   string `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	UserID int    `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"-"`
+}
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// TagMatch tells how GetAllTask combines several tags in a filter.
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any" // хотя бы один из тегов, по умолчанию
+	TagMatchAll TagMatch = "all" // все теги сразу
+)
+
+func (m TagMatch) IsValid() bool {
+	return m == "" || m == TagMatchAny || m == TagMatchAll
+}
@@ -1,11 +1,157 @@
 package entity
 
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusDone       TaskStatus = "done"
+	StatusCancelled  TaskStatus = "cancelled"
+)
+
+func (s TaskStatus) IsValid() bool {
+	switch s {
+	case StatusTodo, StatusInProgress, StatusDone, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsClosed reports whether the task is finished one way or another.
+func (s TaskStatus) IsClosed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+const (
+	PriorityHighest = 1 // P1
+	PriorityLowest  = 4 // P4, по умолчанию
+)
+
+// TaskOrder is the ordering of GetAllTask results.
+type TaskOrder string
+
+const (
+	OrderPosition  TaskOrder = "position" // ручной порядок пользователя, по умолчанию
+	OrderPriority  TaskOrder = "priority" // P1 сверху, внутри приоритета ручной порядок
+	OrderID        TaskOrder = "id"
+	OrderCreatedAt TaskOrder = "created_at"
+	OrderDueAt     TaskOrder = "due_at" // задачи без срока всегда в конце
+)
+
+func (o TaskOrder) IsValid() bool {
+	switch o {
+	case "", OrderPosition, OrderPriority, OrderID, OrderCreatedAt, OrderDueAt:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the order that is applied when none is given.
+func (o TaskOrder) OrDefault() TaskOrder {
+	if o == "" {
+		return OrderPosition
+	}
+	return o
+}
+
+// Due date errors, PUT /api/{id}/due answers them with 400.
+var (
+	ErrInvalidDue       = errors.New("Invalid due date")
+	ErrReminderNoDue    = errors.New("Reminder requires a due date")
+	ErrReminderAfterDue = errors.New("Reminder must not be later than due date")
+	ErrRecurrenceNoDue  = errors.New("Recurring task requires a due date")
+)
+
+// ErrVersionMismatch is returned when a request names a version of the task
+// (If-Match) that is not the current one anymore.
+var ErrVersionMismatch = errors.New("Task was changed by someone else")
+
 type Task struct {
-	ID          int    `gorm:"primaryKey" json:"id" redis:"id"`
-	Description string `json:"description" binding:"required" redis:"description"`
-	UserID      int    `gorm:"not null" redis:"user_id"`
+	ID          int        `gorm:"primaryKey" json:"id" redis:"id"`
+	Description string     `json:"description" binding:"required" redis:"description"`
+	UserID      int        `gorm:"not null" redis:"user_id"`
+	Status      TaskStatus `gorm:"size:20;not null;default:todo;index" json:"status" redis:"status"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" redis:"completed_at"`
+	DueAt       *time.Time `gorm:"index" json:"due_at,omitempty" redis:"due_at"`
+	RemindAt    *time.Time `json:"remind_at,omitempty" redis:"remind_at"`
+	Priority    int        `gorm:"not null;default:4" json:"priority" redis:"priority"`
+	Position    float64    `gorm:"not null;default:0;index" json:"position" redis:"position"`
+	ProjectID   *int       `gorm:"index" json:"project_id,omitempty" redis:"project_id"`    // nil - входящие
+	ParentID    *int       `gorm:"index" json:"parent_id,omitempty" redis:"parent_id"`      // nil - задача верхнего уровня
+	Progress    *int       `gorm:"-" json:"progress,omitempty" redis:"-"`                   // % выполненных подзадач, считается в service
+	Recurrence  string     `gorm:"size:255" json:"recurrence,omitempty" redis:"recurrence"` // RRULE, отсчитывается от due_at в UTC
+	Tags        []Tag      `gorm:"many2many:task_tags;" json:"tags,omitempty" redis:"-"`
+	CreatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"created_at" redis:"created_at"`
+	// растёт при каждом изменении строки (триггер в БД), отдаётся как ETag
+	Version int `gorm:"not null;default:1" json:"version" redis:"version"`
+	// задача в корзине, gorm сам прячет такие строки из обычных запросов
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"string" format:"date-time" redis:"-"`
+	// колонки нет, число считается подзапросом при чтении задач
+	CommentCount int `gorm:"->;-:migration" json:"comment_count" redis:"-"`
 }
 
 type TaskRequest struct {
 	Description string `json:"description" binding:"required"`
 }
+
+type TaskStatusRequest struct {
+	Status TaskStatus `json:"status" binding:"required"`
+}
+
+// TaskDueRequest sets or clears (null) due date and reminder of a task.
+// Times must be RFC 3339 with an explicit offset, e.g. 2025-05-01T18:00:00+03:00.
+type TaskDueRequest struct {
+	DueAt    *time.Time `json:"due_at"`
+	RemindAt *time.Time `json:"remind_at"`
+}
+
+type TaskPriorityRequest struct {
+	Priority int `json:"priority" binding:"required,min=1,max=4"`
+}
+
+// TaskMoveRequest places a task right before or right after another one,
+// exactly one of the ids must be set.
+type TaskMoveRequest struct {
+	BeforeID int `json:"before_id"`
+	AfterID  int `json:"after_id"`
+}
+
+// TaskRecurrenceRequest sets an RFC 5545 rule like "FREQ=WEEKLY;BYDAY=MO,WE",
+// empty string stops the recurrence. The task must have a due date.
+type TaskRecurrenceRequest struct {
+	Recurrence string `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+}
+
+// TaskParentRequest makes a task a subtask of another one, null makes it top level again.
+type TaskParentRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+// TaskFilter narrows down GetAllTask, zero value means "everything".
+type TaskFilter struct {
+	Status    TaskStatus
+	DueFrom   time.Time // due_at >= DueFrom
+	DueTo     time.Time // due_at < DueTo
+	OnlyOpen  bool      // skip done and cancelled tasks
+	ProjectID int       // 0 - tasks of all projects and inbox
+	ParentID  int       // direct subtasks of the task, 0 - no filter
+	TagIDs    []int
+	TagMatch  TagMatch
+	Order     TaskOrder
+	Desc      bool
+	Limit     int         // 0 - без ограничения
+	After     *TaskCursor // продолжить с задачи, на которой закончилась прошлая страница
+}
+
+// TaskPage is one page of GetAllTask, NextCursor is empty on the last page.
+type TaskPage struct {
+	Tasks      []Task
+	NextCursor string
+}
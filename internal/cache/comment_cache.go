@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// CommentCache сами комментарии не кеширует, но у задач в хэшах
+// "user:%d:tasks" и "user:%d:lists" хранится comment_count, их надо сбрасывать.
+type CommentCache struct {
+	rdb   *redis.Client
+	repo  repository.Comments
+	tasks *TaskCache
+}
+
+func NewCommentCache(rdb *redis.Client, repo repository.Comments, tasks *TaskCache) *CommentCache {
+	return &CommentCache{
+		rdb:   rdb,
+		repo:  repo,
+		tasks: tasks,
+	}
+}
+
+func (r *CommentCache) GetComments(userID, taskID, afterID, limit int) ([]entity.Comment, error) {
+	return r.repo.GetComments(userID, taskID, afterID, limit)
+}
+
+func (r *CommentCache) CreateComment(userID, taskID int, body string) (int, error) {
+	id, err := r.repo.CreateComment(userID, taskID, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create comment in repository: %w", err)
+	}
+
+	return id, r.tasks.invalidateAudience(userID, taskID)
+}
+
+// UpdateComment не меняет число комментариев, кеш трогать не нужно.
+func (r *CommentCache) UpdateComment(userID, taskID, commentID int, body string) error {
+	return r.repo.UpdateComment(userID, taskID, commentID, body)
+}
+
+func (r *CommentCache) DeleteComment(userID, taskID, commentID int) error {
+	if err := r.repo.DeleteComment(userID, taskID, commentID); err != nil {
+		return fmt.Errorf("failed to delete comment in repository: %w", err)
+	}
+
+	return r.tasks.invalidateAudience(userID, taskID)
+}
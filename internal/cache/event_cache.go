@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventCache будит потоки событий пользователя через Redis pub/sub: в канал
+// "user:%d:events" публикуется пустое сообщение после каждой записи, которая
+// сбрасывает кэш задач пользователя, на какой бы реплике она ни случилась.
+// Процесс держит одну подписку PSUBSCRIBE на каналы всех пользователей и
+// раздаёт сообщения своим потокам в памяти, так что число соединений с Redis
+// не растёт с числом потоков. Что именно изменилось, поток читает из журнала
+// изменений, так что потерянное сообщение только задерживает события до
+// следующего опроса.
+type EventCache struct {
+	rdb *redis.Client
+
+	mu      sync.Mutex
+	sub     *redis.PubSub // открывается первым потоком и живёт до конца процесса
+	streams map[int]map[chan struct{}]struct{}
+}
+
+func NewEventCache(rdb *redis.Client) *EventCache {
+	return &EventCache{
+		rdb:     rdb,
+		streams: make(map[int]map[chan struct{}]struct{}),
+	}
+}
+
+// SubscribeTasks returns a channel that receives a value after the user's
+// tasks change. Changes that come in a row may be merged into one value. The
+// channel is closed when ctx is done.
+func (r *EventCache) SubscribeTasks(ctx context.Context, userID int) (<-chan struct{}, error) {
+	// подписка должна действовать до первого чтения журнала, иначе изменение между ними потеряется
+	if err := r.listen(ctx); err != nil {
+		return nil, err
+	}
+
+	wake := make(chan struct{}, 1)
+	r.mu.Lock()
+	if r.streams[userID] == nil {
+		r.streams[userID] = make(map[chan struct{}]struct{})
+	}
+	r.streams[userID][wake] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		r.mu.Lock()
+		delete(r.streams[userID], wake)
+		if len(r.streams[userID]) == 0 {
+			delete(r.streams, userID)
+		}
+		r.mu.Unlock()
+		// dispatch больше не видит канал, закрывать можно без блокировки
+		close(wake)
+	}()
+
+	return wake, nil
+}
+
+// listen opens the shared subscription unless it is already open. The
+// connection is not tied to ctx, it serves every stream of the process.
+func (r *EventCache) listen(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sub != nil {
+		return nil
+	}
+
+	sub := r.rdb.PSubscribe(context.Background(), eventsChannelPattern)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return fmt.Errorf("failed to subscribe to task events: %w", err)
+	}
+
+	r.sub = sub
+	go r.dispatch(sub.Channel())
+
+	return nil
+}
+
+// dispatch wakes the streams of the user whose channel got a message. go-redis
+// reconnects the subscription by itself, messages published meanwhile are lost.
+func (r *EventCache) dispatch(msgs <-chan *redis.Message) {
+	for msg := range msgs {
+		var userID int
+		if _, err := fmt.Sscanf(msg.Channel, eventsChannelFormat, &userID); err != nil {
+			continue
+		}
+
+		r.mu.Lock()
+		for wake := range r.streams[userID] {
+			// поток ещё не дочитал журнал - он увидит и это изменение
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+		r.mu.Unlock()
+	}
+
+	// канал закрывается только с подпиской, следующий поток откроет новую
+	r.mu.Lock()
+	r.sub = nil
+	r.mu.Unlock()
+}
+
+// notifyTasks будит потоки событий пользователей, см. EventCache.
+func notifyTasks(rdb *redis.Client, userIDs ...int) error {
+	pipe := rdb.Pipeline()
+	for _, id := range userIDs {
+		pipe.Publish(ctx, eventsChannel(id), "")
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish task events: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	eventsChannelFormat  = "user:%d:events"
+	eventsChannelPattern = "user:*:events"
+)
+
+func eventsChannel(userID int) string {
+	return fmt.Sprintf(eventsChannelFormat, userID)
+}
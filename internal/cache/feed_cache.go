@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// FeedTTL bounds how stale a feed can get if a change slipped past
+	// invalidateTasks: a feed rendered while a task was being written is
+	// cached after the invalidation.
+	FeedTTL      = 5 * time.Minute
+	feedTokenTTL = time.Hour
+	// feedVersionTTL keeps the validators of the last feed long past the
+	// feed itself, so a client polling once a day still gets 304.
+	feedVersionTTL = 7 * 24 * time.Hour
+)
+
+// FeedCache держит готовый календарь пользователя в "user:%d:feed" и
+// владельца токена в "feed:token:<hash>", так что опрос календарным
+// приложением, пока задачи не менялись, в базу не ходит. Календарь
+// сбрасывается вместе с хэшем задач в invalidateTasks, а его ETag и
+// Last-Modified остаются в "user:%d:feed:version": правка, не задевшая
+// календарь, не должна сдвигать Last-Modified.
+type FeedCache struct {
+	rdb  *redis.Client
+	repo repository.Feeds
+}
+
+func NewFeedCache(rdb *redis.Client, repo repository.Feeds) *FeedCache {
+	return &FeedCache{rdb: rdb, repo: repo}
+}
+
+func (r *FeedCache) SaveFeedToken(userID int, tokenHash string) (string, error) {
+	old, err := r.repo.SaveFeedToken(userID, tokenHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to save feed token in repository: %w", err)
+	}
+
+	return old, r.forgetToken(old)
+}
+
+func (r *FeedCache) DeleteFeedToken(userID int) (string, error) {
+	old, err := r.repo.DeleteFeedToken(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete feed token in repository: %w", err)
+	}
+
+	return old, r.forgetToken(old)
+}
+
+func (r *FeedCache) GetFeedUser(tokenHash string) (int, error) {
+	key := feedTokenKey(tokenHash)
+
+	raw, err := r.rdb.Get(ctx, key).Result()
+	if err == nil {
+		return strconv.Atoi(raw)
+	}
+	if !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to get feed token from cache: %w", err)
+	}
+
+	userID, err := r.repo.GetFeedUser(tokenHash)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.rdb.Set(ctx, key, userID, feedTokenTTL).Err(); err != nil {
+		return 0, fmt.Errorf("failed to save feed token to cache: %w", err)
+	}
+
+	return userID, nil
+}
+
+// GetFeed returns the cached feed of the user, nil if there is none.
+func (r *FeedCache) GetFeed(userID int) (*entity.Feed, error) {
+	raw, err := r.rdb.Get(ctx, feedKey(userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed from cache: %w", err)
+	}
+
+	var feed entity.Feed
+	if err := json.Unmarshal(raw, &feed); err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// GetFeedVersion returns the ETag and ModifiedAt of the last feed rendered
+// for the user, without a body, nil if there is none. It outlives the feed.
+func (r *FeedCache) GetFeedVersion(userID int) (*entity.Feed, error) {
+	raw, err := r.rdb.Get(ctx, feedVersionKey(userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed version from cache: %w", err)
+	}
+
+	var version entity.Feed
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+func (r *FeedCache) SaveFeed(userID int, feed entity.Feed) error {
+	b, err := json.Marshal(feed)
+	if err != nil {
+		return err
+	}
+	version, err := json.Marshal(entity.Feed{ETag: feed.ETag, ModifiedAt: feed.ModifiedAt})
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, feedKey(userID), b, FeedTTL)
+	pipe.Set(ctx, feedVersionKey(userID), version, feedVersionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save feed to cache: %w", err)
+	}
+
+	return nil
+}
+
+// forgetToken drops the owner of a revoked token, so it stops working at once.
+func (r *FeedCache) forgetToken(tokenHash string) error {
+	if tokenHash == "" {
+		return nil
+	}
+
+	if err := r.rdb.Del(ctx, feedTokenKey(tokenHash)).Err(); err != nil {
+		return fmt.Errorf("failed to forget feed token: %w", err)
+	}
+
+	return nil
+}
+
+func feedKey(userID int) string {
+	return fmt.Sprintf("user:%d:feed", userID)
+}
+
+func feedVersionKey(userID int) string {
+	return fmt.Sprintf("user:%d:feed:version", userID)
+}
+
+func feedTokenKey(tokenHash string) string {
+	return "feed:token:" + tokenHash
+}
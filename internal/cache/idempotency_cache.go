@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyTTL = 24 * time.Hour // столько живёт сохранённый ответ
+	// PendingTTL - срок отметки выполняющегося запроса, чтобы упавший запрос
+	// не держал ключ сутки. Пока запрос идёт, отметку продлевает ExtendKey.
+	PendingTTL = time.Minute
+)
+
+// IdempotencyCache хранит ответы на запросы с Idempotency-Key в ключах
+// "idempotency:<scope>:<key>", базы за этим нет.
+type IdempotencyCache struct {
+	rdb *redis.Client
+}
+
+func NewIdempotencyCache(rdb *redis.Client) *IdempotencyCache {
+	return &IdempotencyCache{rdb: rdb}
+}
+
+// ReserveKey claims the key for a new request. If the key is already taken
+// the stored record is returned: a finished response or a pending mark.
+func (r *IdempotencyCache) ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	pending, err := json.Marshal(entity.IdempotentResponse{Fingerprint: fingerprint, Pending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// SET NX GET: занять ключ и прочитать старое значение одной командой
+	old, err := r.rdb.SetArgs(ctx, idempotencyKey(scope, key), pending, redis.SetArgs{
+		Mode: "NX",
+		TTL:  PendingTTL,
+		Get:  true,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var stored entity.IdempotentResponse
+	if err := json.Unmarshal([]byte(old), &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotent response: %w", err)
+	}
+
+	return &stored, nil
+}
+
+func (r *IdempotencyCache) SaveResponse(scope, key string, resp entity.IdempotentResponse) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	if err := r.rdb.Set(ctx, idempotencyKey(scope, key), b, IdempotencyTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// ExtendKey gives the pending mark of a running request another PendingTTL.
+func (r *IdempotencyCache) ExtendKey(scope, key string) error {
+	if err := r.rdb.Expire(ctx, idempotencyKey(scope, key), PendingTTL).Err(); err != nil {
+		return fmt.Errorf("failed to extend idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseKey forgets the key, so the request can be retried for real.
+func (r *IdempotencyCache) ReleaseKey(scope, key string) error {
+	if err := r.rdb.Del(ctx, idempotencyKey(scope, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func idempotencyKey(scope, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", scope, key)
+}
@@ -0,0 +1,1136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis.go
+
+// Package mock_cache is a generated GoMock package.
+package mock_cache
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/AronditFire/todo-app/entity"
+	repository "github.com/AronditFire/todo-app/internal/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskList is a mock of TaskList interface.
+type MockTaskList struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskListMockRecorder
+}
+
+// MockTaskListMockRecorder is the mock recorder for MockTaskList.
+type MockTaskListMockRecorder struct {
+	mock *MockTaskList
+}
+
+// NewMockTaskList creates a new mock instance.
+func NewMockTaskList(ctrl *gomock.Controller) *MockTaskList {
+	mock := &MockTaskList{ctrl: ctrl}
+	mock.recorder = &MockTaskListMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskList) EXPECT() *MockTaskListMockRecorder {
+	return m.recorder
+}
+
+// Batch mocks base method.
+func (m *MockTaskList) Batch(userID int, ops []repository.TaskOp, atomic bool) ([]repository.TaskOpResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", userID, ops, atomic)
+	ret0, _ := ret[0].([]repository.TaskOpResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockTaskListMockRecorder) Batch(userID, ops, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockTaskList)(nil).Batch), userID, ops, atomic)
+}
+
+// CreateTask mocks base method.
+func (m *MockTaskList) CreateTask(userID int, task entity.Task) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTask", userID, task)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTask indicates an expected call of CreateTask.
+func (mr *MockTaskListMockRecorder) CreateTask(userID, task interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockTaskList)(nil).CreateTask), userID, task)
+}
+
+// CurrentSyncToken mocks base method.
+func (m *MockTaskList) CurrentSyncToken() (entity.SyncToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrentSyncToken")
+	ret0, _ := ret[0].(entity.SyncToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CurrentSyncToken indicates an expected call of CurrentSyncToken.
+func (mr *MockTaskListMockRecorder) CurrentSyncToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentSyncToken", reflect.TypeOf((*MockTaskList)(nil).CurrentSyncToken))
+}
+
+// DeleteTask mocks base method.
+func (m *MockTaskList) DeleteTask(userID, taskID, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTask", userID, taskID, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTask indicates an expected call of DeleteTask.
+func (mr *MockTaskListMockRecorder) DeleteTask(userID, taskID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskList)(nil).DeleteTask), userID, taskID, version)
+}
+
+// ExportTasks mocks base method.
+func (m *MockTaskList) ExportTasks(userID int, fn func(entity.Task) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTasks", userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportTasks indicates an expected call of ExportTasks.
+func (mr *MockTaskListMockRecorder) ExportTasks(userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTasks", reflect.TypeOf((*MockTaskList)(nil).ExportTasks), userID, fn)
+}
+
+// GetAllTask mocks base method.
+func (m *MockTaskList) GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTask", userID, filter)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTask indicates an expected call of GetAllTask.
+func (mr *MockTaskListMockRecorder) GetAllTask(userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTask", reflect.TypeOf((*MockTaskList)(nil).GetAllTask), userID, filter)
+}
+
+// GetChanges mocks base method.
+func (m *MockTaskList) GetChanges(userID int, since entity.SyncToken) (entity.TaskChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", userID, since)
+	ret0, _ := ret[0].(entity.TaskChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockTaskListMockRecorder) GetChanges(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockTaskList)(nil).GetChanges), userID, since)
+}
+
+// GetTaskByID mocks base method.
+func (m *MockTaskList) GetTaskByID(userID, id int) (entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskByID", userID, id)
+	ret0, _ := ret[0].(entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskByID indicates an expected call of GetTaskByID.
+func (mr *MockTaskListMockRecorder) GetTaskByID(userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskByID", reflect.TypeOf((*MockTaskList)(nil).GetTaskByID), userID, id)
+}
+
+// ImportTasks mocks base method.
+func (m *MockTaskList) ImportTasks(userID int, tasks []entity.ImportTask) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTasks", userID, tasks)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportTasks indicates an expected call of ImportTasks.
+func (mr *MockTaskListMockRecorder) ImportTasks(userID, tasks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTasks", reflect.TypeOf((*MockTaskList)(nil).ImportTasks), userID, tasks)
+}
+
+// MoveTask mocks base method.
+func (m *MockTaskList) MoveTask(userID, taskID, anchorID int, after bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTask", userID, taskID, anchorID, after)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveTask indicates an expected call of MoveTask.
+func (mr *MockTaskListMockRecorder) MoveTask(userID, taskID, anchorID, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTask", reflect.TypeOf((*MockTaskList)(nil).MoveTask), userID, taskID, anchorID, after)
+}
+
+// PatchTask mocks base method.
+func (m *MockTaskList) PatchTask(userID, taskID, version int, apply func(*entity.Task) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchTask", userID, taskID, version, apply)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchTask indicates an expected call of PatchTask.
+func (mr *MockTaskListMockRecorder) PatchTask(userID, taskID, version, apply interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTask", reflect.TypeOf((*MockTaskList)(nil).PatchTask), userID, taskID, version, apply)
+}
+
+// PruneChanges mocks base method.
+func (m *MockTaskList) PruneChanges(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneChanges", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneChanges indicates an expected call of PruneChanges.
+func (mr *MockTaskListMockRecorder) PruneChanges(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneChanges", reflect.TypeOf((*MockTaskList)(nil).PruneChanges), before)
+}
+
+// SaveTaskToCache mocks base method.
+func (m *MockTaskList) SaveTaskToCache(ctx context.Context, userID int, task entity.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTaskToCache", ctx, userID, task)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTaskToCache indicates an expected call of SaveTaskToCache.
+func (mr *MockTaskListMockRecorder) SaveTaskToCache(ctx, userID, task interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTaskToCache", reflect.TypeOf((*MockTaskList)(nil).SaveTaskToCache), ctx, userID, task)
+}
+
+// UpdateTask mocks base method.
+func (m *MockTaskList) UpdateTask(userID, taskId int, desc string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTask", userID, taskId, desc, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTask indicates an expected call of UpdateTask.
+func (mr *MockTaskListMockRecorder) UpdateTask(userID, taskId, desc, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskList)(nil).UpdateTask), userID, taskId, desc, version)
+}
+
+// UpdateTaskDue mocks base method.
+func (m *MockTaskList) UpdateTaskDue(userID, taskID int, dueAt, remindAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskDue", userID, taskID, dueAt, remindAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskDue indicates an expected call of UpdateTaskDue.
+func (mr *MockTaskListMockRecorder) UpdateTaskDue(userID, taskID, dueAt, remindAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskDue", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskDue), userID, taskID, dueAt, remindAt)
+}
+
+// UpdateTaskParent mocks base method.
+func (m *MockTaskList) UpdateTaskParent(userID, taskID int, parentID *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskParent", userID, taskID, parentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskParent indicates an expected call of UpdateTaskParent.
+func (mr *MockTaskListMockRecorder) UpdateTaskParent(userID, taskID, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskParent", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskParent), userID, taskID, parentID)
+}
+
+// UpdateTaskPriority mocks base method.
+func (m *MockTaskList) UpdateTaskPriority(userID, taskID, priority int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskPriority", userID, taskID, priority)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskPriority indicates an expected call of UpdateTaskPriority.
+func (mr *MockTaskListMockRecorder) UpdateTaskPriority(userID, taskID, priority interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskPriority", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskPriority), userID, taskID, priority)
+}
+
+// UpdateTaskProject mocks base method.
+func (m *MockTaskList) UpdateTaskProject(userID, taskID int, projectID *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskProject", userID, taskID, projectID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskProject indicates an expected call of UpdateTaskProject.
+func (mr *MockTaskListMockRecorder) UpdateTaskProject(userID, taskID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskProject", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskProject), userID, taskID, projectID)
+}
+
+// UpdateTaskRecurrence mocks base method.
+func (m *MockTaskList) UpdateTaskRecurrence(userID, taskID int, rule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskRecurrence", userID, taskID, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskRecurrence indicates an expected call of UpdateTaskRecurrence.
+func (mr *MockTaskListMockRecorder) UpdateTaskRecurrence(userID, taskID, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskRecurrence", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskRecurrence), userID, taskID, rule)
+}
+
+// UpdateTaskStatus mocks base method.
+func (m *MockTaskList) UpdateTaskStatus(userID, taskID int, status entity.TaskStatus, completedAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskStatus", userID, taskID, status, completedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskStatus indicates an expected call of UpdateTaskStatus.
+func (mr *MockTaskListMockRecorder) UpdateTaskStatus(userID, taskID, status, completedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskStatus", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskStatus), userID, taskID, status, completedAt)
+}
+
+// MockTags is a mock of Tags interface.
+type MockTags struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagsMockRecorder
+}
+
+// MockTagsMockRecorder is the mock recorder for MockTags.
+type MockTagsMockRecorder struct {
+	mock *MockTags
+}
+
+// NewMockTags creates a new mock instance.
+func NewMockTags(ctrl *gomock.Controller) *MockTags {
+	mock := &MockTags{ctrl: ctrl}
+	mock.recorder = &MockTagsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTags) EXPECT() *MockTagsMockRecorder {
+	return m.recorder
+}
+
+// AttachTag mocks base method.
+func (m *MockTags) AttachTag(userID, taskID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTag", userID, taskID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTag indicates an expected call of AttachTag.
+func (mr *MockTagsMockRecorder) AttachTag(userID, taskID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTag", reflect.TypeOf((*MockTags)(nil).AttachTag), userID, taskID, tagID)
+}
+
+// CreateTag mocks base method.
+func (m *MockTags) CreateTag(userID int, tag entity.Tag) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", userID, tag)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTagsMockRecorder) CreateTag(userID, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTags)(nil).CreateTag), userID, tag)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTags) DeleteTag(userID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", userID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagsMockRecorder) DeleteTag(userID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTags)(nil).DeleteTag), userID, tagID)
+}
+
+// DetachTag mocks base method.
+func (m *MockTags) DetachTag(userID, taskID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTag", userID, taskID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachTag indicates an expected call of DetachTag.
+func (mr *MockTagsMockRecorder) DetachTag(userID, taskID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTag", reflect.TypeOf((*MockTags)(nil).DetachTag), userID, taskID, tagID)
+}
+
+// GetAllTags mocks base method.
+func (m *MockTags) GetAllTags(userID int) ([]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTags", userID)
+	ret0, _ := ret[0].([]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTags indicates an expected call of GetAllTags.
+func (mr *MockTagsMockRecorder) GetAllTags(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTags", reflect.TypeOf((*MockTags)(nil).GetAllTags), userID)
+}
+
+// GetTagByID mocks base method.
+func (m *MockTags) GetTagByID(userID, tagID int) (entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByID", userID, tagID)
+	ret0, _ := ret[0].(entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagByID indicates an expected call of GetTagByID.
+func (mr *MockTagsMockRecorder) GetTagByID(userID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByID", reflect.TypeOf((*MockTags)(nil).GetTagByID), userID, tagID)
+}
+
+// UpdateTag mocks base method.
+func (m *MockTags) UpdateTag(userID, tagID int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTag", userID, tagID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTag indicates an expected call of UpdateTag.
+func (mr *MockTagsMockRecorder) UpdateTag(userID, tagID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockTags)(nil).UpdateTag), userID, tagID, name)
+}
+
+// MockProjects is a mock of Projects interface.
+type MockProjects struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectsMockRecorder
+}
+
+// MockProjectsMockRecorder is the mock recorder for MockProjects.
+type MockProjectsMockRecorder struct {
+	mock *MockProjects
+}
+
+// NewMockProjects creates a new mock instance.
+func NewMockProjects(ctrl *gomock.Controller) *MockProjects {
+	mock := &MockProjects{ctrl: ctrl}
+	mock.recorder = &MockProjectsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProjects) EXPECT() *MockProjectsMockRecorder {
+	return m.recorder
+}
+
+// CreateProject mocks base method.
+func (m *MockProjects) CreateProject(userID int, project entity.Project) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProject", userID, project)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProject indicates an expected call of CreateProject.
+func (mr *MockProjectsMockRecorder) CreateProject(userID, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProject", reflect.TypeOf((*MockProjects)(nil).CreateProject), userID, project)
+}
+
+// DeleteProject mocks base method.
+func (m *MockProjects) DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProject", userID, projectID, mode, targetID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProject indicates an expected call of DeleteProject.
+func (mr *MockProjectsMockRecorder) DeleteProject(userID, projectID, mode, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProject", reflect.TypeOf((*MockProjects)(nil).DeleteProject), userID, projectID, mode, targetID)
+}
+
+// GetAllProjects mocks base method.
+func (m *MockProjects) GetAllProjects(userID int, withArchived bool) ([]entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllProjects", userID, withArchived)
+	ret0, _ := ret[0].([]entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllProjects indicates an expected call of GetAllProjects.
+func (mr *MockProjectsMockRecorder) GetAllProjects(userID, withArchived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProjects", reflect.TypeOf((*MockProjects)(nil).GetAllProjects), userID, withArchived)
+}
+
+// GetProjectByID mocks base method.
+func (m *MockProjects) GetProjectByID(userID, projectID int) (entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectByID", userID, projectID)
+	ret0, _ := ret[0].(entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectByID indicates an expected call of GetProjectByID.
+func (mr *MockProjectsMockRecorder) GetProjectByID(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectByID", reflect.TypeOf((*MockProjects)(nil).GetProjectByID), userID, projectID)
+}
+
+// UpdateProject mocks base method.
+func (m *MockProjects) UpdateProject(userID, projectID int, upd entity.ProjectRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProject", userID, projectID, upd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProject indicates an expected call of UpdateProject.
+func (mr *MockProjectsMockRecorder) UpdateProject(userID, projectID, upd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProject", reflect.TypeOf((*MockProjects)(nil).UpdateProject), userID, projectID, upd)
+}
+
+// MockTrash is a mock of Trash interface.
+type MockTrash struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashMockRecorder
+}
+
+// MockTrashMockRecorder is the mock recorder for MockTrash.
+type MockTrashMockRecorder struct {
+	mock *MockTrash
+}
+
+// NewMockTrash creates a new mock instance.
+func NewMockTrash(ctrl *gomock.Controller) *MockTrash {
+	mock := &MockTrash{ctrl: ctrl}
+	mock.recorder = &MockTrashMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrash) EXPECT() *MockTrashMockRecorder {
+	return m.recorder
+}
+
+// EmptyTrash mocks base method.
+func (m *MockTrash) EmptyTrash(userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockTrashMockRecorder) EmptyTrash(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockTrash)(nil).EmptyTrash), userID)
+}
+
+// GetTrash mocks base method.
+func (m *MockTrash) GetTrash(userID int) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", userID)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTrashMockRecorder) GetTrash(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTrash)(nil).GetTrash), userID)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockTrash) PurgeExpired(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockTrashMockRecorder) PurgeExpired(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockTrash)(nil).PurgeExpired), before)
+}
+
+// PurgeTask mocks base method.
+func (m *MockTrash) PurgeTask(userID, taskID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTask", userID, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTask indicates an expected call of PurgeTask.
+func (mr *MockTrashMockRecorder) PurgeTask(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTask", reflect.TypeOf((*MockTrash)(nil).PurgeTask), userID, taskID)
+}
+
+// RestoreTask mocks base method.
+func (m *MockTrash) RestoreTask(userID, taskID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTask", userID, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTask indicates an expected call of RestoreTask.
+func (mr *MockTrashMockRecorder) RestoreTask(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTask", reflect.TypeOf((*MockTrash)(nil).RestoreTask), userID, taskID)
+}
+
+// MockSharing is a mock of Sharing interface.
+type MockSharing struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharingMockRecorder
+}
+
+// MockSharingMockRecorder is the mock recorder for MockSharing.
+type MockSharingMockRecorder struct {
+	mock *MockSharing
+}
+
+// NewMockSharing creates a new mock instance.
+func NewMockSharing(ctrl *gomock.Controller) *MockSharing {
+	mock := &MockSharing{ctrl: ctrl}
+	mock.recorder = &MockSharingMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharing) EXPECT() *MockSharingMockRecorder {
+	return m.recorder
+}
+
+// GetProjectShares mocks base method.
+func (m *MockSharing) GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectShares", userID, projectID)
+	ret0, _ := ret[0].([]entity.ProjectShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectShares indicates an expected call of GetProjectShares.
+func (mr *MockSharingMockRecorder) GetProjectShares(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectShares", reflect.TypeOf((*MockSharing)(nil).GetProjectShares), userID, projectID)
+}
+
+// GetTaskShares mocks base method.
+func (m *MockSharing) GetTaskShares(userID, taskID int) ([]entity.TaskShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskShares", userID, taskID)
+	ret0, _ := ret[0].([]entity.TaskShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskShares indicates an expected call of GetTaskShares.
+func (mr *MockSharingMockRecorder) GetTaskShares(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskShares", reflect.TypeOf((*MockSharing)(nil).GetTaskShares), userID, taskID)
+}
+
+// ProjectAudience mocks base method.
+func (m *MockSharing) ProjectAudience(projectID int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProjectAudience", projectID)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProjectAudience indicates an expected call of ProjectAudience.
+func (mr *MockSharingMockRecorder) ProjectAudience(projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectAudience", reflect.TypeOf((*MockSharing)(nil).ProjectAudience), projectID)
+}
+
+// ProjectRole mocks base method.
+func (m *MockSharing) ProjectRole(userID, projectID int) (entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProjectRole", userID, projectID)
+	ret0, _ := ret[0].(entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProjectRole indicates an expected call of ProjectRole.
+func (mr *MockSharingMockRecorder) ProjectRole(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectRole", reflect.TypeOf((*MockSharing)(nil).ProjectRole), userID, projectID)
+}
+
+// ShareProject mocks base method.
+func (m *MockSharing) ShareProject(userID, projectID, granteeID int, role entity.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareProject", userID, projectID, granteeID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShareProject indicates an expected call of ShareProject.
+func (mr *MockSharingMockRecorder) ShareProject(userID, projectID, granteeID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareProject", reflect.TypeOf((*MockSharing)(nil).ShareProject), userID, projectID, granteeID, role)
+}
+
+// ShareTask mocks base method.
+func (m *MockSharing) ShareTask(userID, taskID, granteeID int, role entity.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareTask", userID, taskID, granteeID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShareTask indicates an expected call of ShareTask.
+func (mr *MockSharingMockRecorder) ShareTask(userID, taskID, granteeID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareTask", reflect.TypeOf((*MockSharing)(nil).ShareTask), userID, taskID, granteeID, role)
+}
+
+// TaskAudience mocks base method.
+func (m *MockSharing) TaskAudience(taskID int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskAudience", taskID)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskAudience indicates an expected call of TaskAudience.
+func (mr *MockSharingMockRecorder) TaskAudience(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskAudience", reflect.TypeOf((*MockSharing)(nil).TaskAudience), taskID)
+}
+
+// TaskRole mocks base method.
+func (m *MockSharing) TaskRole(userID, taskID int) (entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskRole", userID, taskID)
+	ret0, _ := ret[0].(entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskRole indicates an expected call of TaskRole.
+func (mr *MockSharingMockRecorder) TaskRole(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskRole", reflect.TypeOf((*MockSharing)(nil).TaskRole), userID, taskID)
+}
+
+// TasksAudience mocks base method.
+func (m *MockSharing) TasksAudience(taskIDs []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TasksAudience", taskIDs)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TasksAudience indicates an expected call of TasksAudience.
+func (mr *MockSharingMockRecorder) TasksAudience(taskIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TasksAudience", reflect.TypeOf((*MockSharing)(nil).TasksAudience), taskIDs)
+}
+
+// UnshareProject mocks base method.
+func (m *MockSharing) UnshareProject(userID, projectID, granteeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnshareProject", userID, projectID, granteeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnshareProject indicates an expected call of UnshareProject.
+func (mr *MockSharingMockRecorder) UnshareProject(userID, projectID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnshareProject", reflect.TypeOf((*MockSharing)(nil).UnshareProject), userID, projectID, granteeID)
+}
+
+// UnshareTask mocks base method.
+func (m *MockSharing) UnshareTask(userID, taskID, granteeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnshareTask", userID, taskID, granteeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnshareTask indicates an expected call of UnshareTask.
+func (mr *MockSharingMockRecorder) UnshareTask(userID, taskID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnshareTask", reflect.TypeOf((*MockSharing)(nil).UnshareTask), userID, taskID, granteeID)
+}
+
+// MockComments is a mock of Comments interface.
+type MockComments struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentsMockRecorder
+}
+
+// MockCommentsMockRecorder is the mock recorder for MockComments.
+type MockCommentsMockRecorder struct {
+	mock *MockComments
+}
+
+// NewMockComments creates a new mock instance.
+func NewMockComments(ctrl *gomock.Controller) *MockComments {
+	mock := &MockComments{ctrl: ctrl}
+	mock.recorder = &MockCommentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComments) EXPECT() *MockCommentsMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockComments) CreateComment(userID, taskID int, body string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", userID, taskID, body)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockCommentsMockRecorder) CreateComment(userID, taskID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockComments)(nil).CreateComment), userID, taskID, body)
+}
+
+// DeleteComment mocks base method.
+func (m *MockComments) DeleteComment(userID, taskID, commentID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", userID, taskID, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentsMockRecorder) DeleteComment(userID, taskID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockComments)(nil).DeleteComment), userID, taskID, commentID)
+}
+
+// GetComments mocks base method.
+func (m *MockComments) GetComments(userID, taskID, afterID, limit int) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComments", userID, taskID, afterID, limit)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComments indicates an expected call of GetComments.
+func (mr *MockCommentsMockRecorder) GetComments(userID, taskID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComments", reflect.TypeOf((*MockComments)(nil).GetComments), userID, taskID, afterID, limit)
+}
+
+// UpdateComment mocks base method.
+func (m *MockComments) UpdateComment(userID, taskID, commentID int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", userID, taskID, commentID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentsMockRecorder) UpdateComment(userID, taskID, commentID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockComments)(nil).UpdateComment), userID, taskID, commentID, body)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// ExtendKey mocks base method.
+func (m *MockIdempotency) ExtendKey(scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendKey", scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtendKey indicates an expected call of ExtendKey.
+func (mr *MockIdempotencyMockRecorder) ExtendKey(scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendKey", reflect.TypeOf((*MockIdempotency)(nil).ExtendKey), scope, key)
+}
+
+// ReleaseKey mocks base method.
+func (m *MockIdempotency) ReleaseKey(scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseKey", scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseKey indicates an expected call of ReleaseKey.
+func (mr *MockIdempotencyMockRecorder) ReleaseKey(scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseKey", reflect.TypeOf((*MockIdempotency)(nil).ReleaseKey), scope, key)
+}
+
+// ReserveKey mocks base method.
+func (m *MockIdempotency) ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveKey", scope, key, fingerprint)
+	ret0, _ := ret[0].(*entity.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveKey indicates an expected call of ReserveKey.
+func (mr *MockIdempotencyMockRecorder) ReserveKey(scope, key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveKey", reflect.TypeOf((*MockIdempotency)(nil).ReserveKey), scope, key, fingerprint)
+}
+
+// SaveResponse mocks base method.
+func (m *MockIdempotency) SaveResponse(scope, key string, resp entity.IdempotentResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveResponse", scope, key, resp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveResponse indicates an expected call of SaveResponse.
+func (mr *MockIdempotencyMockRecorder) SaveResponse(scope, key, resp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResponse", reflect.TypeOf((*MockIdempotency)(nil).SaveResponse), scope, key, resp)
+}
+
+// MockFeeds is a mock of Feeds interface.
+type MockFeeds struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedsMockRecorder
+}
+
+// MockFeedsMockRecorder is the mock recorder for MockFeeds.
+type MockFeedsMockRecorder struct {
+	mock *MockFeeds
+}
+
+// NewMockFeeds creates a new mock instance.
+func NewMockFeeds(ctrl *gomock.Controller) *MockFeeds {
+	mock := &MockFeeds{ctrl: ctrl}
+	mock.recorder = &MockFeedsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeeds) EXPECT() *MockFeedsMockRecorder {
+	return m.recorder
+}
+
+// DeleteFeedToken mocks base method.
+func (m *MockFeeds) DeleteFeedToken(userID int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeedToken", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeedToken indicates an expected call of DeleteFeedToken.
+func (mr *MockFeedsMockRecorder) DeleteFeedToken(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeedToken", reflect.TypeOf((*MockFeeds)(nil).DeleteFeedToken), userID)
+}
+
+// GetFeed mocks base method.
+func (m *MockFeeds) GetFeed(userID int) (*entity.Feed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", userID)
+	ret0, _ := ret[0].(*entity.Feed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockFeedsMockRecorder) GetFeed(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockFeeds)(nil).GetFeed), userID)
+}
+
+// GetFeedUser mocks base method.
+func (m *MockFeeds) GetFeedUser(tokenHash string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedUser", tokenHash)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedUser indicates an expected call of GetFeedUser.
+func (mr *MockFeedsMockRecorder) GetFeedUser(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedUser", reflect.TypeOf((*MockFeeds)(nil).GetFeedUser), tokenHash)
+}
+
+// GetFeedVersion mocks base method.
+func (m *MockFeeds) GetFeedVersion(userID int) (*entity.Feed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedVersion", userID)
+	ret0, _ := ret[0].(*entity.Feed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedVersion indicates an expected call of GetFeedVersion.
+func (mr *MockFeedsMockRecorder) GetFeedVersion(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedVersion", reflect.TypeOf((*MockFeeds)(nil).GetFeedVersion), userID)
+}
+
+// SaveFeed mocks base method.
+func (m *MockFeeds) SaveFeed(userID int, feed entity.Feed) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeed", userID, feed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFeed indicates an expected call of SaveFeed.
+func (mr *MockFeedsMockRecorder) SaveFeed(userID, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeed", reflect.TypeOf((*MockFeeds)(nil).SaveFeed), userID, feed)
+}
+
+// SaveFeedToken mocks base method.
+func (m *MockFeeds) SaveFeedToken(userID int, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeedToken", userID, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFeedToken indicates an expected call of SaveFeedToken.
+func (mr *MockFeedsMockRecorder) SaveFeedToken(userID, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeedToken", reflect.TypeOf((*MockFeeds)(nil).SaveFeedToken), userID, tokenHash)
+}
+
+// MockEvents is a mock of Events interface.
+type MockEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsMockRecorder
+}
+
+// MockEventsMockRecorder is the mock recorder for MockEvents.
+type MockEventsMockRecorder struct {
+	mock *MockEvents
+}
+
+// NewMockEvents creates a new mock instance.
+func NewMockEvents(ctrl *gomock.Controller) *MockEvents {
+	mock := &MockEvents{ctrl: ctrl}
+	mock.recorder = &MockEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEvents) EXPECT() *MockEventsMockRecorder {
+	return m.recorder
+}
+
+// SubscribeTasks mocks base method.
+func (m *MockEvents) SubscribeTasks(ctx context.Context, userID int) (<-chan struct{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeTasks", ctx, userID)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeTasks indicates an expected call of SubscribeTasks.
+func (mr *MockEventsMockRecorder) SubscribeTasks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTasks", reflect.TypeOf((*MockEvents)(nil).SubscribeTasks), ctx, userID)
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// ProjectCache проекты не кеширует, но при удалении проекта
+// сбрасывает закешированные задачи: они удалены или перенесены.
+type ProjectCache struct {
+	rdb   *redis.Client
+	repo  repository.Projects
+	share repository.Sharing
+}
+
+func NewProjectCache(rdb *redis.Client, repo repository.Projects, share repository.Sharing) *ProjectCache {
+	return &ProjectCache{
+		rdb:   rdb,
+		repo:  repo,
+		share: share,
+	}
+}
+
+func (r *ProjectCache) CreateProject(userID int, project entity.Project) (int, error) {
+	return r.repo.CreateProject(userID, project)
+}
+
+func (r *ProjectCache) GetAllProjects(userID int, withArchived bool) ([]entity.Project, error) {
+	return r.repo.GetAllProjects(userID, withArchived)
+}
+
+func (r *ProjectCache) GetProjectByID(userID, projectID int) (entity.Project, error) {
+	return r.repo.GetProjectByID(userID, projectID)
+}
+
+func (r *ProjectCache) UpdateProject(userID, projectID int, upd entity.ProjectRequest) error {
+	return r.repo.UpdateProject(userID, projectID, upd)
+}
+
+func (r *ProjectCache) DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error {
+	// после удаления проекта его участников уже не узнать
+	audience, err := r.share.ProjectAudience(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project audience: %w", err)
+	}
+
+	if err := r.repo.DeleteProject(userID, projectID, mode, targetID); err != nil {
+		return fmt.Errorf("failed to delete project in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, append(audience, userID)...)
+}
@@ -3,12 +3,15 @@ package cache
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/AronditFire/todo-app/entity"
 	"github.com/AronditFire/todo-app/internal/repository"
 	"github.com/redis/go-redis/v9"
 )
 
+//go:generate mockgen -source=redis.go -destination=mocks/mock.go
+
 var ctx = context.Background()
 
 func InitRedis() (*redis.Client, error) {
@@ -35,20 +38,121 @@ func CloseRedisConnection(rds *redis.Client) error {
 
 type TaskList interface {
 	CreateTask(userID int, task entity.Task) (int, error)
-	GetAllTask(userID int) ([]entity.Task, error)
+	GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error)
 	GetTaskByID(userID, id int) (entity.Task, error)
-	UpdateTask(userID, taskId int, desc string) error
-	DeleteTask(userID, taskID int) error
-	SaveTasksToCache(ctx context.Context, userID int, tasks []entity.Task) error
+	UpdateTask(userID, taskId int, desc string, version int) error
+	PatchTask(userID, taskID, version int, apply func(task *entity.Task) error) error
+	UpdateTaskStatus(userID, taskID int, status entity.TaskStatus, completedAt *time.Time) error
+	UpdateTaskDue(userID, taskID int, dueAt, remindAt *time.Time) error
+	UpdateTaskPriority(userID, taskID, priority int) error
+	MoveTask(userID, taskID, anchorID int, after bool) error
+	UpdateTaskProject(userID, taskID int, projectID *int) error
+	UpdateTaskParent(userID, taskID int, parentID *int) error
+	UpdateTaskRecurrence(userID, taskID int, rule string) error
+	DeleteTask(userID, taskID, version int) error
+	Batch(userID int, ops []repository.TaskOp, atomic bool) ([]repository.TaskOpResult, error)
+	ImportTasks(userID int, tasks []entity.ImportTask) ([]int, error)
+	ExportTasks(userID int, fn func(task entity.Task) error) error
+	GetChanges(userID int, since entity.SyncToken) (entity.TaskChanges, error)
+	CurrentSyncToken() (entity.SyncToken, error)
+	PruneChanges(before time.Time) (int64, error)
 	SaveTaskToCache(ctx context.Context, userID int, task entity.Task) error
 }
 
+type Tags interface {
+	CreateTag(userID int, tag entity.Tag) (int, error)
+	GetAllTags(userID int) ([]entity.Tag, error)
+	GetTagByID(userID, tagID int) (entity.Tag, error)
+	UpdateTag(userID, tagID int, name string) error
+	DeleteTag(userID, tagID int) error
+	AttachTag(userID, taskID, tagID int) error
+	DetachTag(userID, taskID, tagID int) error
+}
+
+type Projects interface {
+	CreateProject(userID int, project entity.Project) (int, error)
+	GetAllProjects(userID int, withArchived bool) ([]entity.Project, error)
+	GetProjectByID(userID, projectID int) (entity.Project, error)
+	UpdateProject(userID, projectID int, upd entity.ProjectRequest) error
+	DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error
+}
+
+type Trash interface {
+	GetTrash(userID int) ([]entity.Task, error)
+	RestoreTask(userID, taskID int) error
+	PurgeTask(userID, taskID int) error
+	EmptyTrash(userID int) error
+	PurgeExpired(before time.Time) (int64, error)
+}
+
+type Sharing interface {
+	TaskRole(userID, taskID int) (entity.Role, error)
+	ProjectRole(userID, projectID int) (entity.Role, error)
+	TaskAudience(taskID int) ([]int, error)
+	TasksAudience(taskIDs []int) ([]int, error)
+	ProjectAudience(projectID int) ([]int, error)
+	GetTaskShares(userID, taskID int) ([]entity.TaskShare, error)
+	ShareTask(userID, taskID, granteeID int, role entity.Role) error
+	UnshareTask(userID, taskID, granteeID int) error
+	GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error)
+	ShareProject(userID, projectID, granteeID int, role entity.Role) error
+	UnshareProject(userID, projectID, granteeID int) error
+}
+
+type Comments interface {
+	GetComments(userID, taskID, afterID, limit int) ([]entity.Comment, error)
+	CreateComment(userID, taskID int, body string) (int, error)
+	UpdateComment(userID, taskID, commentID int, body string) error
+	DeleteComment(userID, taskID, commentID int) error
+}
+
+// Idempotency stores responses of requests sent with an Idempotency-Key.
+type Idempotency interface {
+	ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error)
+	SaveResponse(scope, key string, resp entity.IdempotentResponse) error
+	ExtendKey(scope, key string) error
+	ReleaseKey(scope, key string) error
+}
+
+// Feeds stores feed tokens and caches rendered calendar feeds.
+type Feeds interface {
+	SaveFeedToken(userID int, tokenHash string) (string, error)
+	DeleteFeedToken(userID int) (string, error)
+	GetFeedUser(tokenHash string) (int, error)
+	GetFeed(userID int) (*entity.Feed, error)
+	GetFeedVersion(userID int) (*entity.Feed, error)
+	SaveFeed(userID int, feed entity.Feed) error
+}
+
+// Events wakes up task event streams of a user on any replica.
+type Events interface {
+	SubscribeTasks(ctx context.Context, userID int) (<-chan struct{}, error)
+}
+
 type RedisRepository struct {
 	TaskList
+	Tags
+	Projects
+	Trash
+	Sharing
+	Comments
+	Idempotency
+	Feeds
+	Events
 }
 
 func NewRedisRepository(rdb *redis.Client, repo *repository.Repository) *RedisRepository {
+	tasks := NewTaskCache(rdb, repo.TaskList, repo.Sharing)
+
 	return &RedisRepository{
-		TaskList: NewTaskCache(rdb, repo),
+		TaskList:    tasks,
+		Tags:        NewTagCache(rdb, repo.Tags, tasks),
+		Projects:    NewProjectCache(rdb, repo.Projects, repo.Sharing),
+		Trash:       NewTrashCache(rdb, repo.Trash, repo.Sharing),
+		Sharing:     NewSharingCache(rdb, repo.Sharing),
+		Comments:    NewCommentCache(rdb, repo.Comments, tasks),
+		Idempotency: NewIdempotencyCache(rdb),
+		Feeds:       NewFeedCache(rdb, repo.Feeds),
+		Events:      NewEventCache(rdb),
 	}
 }
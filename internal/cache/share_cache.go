@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// SharingCache доступы не кеширует, но у получателя доступа меняется
+// набор видимых задач, поэтому его хэш задач сбрасывается.
+type SharingCache struct {
+	rdb  *redis.Client
+	repo repository.Sharing
+}
+
+func NewSharingCache(rdb *redis.Client, repo repository.Sharing) *SharingCache {
+	return &SharingCache{
+		rdb:  rdb,
+		repo: repo,
+	}
+}
+
+func (r *SharingCache) TaskRole(userID, taskID int) (entity.Role, error) {
+	return r.repo.TaskRole(userID, taskID)
+}
+
+func (r *SharingCache) ProjectRole(userID, projectID int) (entity.Role, error) {
+	return r.repo.ProjectRole(userID, projectID)
+}
+
+func (r *SharingCache) TaskAudience(taskID int) ([]int, error) {
+	return r.repo.TaskAudience(taskID)
+}
+
+func (r *SharingCache) TasksAudience(taskIDs []int) ([]int, error) {
+	return r.repo.TasksAudience(taskIDs)
+}
+
+func (r *SharingCache) ProjectAudience(projectID int) ([]int, error) {
+	return r.repo.ProjectAudience(projectID)
+}
+
+func (r *SharingCache) GetTaskShares(userID, taskID int) ([]entity.TaskShare, error) {
+	return r.repo.GetTaskShares(userID, taskID)
+}
+
+func (r *SharingCache) ShareTask(userID, taskID, granteeID int, role entity.Role) error {
+	if err := r.repo.ShareTask(userID, taskID, granteeID, role); err != nil {
+		return fmt.Errorf("failed to share task in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, granteeID)
+}
+
+func (r *SharingCache) UnshareTask(userID, taskID, granteeID int) error {
+	if err := r.repo.UnshareTask(userID, taskID, granteeID); err != nil {
+		return fmt.Errorf("failed to unshare task in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, granteeID)
+}
+
+func (r *SharingCache) GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error) {
+	return r.repo.GetProjectShares(userID, projectID)
+}
+
+func (r *SharingCache) ShareProject(userID, projectID, granteeID int, role entity.Role) error {
+	if err := r.repo.ShareProject(userID, projectID, granteeID, role); err != nil {
+		return fmt.Errorf("failed to share project in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, granteeID)
+}
+
+func (r *SharingCache) UnshareProject(userID, projectID, granteeID int) error {
+	if err := r.repo.UnshareProject(userID, projectID, granteeID); err != nil {
+		return fmt.Errorf("failed to unshare project in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, granteeID)
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// TagCache сам теги не кеширует, но следит, чтобы задачи в хэшах
+// "user:%d:tasks" и "user:%d:lists" не остались со старыми тегами.
+type TagCache struct {
+	rdb   *redis.Client
+	repo  repository.Tags
+	tasks *TaskCache
+}
+
+func NewTagCache(rdb *redis.Client, repo repository.Tags, tasks *TaskCache) *TagCache {
+	return &TagCache{
+		rdb:   rdb,
+		repo:  repo,
+		tasks: tasks,
+	}
+}
+
+func (r *TagCache) CreateTag(userID int, tag entity.Tag) (int, error) {
+	return r.repo.CreateTag(userID, tag)
+}
+
+func (r *TagCache) GetAllTags(userID int) ([]entity.Tag, error) {
+	return r.repo.GetAllTags(userID)
+}
+
+func (r *TagCache) GetTagByID(userID, tagID int) (entity.Tag, error) {
+	return r.repo.GetTagByID(userID, tagID)
+}
+
+func (r *TagCache) UpdateTag(userID, tagID int, name string) error {
+	audience, err := r.tagAudience(userID, tagID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.UpdateTag(userID, tagID, name); err != nil {
+		return fmt.Errorf("failed to update tag in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, audience...)
+}
+
+func (r *TagCache) DeleteTag(userID, tagID int) error {
+	// после удаления связей с задачами уже не узнать, кто их видел
+	audience, err := r.tagAudience(userID, tagID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.DeleteTag(userID, tagID); err != nil {
+		return fmt.Errorf("failed to delete tag in repository: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, audience...)
+}
+
+// tagAudience - владелец тега и все, кто видит задачи с ним: имя тега лежит
+// внутри каждой закешированной задачи, в том числе у тех, с кем она расшарена.
+func (r *TagCache) tagAudience(userID, tagID int) ([]int, error) {
+	taskIDs, err := r.repo.GetTagTaskIDs(userID, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag tasks: %w", err)
+	}
+
+	audience, err := r.tasks.share.TasksAudience(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks audience: %w", err)
+	}
+
+	return append(audience, userID), nil
+}
+
+func (r *TagCache) AttachTag(userID, taskID, tagID int) error {
+	if err := r.repo.AttachTag(userID, taskID, tagID); err != nil {
+		return fmt.Errorf("failed to attach tag in repository: %w", err)
+	}
+
+	return r.tasks.invalidateAudience(userID, taskID)
+}
+
+func (r *TagCache) DetachTag(userID, taskID, tagID int) error {
+	if err := r.repo.DetachTag(userID, taskID, tagID); err != nil {
+		return fmt.Errorf("failed to detach tag in repository: %w", err)
+	}
+
+	return r.tasks.invalidateAudience(userID, taskID)
+}
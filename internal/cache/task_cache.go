@@ -3,7 +3,9 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/AronditFire/todo-app/entity"
@@ -13,39 +15,40 @@ import (
 
 const TTL = 60 // время жизни кэша в секундах
 
+// TaskCache держит на каждого пользователя два хэша: "user:%d:tasks" с
+// задачами, которые читали по одной, и "user:%d:lists" с выборками списка.
+// В них лежат и чужие задачи, к которым дали доступ, поэтому изменение
+// задачи касается хэшей всех, кто её видит.
 type TaskCache struct {
-	rdb  *redis.Client
-	repo repository.TaskList
+	rdb   *redis.Client
+	repo  repository.TaskList
+	share repository.Sharing
 }
 
-func NewTaskCache(rdb *redis.Client, repo repository.TaskList) *TaskCache {
+func NewTaskCache(rdb *redis.Client, repo repository.TaskList, share repository.Sharing) *TaskCache {
 	return &TaskCache{
-		rdb:  rdb,
-		repo: repo,
+		rdb:   rdb,
+		repo:  repo,
+		share: share,
 	}
 }
 
-func (r *TaskCache) SaveTasksToCache(ctx context.Context, userID int, tasks []entity.Task) error {
-	key := fmt.Sprintf("user:%d:tasks", userID)
+// saveList кладёт выборку в хэш "user:%d:lists" под её фильтром.
+func (r *TaskCache) saveList(userID int, field string, tasks []entity.Task) error {
+	key := listsKey(userID)
 
-	hm := make(map[string]interface{}, len(tasks))
-	for _, t := range tasks {
-		b, err := json.Marshal(t)
-		if err != nil {
-			return err
-		}
-		// поле―строка, значение―JSON
-		hm[fmt.Sprint(t.ID)] = b
-	}
-	// Записываем сразу всё:
-	if err := r.rdb.HSet(ctx, key, hm).Err(); err != nil {
+	tasksJSON, err := json.Marshal(tasks)
+	if err != nil {
 		return err
 	}
-	// Ставим TTL в 60 секунд
 
-	if err := r.rdb.Expire(ctx, key, time.Second*time.Duration(TTL)).Err(); err != nil {
-		return err
+	pipe := r.rdb.TxPipeline()
+	pipe.HSet(ctx, key, field, tasksJSON)
+	pipe.Expire(ctx, key, time.Second*TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to execute pipeline while saving task list in redis: %w", err)
 	}
+
 	return nil
 }
 
@@ -69,62 +72,55 @@ func (r *TaskCache) SaveTaskToCache(ctx context.Context, userID int, task entity
 }
 
 func (r *TaskCache) CreateTask(userID int, task entity.Task) (int, error) {
-	key := fmt.Sprintf("user:%d:tasks", userID)
-
 	id, err := r.repo.CreateTask(userID, task)
 	if err != nil {
 		return 0, err
 	}
 
-	task.ID = id
-	task.UserID = userID
-
-	raw, err := json.Marshal(task)
-	if err != nil {
-		return 0, fmt.Errorf("failed to marshal task: %w", err)
-	}
-
-	pipe := r.rdb.TxPipeline()
-	pipe.HSet(ctx, key, fmt.Sprint(id), raw)
-	pipe.Expire(ctx, key, TTL*time.Second)
-	_, err = pipe.Exec(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("failed to execute pipeline: %w", err)
+	// подзадача принадлежит владельцу родителя и видна всем, кто видит родителя
+	if err := r.invalidateAudience(userID, id); err != nil {
+		return 0, err
 	}
 
-	return task.ID, nil
+	return id, nil
 }
 
-func (r *TaskCache) GetAllTask(userID int) ([]entity.Task, error) {
-	key := fmt.Sprintf("user:%d:tasks", userID)
+// GetAllTask кеширует каждую выборку, весь список или страницу, под её
+// фильтром вместе с курсором и размером страницы. Выборки по сроку считаются
+// от текущего времени и не повторяются, они идут мимо кэша.
+func (r *TaskCache) GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error) {
+	if !filter.DueFrom.IsZero() || !filter.DueTo.IsZero() {
+		tasks, err := r.repo.GetAllTask(userID, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tasks by due date from repository: %w", err)
+		}
+		return tasks, nil
+	}
 
-	data, err := r.rdb.HGetAll(ctx, key).Result()
+	field, err := json.Marshal(filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks from cache: %w", err)
+		return nil, err
 	}
-	var tasks []entity.Task
-	if len(data) > 0 {
-		// Кеш есть, десериализуем
-		for _, raw := range data {
-			var t entity.Task
-			if err := json.Unmarshal([]byte(raw), &t); err != nil {
-				return nil, err
-			}
-			tasks = append(tasks, t)
-		}
-		if err = r.rdb.Expire(ctx, key, time.Second*TTL).Err(); err != nil {
-			return nil, fmt.Errorf("failed to set expiration for cache key: %w", err)
+
+	data, err := r.rdb.HGet(ctx, listsKey(userID), string(field)).Result()
+	if err == nil {
+		var tasks []entity.Task
+		if err := json.Unmarshal([]byte(data), &tasks); err != nil {
+			return nil, err
 		}
 		return tasks, nil
 	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get tasks from cache: %w", err)
+	}
 
 	// Кеша нет, получаем из репозитория
-	tasks, err = r.repo.GetAllTask(userID)
+	tasks, err := r.repo.GetAllTask(userID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks from repository: %w", err)
 	}
 
-	if err := r.SaveTasksToCache(ctx, userID, tasks); err != nil {
+	if err := r.saveList(userID, string(field), tasks); err != nil {
 		return nil, fmt.Errorf("failed to save tasks to cache: %w", err)
 	}
 
@@ -135,7 +131,7 @@ func (r *TaskCache) GetTaskByID(userID, id int) (entity.Task, error) {
 	key := fmt.Sprintf("user:%d:tasks", userID)
 
 	data, err := r.rdb.HGet(ctx, key, fmt.Sprint(id)).Result()
-	if err != nil {
+	if err != nil && !errors.Is(err, redis.Nil) {
 		return entity.Task{}, fmt.Errorf("failed to get task from cache: %w", err)
 	}
 	var task entity.Task
@@ -164,33 +160,210 @@ func (r *TaskCache) GetTaskByID(userID, id int) (entity.Task, error) {
 	return task, nil
 }
 
-func (r *TaskCache) UpdateTask(userID, taskId int, desc string) error {
-	if err := r.repo.UpdateTask(userID, taskId, desc); err != nil {
+func (r *TaskCache) UpdateTask(userID, taskId int, desc string, version int) error {
+	if err := r.repo.UpdateTask(userID, taskId, desc, version); err != nil {
 		return fmt.Errorf("failed to update task in repository: %w", err)
 	}
 
-	task, err := r.repo.GetTaskByID(userID, taskId)
+	return r.invalidateAudience(userID, taskId)
+}
+
+// PatchTask may move the task to another project or parent, so the old audience is reset too.
+func (r *TaskCache) PatchTask(userID, taskID, version int, apply func(task *entity.Task) error) error {
+	before, err := r.share.TaskAudience(taskID)
 	if err != nil {
-		return fmt.Errorf("failed to get updated task: %w", err)
+		return fmt.Errorf("failed to get task audience: %w", err)
 	}
 
-	if err := r.SaveTaskToCache(ctx, userID, task); err != nil {
-		return fmt.Errorf("failed to save updated task to cache: %w", err)
+	if err := r.repo.PatchTask(userID, taskID, version, apply); err != nil {
+		return fmt.Errorf("failed to patch task in repository: %w", err)
 	}
 
-	return nil
+	return r.invalidateAudience(userID, taskID, before...)
+}
+
+func (r *TaskCache) UpdateTaskStatus(userID, taskID int, status entity.TaskStatus, completedAt *time.Time) error {
+	if err := r.repo.UpdateTaskStatus(userID, taskID, status, completedAt); err != nil {
+		return fmt.Errorf("failed to update task status in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID)
+}
+
+func (r *TaskCache) UpdateTaskDue(userID, taskID int, dueAt, remindAt *time.Time) error {
+	if err := r.repo.UpdateTaskDue(userID, taskID, dueAt, remindAt); err != nil {
+		return fmt.Errorf("failed to update task due date in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID)
 }
 
-func (r *TaskCache) DeleteTask(userID, taskID int) error {
-	if err := r.repo.DeleteTask(userID, taskID); err != nil {
+func (r *TaskCache) UpdateTaskPriority(userID, taskID, priority int) error {
+	if err := r.repo.UpdateTaskPriority(userID, taskID, priority); err != nil {
+		return fmt.Errorf("failed to update task priority in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID)
+}
+
+func (r *TaskCache) MoveTask(userID, taskID, anchorID int, after bool) error {
+	if err := r.repo.MoveTask(userID, taskID, anchorID, after); err != nil {
+		return fmt.Errorf("failed to move task in repository: %w", err)
+	}
+
+	// при перенумерации меняются позиции многих задач, проще сбросить весь хэш
+	return r.invalidateAudience(userID, taskID)
+}
+
+func (r *TaskCache) UpdateTaskProject(userID, taskID int, projectID *int) error {
+	// у задачи меняется аудитория: те, кто видел её через старый проект, тоже сбрасываются
+	before, err := r.share.TaskAudience(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task audience: %w", err)
+	}
+
+	if err := r.repo.UpdateTaskProject(userID, taskID, projectID); err != nil {
+		return fmt.Errorf("failed to update task project in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID, before...)
+}
+
+func (r *TaskCache) UpdateTaskParent(userID, taskID int, parentID *int) error {
+	// у задачи меняется аудитория: те, кто видел её через старый родитель, тоже сбрасываются
+	before, err := r.share.TaskAudience(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task audience: %w", err)
+	}
+
+	if err := r.repo.UpdateTaskParent(userID, taskID, parentID); err != nil {
+		return fmt.Errorf("failed to update task parent in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID, before...)
+}
+
+func (r *TaskCache) UpdateTaskRecurrence(userID, taskID int, rule string) error {
+	if err := r.repo.UpdateTaskRecurrence(userID, taskID, rule); err != nil {
+		return fmt.Errorf("failed to update task recurrence in repository: %w", err)
+	}
+
+	return r.invalidateAudience(userID, taskID)
+}
+
+func (r *TaskCache) DeleteTask(userID, taskID, version int) error {
+	if err := r.repo.DeleteTask(userID, taskID, version); err != nil {
 		return fmt.Errorf("failed to delete task in repository: %w", err)
 	}
 
-	key := fmt.Sprintf("user:%d:tasks", userID)
+	// вместе с задачей в корзину ушли и её подзадачи,
+	// доступ от deleted_at не зависит, так что аудиторию ещё можно узнать
+	return r.invalidateAudience(userID, taskID)
+}
 
-	if err := r.rdb.HDel(ctx, key, fmt.Sprint(taskID)).Err(); err != nil {
-		return fmt.Errorf("failed to delete task from cache: %w", err)
+// Batch сбрасывает хэши всех, кто видел задачи пакета до записи или видит
+// после, одним DEL вместо обновления кэша на каждую операцию.
+func (r *TaskCache) Batch(userID int, ops []repository.TaskOp, atomic bool) ([]repository.TaskOpResult, error) {
+	var taskIDs []int
+	for _, op := range ops {
+		if op.TaskID > 0 {
+			taskIDs = append(taskIDs, op.TaskID)
+		}
 	}
 
-	return nil
+	before, err := r.share.TasksAudience(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks audience: %w", err)
+	}
+
+	results, err := r.repo.Batch(userID, ops, atomic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run batch in repository: %w", err)
+	}
+
+	for _, res := range results {
+		if res.Err == nil && res.ID > 0 {
+			taskIDs = append(taskIDs, res.ID)
+		}
+	}
+
+	after, err := r.share.TasksAudience(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks audience: %w", err)
+	}
+
+	users := append(append([]int{userID}, before...), after...)
+	return results, invalidateTasks(r.rdb, users...)
+}
+
+// ImportTasks создаёт только собственные задачи пользователя, поэтому
+// сбрасывается один его хэш.
+func (r *TaskCache) ImportTasks(userID int, tasks []entity.ImportTask) ([]int, error) {
+	ids, err := r.repo.ImportTasks(userID, tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import tasks in repository: %w", err)
+	}
+
+	return ids, invalidateTasks(r.rdb, userID)
+}
+
+// ExportTasks читает мимо кэша: хэш хранит весь список целиком, а выгрузка
+// идёт потоком.
+func (r *TaskCache) ExportTasks(userID int, fn func(task entity.Task) error) error {
+	return r.repo.ExportTasks(userID, fn)
+}
+
+// GetChanges читает мимо кэша: изменения и токен должны быть из одного снимка БД.
+func (r *TaskCache) GetChanges(userID int, since entity.SyncToken) (entity.TaskChanges, error) {
+	return r.repo.GetChanges(userID, since)
+}
+
+func (r *TaskCache) CurrentSyncToken() (entity.SyncToken, error) {
+	return r.repo.CurrentSyncToken()
+}
+
+// PruneChanges трогает только журнал изменений, в кэше его нет.
+func (r *TaskCache) PruneChanges(before time.Time) (int64, error) {
+	return r.repo.PruneChanges(before)
+}
+
+// invalidateAudience сбрасывает хэши автора изменения, всех, кто видит
+// задачу, и пользователей из also. Поле задачи в хэше не переписывается:
+// хэша может не быть, а Expire на новом ключе оставил бы хэш из одного поля.
+func (r *TaskCache) invalidateAudience(userID, taskID int, also ...int) error {
+	audience, err := r.share.TaskAudience(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task audience: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, append(append(audience, also...), userID)...)
+}
+
+// invalidateTasks сбрасывает хэши задач и выборок пользователей целиком,
+// когда изменение затронуло много задач сразу, и их календари, и будит их
+// потоки событий.
+func invalidateTasks(rdb *redis.Client, userIDs ...int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	// автор изменения обычно есть и в аудитории задачи
+	userIDs = slices.Clone(userIDs)
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+
+	keys := make([]string, 0, 3*len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, fmt.Sprintf("user:%d:tasks", id), listsKey(id), feedKey(id))
+	}
+
+	if err := rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate tasks cache: %w", err)
+	}
+
+	return notifyTasks(rdb, userIDs...)
+}
+
+func listsKey(userID int) string {
+	return fmt.Sprintf("user:%d:lists", userID)
 }
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// TrashCache корзину не кеширует: удалённых задач нет в хэше,
+// а восстановленные возвращаются в него при следующем чтении.
+type TrashCache struct {
+	rdb   *redis.Client
+	repo  repository.Trash
+	share repository.Sharing
+}
+
+func NewTrashCache(rdb *redis.Client, repo repository.Trash, share repository.Sharing) *TrashCache {
+	return &TrashCache{
+		rdb:   rdb,
+		repo:  repo,
+		share: share,
+	}
+}
+
+func (r *TrashCache) GetTrash(userID int) ([]entity.Task, error) {
+	return r.repo.GetTrash(userID)
+}
+
+func (r *TrashCache) RestoreTask(userID, taskID int) error {
+	if err := r.repo.RestoreTask(userID, taskID); err != nil {
+		return fmt.Errorf("failed to restore task in repository: %w", err)
+	}
+
+	// вместе с задачей восстановлены подзадачи, проще сбросить хэши всех, кто их видит
+	audience, err := r.share.TaskAudience(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task audience: %w", err)
+	}
+
+	return invalidateTasks(r.rdb, append(audience, userID)...)
+}
+
+func (r *TrashCache) PurgeTask(userID, taskID int) error {
+	return r.repo.PurgeTask(userID, taskID)
+}
+
+func (r *TrashCache) EmptyTrash(userID int) error {
+	return r.repo.EmptyTrash(userID)
+}
+
+func (r *TrashCache) PurgeExpired(before time.Time) (int64, error) {
+	return r.repo.PurgeExpired(before)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -21,10 +22,32 @@ func InitDB() (*gorm.DB, error) {
 		log.Fatalf("Could not connect to database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&entity.Task{}, &entity.User{}); err != nil {
+	if err := db.AutoMigrate(&entity.Task{}, &entity.User{}, &entity.Tag{}, &entity.Project{}, &entity.TaskHistory{},
+		&entity.TaskShare{}, &entity.ProjectShare{}, &entity.TaskAssignee{}, &entity.Comment{},
+		&entity.Attachment{}, &entity.PurgedBlob{}, &entity.FeedToken{}, &entity.AppPassword{}, &entity.CalendarObject{}); err != nil {
 		log.Fatalf("Failed to migrate tables: %v", err)
 	}
 
+	if err := repository.MigrateSearch(db); err != nil {
+		log.Fatalf("Failed to migrate search index: %v", err)
+	}
+
+	if err := repository.MigrateAccess(db); err != nil {
+		log.Fatalf("Failed to migrate access views: %v", err)
+	}
+
+	if err := repository.MigrateVersion(db); err != nil {
+		log.Fatalf("Failed to migrate task versions: %v", err)
+	}
+
+	if err := repository.MigrateChanges(db); err != nil {
+		log.Fatalf("Failed to migrate change log: %v", err)
+	}
+
+	if err := repository.MigrateCalDAV(db); err != nil {
+		log.Fatalf("Failed to migrate calendar objects: %v", err)
+	}
+
 	return db, err
 }
 
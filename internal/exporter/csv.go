@@ -0,0 +1,54 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// csvHeader starts with the columns the csv import reads, so an export can
+// be imported back.
+var csvHeader = []string{
+	"title", "due_date", "priority", "labels", "completed",
+	"id", "status", "remind_at", "project_id", "parent_id", "recurrence", "created_at", "completed_at",
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	return cw, cw.w.Write(csvHeader)
+}
+
+func (cw *csvWriter) Write(task entity.Task) error {
+	completed := "no"
+	if task.Status == entity.StatusDone {
+		completed = "yes"
+	}
+
+	return cw.w.Write([]string{
+		task.Description,
+		formatTime(task.DueAt),
+		strconv.Itoa(task.Priority),
+		strings.Join(tagNames(task), ","),
+		completed,
+		strconv.Itoa(task.ID),
+		string(task.Status),
+		formatTime(task.RemindAt),
+		formatID(task.ProjectID),
+		formatID(task.ParentID),
+		task.Recurrence,
+		formatTime(&task.CreatedAt),
+		formatTime(task.CompletedAt),
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
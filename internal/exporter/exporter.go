@@ -0,0 +1,73 @@
+// Package exporter writes tasks to files other tools and backup scripts can
+// read. Tasks are written one by one as they are read from the database,
+// the same tasks always give the same bytes.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// Writer encodes tasks into an export. Close writes what ends the file, it
+// does not close the underlying writer.
+type Writer interface {
+	Write(task entity.Task) error
+	Close() error
+}
+
+// New returns the writer of the format.
+func New(format entity.ExportFormat, w io.Writer) (Writer, error) {
+	switch format {
+	case entity.ExportCSV:
+		return newCSVWriter(w)
+	case entity.ExportJSON:
+		return &jsonWriter{w: w}, nil
+	case entity.ExportMarkdown:
+		return newMarkdownWriter(w)
+	case entity.ExportICS:
+		return NewICSWriter(w, "Tasks")
+	}
+
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// sortedTags orders the tags of a task by name, the database gives them in
+// no particular order.
+func sortedTags(tags []entity.Tag) []entity.Tag {
+	sorted := append([]entity.Tag(nil), tags...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func tagNames(task entity.Task) []string {
+	names := make([]string, 0, len(task.Tags))
+	for _, tag := range sortedTags(task.Tags) {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// formatTime writes times in UTC, so the export does not depend on the
+// time zone of the server.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprint(*id)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "#", `\#`, "~", `\~`, "\r\n", " ", "\n", " ",
+)
@@ -0,0 +1,170 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/importer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	created := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 5, 6, 11, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	completed := time.Date(2025, 5, 5, 18, 30, 0, 0, time.UTC)
+	parentID := 1
+
+	tasks := []entity.Task{
+		{
+			ID:          1,
+			Description: "Buy milk, bread; eggs",
+			Status:      entity.StatusTodo,
+			DueAt:       &due,
+			Priority:    2,
+			Tags:        []entity.Tag{{Name: "shop"}, {Name: "home"}},
+			CreatedAt:   created,
+		},
+		{
+			ID:          2,
+			Description: "Check *prices*",
+			Status:      entity.StatusDone,
+			CompletedAt: &completed,
+			Priority:    4,
+			ParentID:    &parentID,
+			CreatedAt:   created,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format entity.ExportFormat
+		tasks  []entity.Task
+		want   string
+	}{
+		{
+			name:   "CSV",
+			format: entity.ExportCSV,
+			tasks:  tasks,
+			want: "title,due_date,priority,labels,completed,id,status,remind_at,project_id,parent_id,recurrence,created_at,completed_at\n" +
+				"\"Buy milk, bread; eggs\",2025-05-06T08:00:00Z,2,\"home,shop\",no,1,todo,,,,,2025-05-01T08:00:00Z,\n" +
+				"Check *prices*,,4,,yes,2,done,,,1,,2025-05-01T08:00:00Z,2025-05-05T18:30:00Z\n",
+		},
+		{
+			name:   "JSON Empty",
+			format: entity.ExportJSON,
+			want:   "[]\n",
+		},
+		{
+			name:   "Markdown",
+			format: entity.ExportMarkdown,
+			tasks:  tasks,
+			want: "# Tasks\n\n" +
+				"- [ ] Buy milk, bread; eggs — P2, due 2025-05-06T08:00:00Z, #home, #shop\n" +
+				"- [x] Check \\*prices\\* — P4, subtask of 1\n",
+		},
+		{
+			name:   "ICS",
+			format: entity.ExportICS,
+			tasks:  tasks,
+			want: "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"PRODID:-//todo-app//Tasks//EN\r\n" +
+				"CALSCALE:GREGORIAN\r\n" +
+				"X-WR-CALNAME:Tasks\r\n" +
+				"BEGIN:VTODO\r\n" +
+				"UID:task-1@todo-app\r\n" +
+				"DTSTAMP:20250501T080000Z\r\n" +
+				"CREATED:20250501T080000Z\r\n" +
+				"SUMMARY:Buy milk\\, bread\\; eggs\r\n" +
+				"DUE:20250506T080000Z\r\n" +
+				"STATUS:NEEDS-ACTION\r\n" +
+				"PRIORITY:3\r\n" +
+				"CATEGORIES:home,shop\r\n" +
+				"END:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := New(tt.format, &buf)
+			assert.NoError(t, err)
+
+			for _, task := range tt.tasks {
+				assert.NoError(t, w.Write(task))
+			}
+			assert.NoError(t, w.Close())
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(entity.ExportJSON, &buf)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Write(entity.Task{ID: 1, Description: "A", Tags: []entity.Tag{{ID: 2, Name: "b"}, {ID: 1, Name: "a"}}}))
+	assert.NoError(t, w.Write(entity.Task{ID: 2, Description: "B"}))
+	assert.NoError(t, w.Close())
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "[", lines[0])
+	assert.Contains(t, lines[1], `"tags":[{"id":1,"name":"a"},{"id":2,"name":"b"}]`)
+	assert.True(t, strings.HasSuffix(lines[1], ","))
+	assert.Equal(t, "]", lines[3])
+}
+
+func TestICSWriterFolding(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewICSWriter(&buf, "Tasks")
+	assert.NoError(t, err)
+
+	due := time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, w.Write(entity.Task{ID: 1, Description: strings.Repeat("задача ", 20), DueAt: &due}))
+	assert.NoError(t, w.Write(entity.Task{ID: 2, Description: "no due date"}))
+	assert.NoError(t, w.Close())
+
+	out := buf.String()
+	assert.NotContains(t, out, "no due date")
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "line %q splits a character", line)
+	}
+	assert.Contains(t, strings.ReplaceAll(out, "\r\n ", ""), "SUMMARY:"+strings.Repeat("задача ", 20)+"\r\n")
+}
+
+// A CalDAV client gets back what it sent: the task survives a round trip
+// through the importer.
+func TestWriteCalendarObject(t *testing.T) {
+	due := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	remind := due.Add(-time.Hour)
+	task := entity.Task{
+		ID:          5,
+		Description: "Pay rent; by card, online",
+		Status:      entity.StatusInProgress,
+		DueAt:       &due,
+		RemindAt:    &remind,
+		Priority:    2,
+		Recurrence:  "FREQ=MONTHLY",
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCalendarObject(&buf, task, "client-uid"))
+
+	parsed, uid, err := importer.ParseVTODO(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-uid", uid)
+	task.ID = 0
+	assert.Equal(t, task, parsed)
+
+	buf.Reset()
+	assert.NoError(t, WriteCalendarObject(&buf, entity.Task{ID: 6, Description: "Someday"}, ""))
+	assert.Contains(t, buf.String(), "UID:task-6@todo-app\r\n")
+}
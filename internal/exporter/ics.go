@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+const (
+	icsProdID  = "-//todo-app//Tasks//EN"
+	icsTime    = "20060102T150405Z"
+	icsMaxLine = 75 // октетов без CRLF, RFC 5545 3.1
+	icsUIDHost = "todo-app"
+)
+
+// icsStatuses maps task statuses onto VTODO STATUS values.
+var icsStatuses = map[entity.TaskStatus]string{
+	entity.StatusTodo:       "NEEDS-ACTION",
+	entity.StatusInProgress: "IN-PROCESS",
+	entity.StatusDone:       "COMPLETED",
+	entity.StatusCancelled:  "CANCELLED",
+}
+
+// icsPriorities maps P1-P4 onto the 1 (highest) to 9 (lowest) scale of iCalendar.
+var icsPriorities = map[int]int{1: 1, 2: 3, 3: 5, 4: 9}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// ICSWriter writes an iCalendar calendar (RFC 5545) where every task with a
+// due date is a VTODO. Tasks without a due date are skipped: calendar
+// clients have nowhere to show them. DTSTAMP is the creation time of the
+// task rather than the time of the export, so the output stays the same
+// until the tasks change.
+type ICSWriter struct {
+	w *bufio.Writer
+}
+
+// NewICSWriter starts a calendar called name.
+func NewICSWriter(w io.Writer, name string) (*ICSWriter, error) {
+	iw := &ICSWriter{w: bufio.NewWriter(w)}
+	iw.begin()
+	iw.line("X-WR-CALNAME:" + icsEscaper.Replace(name))
+	return iw, iw.flush()
+}
+
+func (iw *ICSWriter) Write(task entity.Task) error {
+	if task.DueAt == nil {
+		return nil
+	}
+
+	iw.todo(task, TaskUID(task.ID))
+	return iw.flush()
+}
+
+func (iw *ICSWriter) Close() error {
+	iw.line("END:VCALENDAR")
+	return iw.flush()
+}
+
+// WriteCalendarObject writes a CalDAV calendar object resource: a calendar
+// with the one VTODO of the task, with or without a due date. uid is the UID
+// the client gave the task, TaskUID is used when it is empty.
+func WriteCalendarObject(w io.Writer, task entity.Task, uid string) error {
+	if uid == "" {
+		uid = TaskUID(task.ID)
+	}
+
+	iw := &ICSWriter{w: bufio.NewWriter(w)}
+	iw.begin()
+	iw.todo(task, uid)
+	return iw.Close()
+}
+
+func (iw *ICSWriter) begin() {
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:" + icsProdID)
+	iw.line("CALSCALE:GREGORIAN")
+}
+
+func (iw *ICSWriter) todo(task entity.Task, uid string) {
+	iw.line("BEGIN:VTODO")
+	iw.line("UID:" + icsEscaper.Replace(uid))
+	iw.line("DTSTAMP:" + task.CreatedAt.UTC().Format(icsTime))
+	iw.line("CREATED:" + task.CreatedAt.UTC().Format(icsTime))
+	iw.line("SUMMARY:" + icsEscaper.Replace(task.Description))
+	if task.DueAt != nil {
+		iw.line("DUE:" + task.DueAt.UTC().Format(icsTime))
+	}
+	if status, ok := icsStatuses[task.Status]; ok {
+		iw.line("STATUS:" + status)
+	}
+	if priority, ok := icsPriorities[task.Priority]; ok {
+		iw.line(fmt.Sprintf("PRIORITY:%d", priority))
+	}
+	if task.CompletedAt != nil {
+		iw.line("COMPLETED:" + task.CompletedAt.UTC().Format(icsTime))
+	}
+	if task.Recurrence != "" {
+		iw.line("RRULE:" + strings.TrimPrefix(task.Recurrence, "RRULE:"))
+	}
+	if task.ParentID != nil {
+		iw.line("RELATED-TO:" + TaskUID(*task.ParentID))
+	}
+	if names := tagNames(task); len(names) > 0 {
+		for i, name := range names {
+			names[i] = icsEscaper.Replace(name)
+		}
+		iw.line("CATEGORIES:" + strings.Join(names, ","))
+	}
+	if task.RemindAt != nil {
+		iw.line("BEGIN:VALARM")
+		iw.line("ACTION:DISPLAY")
+		iw.line("DESCRIPTION:" + icsEscaper.Replace(task.Description))
+		iw.line("TRIGGER;VALUE=DATE-TIME:" + task.RemindAt.UTC().Format(icsTime))
+		iw.line("END:VALARM")
+	}
+	iw.line("END:VTODO")
+}
+
+// TaskUID is the UID of the task's VTODO, stable for the life of the task.
+func TaskUID(taskID int) string {
+	return fmt.Sprintf("task-%d@%s", taskID, icsUIDHost)
+}
+
+// line writes a content line folded into lines of at most 75 octets, a
+// continuation line starts with a space. Multi-byte characters are not split.
+func (iw *ICSWriter) line(s string) {
+	limit := icsMaxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		iw.w.WriteString(s[:cut])
+		iw.w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icsMaxLine - 1 // пробел в начале строки тоже считается
+	}
+	iw.w.WriteString(s)
+	iw.w.WriteString("\r\n")
+}
+
+// flush reports the first write error, bufio.Writer keeps it until then.
+func (iw *ICSWriter) flush() error {
+	return iw.w.Flush()
+}
@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// jsonWriter writes a JSON array, one task per line.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonWriter) Write(task entity.Task) error {
+	task.Tags = sortedTags(task.Tags)
+	b, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if jw.count == 0 {
+		sep = "[\n"
+	}
+	jw.count++
+
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "\n]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
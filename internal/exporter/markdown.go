@@ -0,0 +1,55 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// markdownWriter writes a task list: "- [x] Buy milk — due 2025-05-06T09:00:00Z, P2, #home".
+type markdownWriter struct {
+	w io.Writer
+}
+
+func newMarkdownWriter(w io.Writer) (*markdownWriter, error) {
+	_, err := io.WriteString(w, "# Tasks\n\n")
+	return &markdownWriter{w: w}, err
+}
+
+func (mw *markdownWriter) Write(task entity.Task) error {
+	box := "[ ]"
+	if task.Status.IsClosed() {
+		box = "[x]"
+	}
+
+	title := markdownEscaper.Replace(task.Description)
+	if task.Status == entity.StatusCancelled {
+		title = "~~" + title + "~~"
+	}
+
+	details := []string{fmt.Sprintf("P%d", task.Priority)}
+	if task.Status == entity.StatusInProgress {
+		details = append(details, "in progress")
+	}
+	if task.DueAt != nil {
+		details = append(details, "due "+formatTime(task.DueAt))
+	}
+	if task.Recurrence != "" {
+		details = append(details, "repeats "+task.Recurrence)
+	}
+	if task.ParentID != nil {
+		details = append(details, fmt.Sprintf("subtask of %d", *task.ParentID))
+	}
+	for _, name := range tagNames(task) {
+		details = append(details, "#"+markdownEscaper.Replace(name))
+	}
+
+	_, err := fmt.Fprintf(mw.w, "- %s %s — %s\n", box, title, strings.Join(details, ", "))
+	return err
+}
+
+func (mw *markdownWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetAppPasswordsResponse struct {
+	Data []entity.AppPassword `json:"data"`
+}
+
+// @Summary Get app passwords
+// @Security ApiKeyAuth
+// @Tags caldav
+// @Description names of the app passwords CalDAV clients sign in with, the passwords themselves are not kept
+// @ID get-app-passwords
+// @Produce  json
+// @Success 200 {object} GetAppPasswordsResponse
+// @Failure 500 {string} string "error"
+// @Router /api/app-passwords [get]
+func (h *Handler) getAppPasswords(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	passwords, err := h.services.CalDAV.GetAppPasswords(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get app passwords",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAppPasswordsResponse{
+		Data: passwords,
+	})
+}
+
+// @Summary Create app password
+// @Security ApiKeyAuth
+// @Tags caldav
+// @Description issues a password for a CalDAV client (Apple Reminders, Thunderbird, DAVx5). Sign in at /caldav/ with your username and this password. It is shown only once
+// @ID create-app-password
+// @Accept  json
+// @Produce  json
+// @Param input body entity.AppPasswordRequest true "client name"
+// @Success 201 {object} entity.AppPasswordResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/app-passwords [post]
+func (h *Handler) createAppPassword(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req entity.AppPasswordRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating app password",
+		})
+		return
+	}
+
+	password, err := h.services.CalDAV.CreateAppPassword(userID, req.Name)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create app password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, password)
+}
+
+// @Summary Delete app password
+// @Security ApiKeyAuth
+// @Tags caldav
+// @Description the client signed in with it stops syncing
+// @ID delete-app-password
+// @Produce  json
+// @Param id path int true "app password id"
+// @Success 200 {string} string "deleted"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/app-passwords/{id} [delete]
+func (h *Handler) deleteAppPassword(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid app password id",
+		})
+		return
+	}
+
+	if err := h.services.CalDAV.DeleteAppPassword(userID, id); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not delete app password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetAssigneesResponse struct {
+	Data []entity.TaskAssignee `json:"data"`
+}
+
+// @Summary Get task assignees
+// @Security ApiKeyAuth
+// @Tags assignments
+// @ID get-task-assignees
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} GetAssigneesResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/assignees [get]
+func (h *Handler) getAssignees(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	assignees, err := h.services.Assignments.GetAssignees(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task assignees",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAssigneesResponse{
+		Data: assignees,
+	})
+}
+
+// @Summary Assign task
+// @Security ApiKeyAuth
+// @Tags assignments
+// @Description replace assignees of a task in a project, every assignee must be a member of the project; an empty list unassigns everybody
+// @ID assign-task
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.AssignRequest true "user ids"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 422 {object} map[string]any "error and fields: user_ids -> problem"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/assignees [put]
+func (h *Handler) setAssignees(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.AssignRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while assigning task",
+		})
+		return
+	}
+
+	if err := h.services.Assignments.SetAssignees(userID, id, req.UserIDs); err != nil {
+		if abortFieldErrors(c, err) {
+			return
+		}
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not assign task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "assignees updated",
+	})
+}
+
+// @Summary Get tasks assigned to me
+// @Security ApiKeyAuth
+// @Tags assignments
+// @Description tasks assigned to the user across all projects, nearest due date first
+// @ID get-assigned-tasks
+// @Produce  json
+// @Success 200 {object} GetAllTaskResponse
+// @Failure 500 {string} string "error"
+// @Router /api/assigned [get]
+func (h *Handler) getAssignedTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tasks, err := h.services.Assignments.GetAssignedTasks(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get assigned tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTaskResponse{
+		Data: tasks,
+	})
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadBody leaves room for multipart headers around the largest file.
+const maxUploadBody = service.MaxAttachmentSize + 1<<20
+
+type GetAttachmentsResponse struct {
+	Data []entity.Attachment `json:"data"`
+}
+
+// @Summary Get task attachments
+// @Security ApiKeyAuth
+// @Tags attachments
+// @ID get-task-attachments
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} GetAttachmentsResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/attachments [get]
+func (h *Handler) getAttachments(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	attachments, err := h.services.Attachments.GetAttachments(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task attachments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAttachmentsResponse{
+		Data: attachments,
+	})
+}
+
+// @Summary Attach file
+// @Security ApiKeyAuth
+// @Tags attachments
+// @Description up to 10 MiB; images, pdf, zip (office documents) and plain text, the type is detected from the content
+// @ID upload-task-attachment
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "task id"
+// @Param file formData file true "file to attach"
+// @Success 201 {integer} integer 1
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 413 {string} string "error"
+// @Failure 415 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/attachments [post]
+func (h *Handler) uploadAttachment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBody)
+
+	var bindfile entity.BindFile
+	if err := c.ShouldBind(&bindfile); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "file is too large",
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "could not bind uploaded file",
+		})
+		return
+	}
+
+	attachmentID, err := h.services.Attachments.UploadAttachment(userID, id, bindfile.File)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not attach file",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      attachmentID,
+	})
+}
+
+// @Summary Download attachment
+// @Security ApiKeyAuth
+// @Tags attachments
+// @ID download-task-attachment
+// @Produce  octet-stream
+// @Param id path int true "task id"
+// @Param attachment_id path int true "attachment id"
+// @Success 200 {file} file
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/attachments/{attachment_id} [get]
+func (h *Handler) downloadAttachment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid attachment id",
+		})
+		return
+	}
+
+	attachment, body, err := h.services.Attachments.OpenAttachment(userID, id, attachmentID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get attachment",
+		})
+		return
+	}
+	defer body.Close()
+
+	// всегда скачивание: загруженный html не должен открываться на нашем домене
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary Delete attachment
+// @Security ApiKeyAuth
+// @Tags attachments
+// @ID delete-task-attachment
+// @Produce  json
+// @Param id path int true "task id"
+// @Param attachment_id path int true "attachment id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/attachments/{attachment_id} [delete]
+func (h *Handler) deleteAttachment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid attachment id",
+		})
+		return
+	}
+
+	if err := h.services.Attachments.DeleteAttachment(userID, id, attachmentID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not delete attachment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
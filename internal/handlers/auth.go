@@ -15,6 +15,7 @@ import (
 // @Accept  json
 // @Produce  json
 // @Param input body entity.UserRegisterRequest true "account info"
+// @Param Idempotency-Key header string false "retry with the same key and body replays the first response"
 // @Success 201 {string} string "message"
 // @Failure 400 {string} string "error"
 // @Failure 500 {string} string "error"
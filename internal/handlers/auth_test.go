@@ -69,7 +69,7 @@ func TestHandler_registerUser(t *testing.T) {
 			repo := mock_service.NewMockAuthorization(c)
 			tt.mockBehaivor(repo, tt.inputUser)
 
-			service := &service.Service{Authorization: /*repo*/ nil}
+			service := &service.Service{Authorization: repo}
 			handler := NewHander(service)
 
 			// Arrange
@@ -145,8 +145,8 @@ func TestHandler_loginUser(t *testing.T) {
 
 			tt.mockBehaivor(repo, tt.inputUser)
 
-			service := &service.Service{Authorization: /*repo*/ nil}
-			handler := &Handler{service}
+			service := &service.Service{Authorization: repo}
+			handler := NewHander(service)
 
 			r := gin.New()
 			r.POST("/auth/sign-in", handler.loginUser)
@@ -248,8 +248,8 @@ func TestHandler_refreshTokens(t *testing.T) {
 			tt.mockRefreshBehaivor(repo, tt.inputToken.RefreshToken)
 			tt.mockRenewBehaivor(repo, tt.inputID)
 
-			service := &service.Service{Authorization: /*repo*/ nil}
-			handler := &Handler{service}
+			service := &service.Service{Authorization: repo}
+			handler := NewHander(service)
 
 			r := gin.New()
 			r.POST("/auth/refresh", handler.refreshTokens)
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type BatchResponse struct {
+	Results []entity.BatchResult `json:"results"`
+}
+
+// @Summary Run batch
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description create, update (merge patch), delete and complete up to 500 tasks in one transaction. An atomic batch (default) is applied only if every operation succeeds, otherwise nothing is applied and the response is 422; a best_effort batch skips failed operations and applies the rest
+// @ID run-batch
+// @Accept  json
+// @Produce  json
+// @Param input body entity.BatchRequest true "operations"
+// @Param Idempotency-Key header string false "retry with the same key and body replays the first response"
+// @Success 200 {object} BatchResponse
+// @Failure 422 {object} BatchResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/batch [post]
+func (h *Handler) runBatch(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req entity.BatchRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while running batch",
+		})
+		return
+	}
+
+	if len(req.Operations) == 0 || !req.Mode.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid batch",
+		})
+		return
+	}
+
+	results, err := h.services.TaskList.Batch(userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not run batch",
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if req.Mode != entity.BatchBestEffort {
+		for _, res := range results {
+			if res.Status == entity.BatchFailed {
+				status = http.StatusUnprocessableEntity
+				break
+			}
+		}
+	}
+
+	c.JSON(status, BatchResponse{
+		Results: results,
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_runBatch(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	failed := []entity.BatchResult{
+		{Index: 0, Op: entity.BatchDelete, Status: entity.BatchSkipped},
+		{Index: 1, Op: entity.BatchDelete, Status: entity.BatchFailed, Error: "record not found"},
+	}
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name: "Applied",
+			body: `{"operations":[{"op":"delete","id":1}]}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Batch(1, entity.BatchRequest{Operations: []entity.BatchOperation{{Op: entity.BatchDelete, ID: 1}}}).
+					Return([]entity.BatchResult{{Index: 0, Op: entity.BatchDelete, Status: entity.BatchOK}}, nil)
+			},
+			expectedStatus:       200,
+			expectedResponseBody: `{"results":[{"index":0,"op":"delete","status":"ok"}]}`,
+		},
+		{
+			name: "Atomic Failed",
+			body: `{"operations":[{"op":"delete","id":1},{"op":"delete","id":2}]}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Batch(1, gomock.Any()).Return(failed, nil)
+			},
+			expectedStatus:       422,
+			expectedResponseBody: `{"results":[{"index":0,"op":"delete","status":"skipped"},{"index":1,"op":"delete","status":"failed","error":"record not found"}]}`,
+		},
+		{
+			name: "Best Effort Partly Failed",
+			body: `{"mode":"best_effort","operations":[{"op":"delete","id":1},{"op":"delete","id":2}]}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Batch(1, gomock.Any()).Return(failed, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:           "Unknown Mode",
+			body:           `{"mode":"sometimes","operations":[{"op":"delete","id":1}]}`,
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.POST("/api/batch", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.runBatch)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(tt.body))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,718 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/exporter"
+	"github.com/AronditFire/todo-app/internal/importer"
+	"github.com/AronditFire/todo-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CalDAV отдаёт задачи пользователя одним календарём VTODO: принципал и
+// домашняя коллекция - /caldav/, календарь - /caldav/tasks/, задача -
+// /caldav/tasks/<name>.ics. Клиенты входят по HTTP Basic с паролем приложения.
+const (
+	davRoot     = "/caldav/"
+	davCalendar = "/caldav/tasks/"
+
+	davContentType    = "text/calendar; charset=utf-8; component=VTODO"
+	maxCalendarObject = 1 << 20
+)
+
+// davTaskList is the calendar collection: every task the user can see,
+// ordered by id, with the names clients gave the tasks they created and the
+// sync token of the read.
+type davTaskList struct {
+	userID  int
+	tasks   []entity.Task
+	objects map[int]entity.CalendarObject
+	token   string
+}
+
+// object is the resource of the task inside the collection.
+func (l davTaskList) object(taskID int) entity.CalendarObject {
+	if object, ok := l.objects[taskID]; ok {
+		return object
+	}
+	return service.DefaultCalendarObject(l.userID, taskID)
+}
+
+// find returns the task of a resource name, nil if there is none.
+func (l davTaskList) find(name string) *entity.Task {
+	for i := range l.tasks {
+		if l.object(l.tasks[i].ID).Name == name {
+			return &l.tasks[i]
+		}
+	}
+	return nil
+}
+
+// The sync token of the collection is the token of GET /api/sync made a URI,
+// as RFC 6578 wants. It is the CTag as well.
+const davSyncTokenPrefix = "data:,"
+
+func davSyncToken(token string) string {
+	return davSyncTokenPrefix + token
+}
+
+func parseDavSyncToken(s string) (entity.SyncToken, error) {
+	token, ok := strings.CutPrefix(s, davSyncTokenPrefix)
+	if !ok {
+		return 0, errors.New("malformed sync token")
+	}
+	return entity.DecodeSyncToken(token)
+}
+
+func (h *Handler) davTasks(userID int) (davTaskList, error) {
+	// полная выборка и токен берутся из одного снимка
+	all, err := h.services.TaskList.Sync(userID, 0)
+	if err != nil {
+		return davTaskList{}, err
+	}
+
+	objects, err := h.services.CalDAV.GetCalendarObjects(userID)
+	if err != nil {
+		return davTaskList{}, err
+	}
+
+	return davTaskList{userID: userID, tasks: all.Tasks, objects: objects, token: all.Token}, nil
+}
+
+// davObject finds the resource of one task without listing the collection,
+// object is nil when no task has the name.
+func (h *Handler) davObject(userID int, name string) (*entity.CalendarObject, entity.Task, error) {
+	object, err := h.services.CalDAV.FindCalendarObject(userID, name)
+	if err != nil || object == nil {
+		return nil, entity.Task{}, err
+	}
+
+	task, err := h.services.TaskList.GetTaskByID(userID, object.TaskID)
+	if err != nil {
+		return nil, entity.Task{}, err
+	}
+
+	return object, task, nil
+}
+
+// appPasswordIdentify signs CalDAV clients in with HTTP Basic: the user name
+// and one of the user's app passwords.
+func (h *Handler) appPasswordIdentify(c *gin.Context) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		davUnauthorized(c)
+		return
+	}
+
+	userID, err := h.services.CalDAV.CheckAppPassword(username, password)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			davUnauthorized(c)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Set(userCtx, userID)
+	c.Next()
+}
+
+func davUnauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Basic realm="todo-app", charset="UTF-8"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// caldavRedirect answers /.well-known/caldav (RFC 6764), clients start there.
+func (h *Handler) caldavRedirect(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, davRoot)
+}
+
+func (h *Handler) propfindPrincipal(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	wanted, err := readPropfind(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	m := newMultistatus()
+	m.response(davRoot, principalProps(), wanted)
+
+	if c.GetHeader("Depth") != "0" {
+		list, err := h.davTasks(userID)
+		if err != nil {
+			c.AbortWithStatus(errorStatus(err))
+			return
+		}
+		m.response(davCalendar, calendarProps(list), wanted)
+	}
+
+	writeMultistatus(c, m)
+}
+
+func (h *Handler) propfindCalendar(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	wanted, err := readPropfind(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.davTasks(userID)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	m := newMultistatus()
+	m.response(davCalendar, calendarProps(list), wanted)
+	if c.GetHeader("Depth") != "0" {
+		for _, task := range list.tasks {
+			if err := m.taskResponse(list.object(task.ID), task, wanted); err != nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	writeMultistatus(c, m)
+}
+
+func (h *Handler) propfindCalendarObject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	wanted, err := readPropfind(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	object, task, err := h.davObject(userID, c.Param("name"))
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	if object == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	m := newMultistatus()
+	if err := m.taskResponse(*object, task, wanted); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	writeMultistatus(c, m)
+}
+
+// reportCalendar answers calendar-query, calendar-multiget and sync-collection.
+func (h *Handler) reportCalendar(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var report davReport
+	if err := xml.NewDecoder(io.LimitReader(c.Request.Body, maxCalendarObject)).Decode(&report); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	wanted := report.Prop.propNames()
+
+	if report.XMLName == (xml.Name{Space: nsDAV, Local: "sync-collection"}) {
+		h.syncCollection(c, userID, report.SyncToken, wanted)
+		return
+	}
+
+	list, err := h.davTasks(userID)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	m := newMultistatus()
+	var tasks []entity.Task
+	switch report.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		for _, task := range list.tasks {
+			if matchCalendarFilter(report.Filter, task, list.object(task.ID).UID) {
+				tasks = append(tasks, task)
+			}
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range report.Hrefs {
+			var task *entity.Task
+			if dir, name := path.Split(href); dir == davCalendar {
+				task = list.find(name)
+			}
+			if task == nil {
+				m.status(href, http.StatusNotFound)
+				continue
+			}
+			tasks = append(tasks, *task)
+		}
+	default:
+		davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+
+	for _, task := range tasks {
+		if err := m.taskResponse(list.object(task.ID), task, wanted); err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeMultistatus(c, m)
+}
+
+// syncCollection answers sync-collection (RFC 6578) from the change log of
+// GET /api/sync: the tasks changed since the token and the names of the ones
+// that are gone. A token the client did not get from here, or one older than
+// the change log keeps, gets 403 valid-sync-token, the client lists the
+// collection again.
+func (h *Handler) syncCollection(c *gin.Context, userID int, token string, wanted []xml.Name) {
+	var since entity.SyncToken
+	if token != "" {
+		var err error
+		if since, err = parseDavSyncToken(token); err != nil {
+			davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+			return
+		}
+	}
+
+	delta, err := h.services.TaskList.Sync(userID, since)
+	if errors.Is(err, entity.ErrSyncTokenExpired) {
+		davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	objects, err := h.services.CalDAV.GetCalendarObjects(userID)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	// имена задач, ушедших в корзину, уже освобождены, их помнит только журнал удалений
+	removed, err := h.services.CalDAV.GetRemovedCalendarObjects(userID, since)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	list := davTaskList{userID: userID, tasks: delta.Tasks, objects: objects, token: delta.Token}
+
+	m := newMultistatus()
+	present := make(map[string]bool, len(list.tasks))
+	for _, task := range list.tasks {
+		object := list.object(task.ID)
+		present[object.Name] = true
+		if err := m.taskResponse(object, task, wanted); err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// имя удалённой задачи мог уже занять новый объект, он есть среди изменённых
+	var gone []string
+	for _, id := range delta.Deleted {
+		gone = append(gone, list.object(id).Name)
+	}
+	for _, object := range removed {
+		gone = append(gone, object.Name)
+	}
+	sort.Strings(gone)
+	for i, name := range gone {
+		if !present[name] && (i == 0 || gone[i-1] != name) {
+			m.status(davCalendar+name, http.StatusNotFound)
+		}
+	}
+
+	m.syncToken(davSyncToken(list.token)) // sync-token идёт после всех response
+	writeMultistatus(c, m)
+}
+
+func (h *Handler) getCalendarObject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	object, task, err := h.davObject(userID, c.Param("name"))
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	if object == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	etag := taskETag(task.Version)
+	c.Header("ETag", etag)
+	if etagListed(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := exporter.WriteCalendarObject(&body, task, object.UID); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(http.StatusOK, exportContentTypes[entity.ExportICS], body.Bytes())
+}
+
+// putCalendarObject creates a task from a VTODO under a new name or replaces
+// the fields of an existing one. Fields iCalendar has no place for (project,
+// parent, tags) are kept. Completing a recurring task goes through
+// PatchAndCompleteTask, so the next occurrence is created like in the app.
+func (h *Handler) putCalendarObject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	name := c.Param("name")
+	if !strings.HasSuffix(name, ".ics") {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	task, uid, err := importer.ParseVTODO(http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarObject))
+	if err != nil || uid == "" {
+		davError(c, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"})
+		return
+	}
+
+	object, existing, err := h.davObject(userID, name)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	if object == nil {
+		h.createCalendarObject(c, userID, name, uid, task)
+		return
+	}
+
+	if c.GetHeader("If-None-Match") == "*" {
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+		return
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	patch := davTaskPatch(task)
+	// повторяющаяся задача закрывается вместе с правкой: PatchTask не создаёт следующее повторение
+	if task.Status == entity.StatusDone && existing.Recurrence != "" && existing.Status != entity.StatusDone {
+		patch.Status = entity.PatchField[entity.TaskStatus]{}
+		_, err = h.services.TaskList.PatchAndCompleteTask(userID, existing.ID, patch, version)
+	} else {
+		err = h.services.TaskList.PatchTask(userID, existing.ID, patch, version)
+	}
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	updated, err := h.services.TaskList.GetTaskByID(userID, existing.ID)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	c.Header("ETag", taskETag(updated.Version))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) createCalendarObject(c *gin.Context, userID int, name, uid string, task entity.Task) {
+	if c.GetHeader("If-Match") != "" {
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+		return
+	}
+	if h.services.CalDAV.CalendarNameReserved(name) {
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	taken, err := h.services.CalDAV.CalendarUIDTaken(userID, uid)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	if taken {
+		davError(c, http.StatusConflict, xml.Name{Space: nsCalDAV, Local: "no-uid-conflict"})
+		return
+	}
+
+	id, err := h.services.TaskList.CreateCalendarTask(userID, task, entity.CalendarObject{Name: name, UID: uid})
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	created, err := h.services.TaskList.GetTaskByID(userID, id)
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	c.Header("ETag", taskETag(created.Version))
+	c.Status(http.StatusCreated)
+}
+
+func (h *Handler) deleteCalendarObject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	object, err := h.services.CalDAV.FindCalendarObject(userID, c.Param("name"))
+	if err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+	if object == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	// задача уходит в корзину, как и при DELETE /api/:id
+	if err := h.services.TaskList.DeleteTask(userID, object.TaskID, version); err != nil {
+		c.AbortWithStatus(errorStatus(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// davTaskPatch replaces every field a VTODO carries, a missing property
+// clears the field or sets the default.
+func davTaskPatch(task entity.Task) entity.TaskPatch {
+	status := task.Status
+	if status == "" {
+		status = entity.StatusTodo
+	}
+	priority := task.Priority
+	if priority == 0 {
+		priority = entity.PriorityLowest
+	}
+
+	return entity.TaskPatch{
+		Description: entity.PatchField[string]{Set: true, Value: &task.Description},
+		Status:      entity.PatchField[entity.TaskStatus]{Set: true, Value: &status},
+		DueAt:       entity.PatchField[time.Time]{Set: true, Value: task.DueAt},
+		RemindAt:    entity.PatchField[time.Time]{Set: true, Value: task.RemindAt},
+		Priority:    entity.PatchField[int]{Set: true, Value: &priority},
+		Recurrence:  entity.PatchField[string]{Set: true, Value: &task.Recurrence},
+	}
+}
+
+func principalProps() davProps {
+	return davProps{
+		propResourceType: "<d:collection/><d:principal/>",
+		propCurrentUser:  hrefElement(davRoot),
+		propPrincipalURL: hrefElement(davRoot),
+		propCalendarHome: hrefElement(davRoot),
+	}
+}
+
+func calendarProps(list davTaskList) davProps {
+	token := davSyncToken(list.token)
+	return davProps{
+		propResourceType:       "<d:collection/><cal:calendar/>",
+		propDisplayName:        "Tasks",
+		propSupportedComponent: `<cal:comp name="VTODO"/>`,
+		propSyncToken:          escapeXML(token),
+		propCTag:               escapeXML(token),
+		propCurrentUser:        hrefElement(davRoot),
+		propOwner:              hrefElement(davRoot),
+		propPrivileges: "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>" +
+			"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege><d:privilege><d:unbind/></d:privilege>",
+		propSupportedReports: "<d:supported-report><d:report><cal:calendar-query/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><cal:calendar-multiget/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>",
+	}
+}
+
+// taskResponse reports a task resource. The calendar data is rendered only
+// when it is asked for.
+func (m *multistatus) taskResponse(object entity.CalendarObject, task entity.Task, wanted []xml.Name) error {
+	props := davProps{
+		propResourceType:   "",
+		propGetETag:        escapeXML(taskETag(task.Version)),
+		propGetContentType: davContentType,
+	}
+	for _, name := range wanted {
+		if name != propCalendarData {
+			continue
+		}
+		var body bytes.Buffer
+		if err := exporter.WriteCalendarObject(&body, task, object.UID); err != nil {
+			return err
+		}
+		props[propCalendarData] = escapeXML(body.String())
+	}
+
+	m.response(davCalendar+object.Name, props, wanted)
+	return nil
+}
+
+// matchCalendarFilter applies the filter of a calendar-query: the VTODO
+// comp-filter with its time-range and prop-filters (is-not-defined and
+// text-match). Properties the filter names but a task has no field for are
+// never defined.
+func matchCalendarFilter(filter *calCompFilter, task entity.Task, uid string) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Name != "VCALENDAR" {
+		return false
+	}
+
+	for _, comp := range filter.Comps {
+		if comp.Name != "VTODO" {
+			if comp.NotDefined == nil {
+				return false // в календаре только VTODO
+			}
+			continue
+		}
+		if comp.NotDefined != nil {
+			return false
+		}
+		if comp.TimeRange != nil && !inTimeRange(comp.TimeRange, task.DueAt) {
+			return false
+		}
+		for _, prop := range comp.Props {
+			if !matchPropFilter(prop, task, uid) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchPropFilter(filter calPropFilter, task entity.Task, uid string) bool {
+	var (
+		value   string
+		defined bool
+	)
+	switch strings.ToUpper(filter.Name) {
+	case "UID":
+		value, defined = uid, true
+	case "SUMMARY":
+		value, defined = task.Description, true
+	case "STATUS":
+		value, defined = davStatuses[task.Status], true
+	case "PRIORITY":
+		value, defined = strconv.Itoa(task.Priority), true
+	case "DUE":
+		defined = task.DueAt != nil
+	case "COMPLETED":
+		defined = task.CompletedAt != nil
+	case "RRULE":
+		value, defined = task.Recurrence, task.Recurrence != ""
+	}
+
+	if filter.NotDefined != nil {
+		return !defined
+	}
+	if filter.TextMatch == nil || !defined {
+		return defined
+	}
+
+	match := strings.Contains(strings.ToLower(value), strings.ToLower(strings.TrimSpace(filter.TextMatch.Value)))
+	return match != (filter.TextMatch.Negate == "yes")
+}
+
+// davStatuses are the VTODO STATUS values text-match compares with.
+var davStatuses = map[entity.TaskStatus]string{
+	entity.StatusTodo:       "NEEDS-ACTION",
+	entity.StatusInProgress: "IN-PROCESS",
+	entity.StatusDone:       "COMPLETED",
+	entity.StatusCancelled:  "CANCELLED",
+}
+
+// inTimeRange: a VTODO without DUE overlaps any range (RFC 4791 9.9).
+func inTimeRange(r *calTimeRange, due *time.Time) bool {
+	if due == nil {
+		return true
+	}
+	if start, err := time.Parse("20060102T150405Z", r.Start); err == nil && due.Before(start) {
+		return false
+	}
+	if end, err := time.Parse("20060102T150405Z", r.End); err == nil && !due.Before(end) {
+		return false
+	}
+	return true
+}
+
+// readPropfind returns the wanted properties, nil for allprop or an empty body.
+func readPropfind(c *gin.Context) ([]xml.Name, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCalendarObject))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var req davPropfind
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return req.Prop.propNames(), nil
+}
+
+func writeMultistatus(c *gin.Context, m *multistatus) {
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", m.bytes())
+}
+
+// davError answers with a failed precondition, like <d:valid-sync-token/>.
+func davError(c *gin.Context, code int, precondition xml.Name) {
+	body := xml.Header + `<d:error xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">` + element(precondition, "") + "</d:error>"
+	c.Data(code, "application/xml; charset=utf-8", []byte(body))
+	c.Abort()
+}
@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testVTODO = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:abc-123\r\nSUMMARY:Buy milk\r\nSTATUS:COMPLETED\r\nPRIORITY:1\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+func newCalDAVRouter(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) *gin.Engine {
+	handler := NewHander(&service.Service{TaskList: tasks, CalDAV: caldav})
+
+	r := gin.New()
+	auth := func(c *gin.Context) { c.Set(userCtx, 1) }
+	r.Handle("PROPFIND", "/caldav/tasks/", auth, handler.propfindCalendar)
+	r.Handle("REPORT", "/caldav/tasks/", auth, handler.reportCalendar)
+	r.GET("/caldav/tasks/:name", auth, handler.getCalendarObject)
+	r.PUT("/caldav/tasks/:name", auth, handler.putCalendarObject)
+	r.DELETE("/caldav/tasks/:name", auth, handler.deleteCalendarObject)
+	return r
+}
+
+func TestHandler_appPasswordIdentify(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name           string
+		setAuth        bool
+		mock           func(s *mock_service.MockCalDAV)
+		expectedStatus int
+	}{
+		{
+			name:    "OK",
+			setAuth: true,
+			mock: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().CheckAppPassword("alice", "secret").Return(7, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:    "Wrong Password",
+			setAuth: true,
+			mock: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().CheckAppPassword("alice", "secret").Return(0, entity.ErrUnauthorized)
+			},
+			expectedStatus: 401,
+		},
+		{
+			name:           "No Credentials",
+			mock:           func(*mock_service.MockCalDAV) {},
+			expectedStatus: 401,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			caldav := mock_service.NewMockCalDAV(c)
+			tt.mock(caldav)
+
+			handler := NewHander(&service.Service{CalDAV: caldav})
+
+			r := gin.New()
+			r.GET("/caldav/", handler.appPasswordIdentify, func(c *gin.Context) {
+				id, _ := getUserId(c)
+				assert.Equal(t, 7, id)
+			})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/caldav/", nil)
+			if tt.setAuth {
+				req.SetBasicAuth("alice", "secret")
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == 401 {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+			}
+		})
+	}
+}
+
+func TestHandler_propfindCalendar(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	tasks := mock_service.NewMockTaskList(c)
+	caldav := mock_service.NewMockCalDAV(c)
+	tasks.EXPECT().Sync(1, entity.SyncToken(0)).Return(entity.SyncResponse{
+		Tasks: []entity.Task{
+			{ID: 3, Description: "a", Version: 1},
+			{ID: 5, Description: "b", Version: 2},
+		},
+		Token: "tok",
+	}, nil)
+	caldav.EXPECT().GetCalendarObjects(1).Return(map[int]entity.CalendarObject{
+		5: {TaskID: 5, UserID: 1, Name: "abc.ics", UID: "abc"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PROPFIND", "/caldav/tasks/", strings.NewReader(
+		`<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/><d:displayname/><d:sync-token/></d:prop></d:propfind>`))
+	req.Header.Set("Depth", "1")
+
+	newCalDAVRouter(tasks, caldav).ServeHTTP(w, req)
+
+	assert.Equal(t, 207, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<d:displayname>Tasks</d:displayname>")
+	assert.Contains(t, body, "<d:href>/caldav/tasks/task-3.ics</d:href>")
+	assert.Contains(t, body, "<d:href>/caldav/tasks/abc.ics</d:href>")
+	assert.Contains(t, body, "<d:getetag>&#34;2&#34;</d:getetag>")
+	assert.Contains(t, body, "<d:sync-token>data:,tok</d:sync-token>")
+	assert.Less(t, strings.Index(body, "task-3.ics"), strings.Index(body, "abc.ics"))
+}
+
+func TestHandler_reportCalendar(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	due := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	list := []entity.Task{
+		{ID: 1, Description: "Buy milk", Status: entity.StatusTodo, DueAt: &due, Version: 1},
+		{ID: 2, Description: "Call mom", Status: entity.StatusDone, CompletedAt: &due, Version: 3},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		contains       []string
+		notContains    []string
+	}{
+		{
+			name: "Query Open Tasks",
+			body: `<cal:calendar-query xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/></d:prop>` +
+				`<cal:filter><cal:comp-filter name="VCALENDAR"><cal:comp-filter name="VTODO">` +
+				`<cal:prop-filter name="COMPLETED"><cal:is-not-defined/></cal:prop-filter>` +
+				`</cal:comp-filter></cal:comp-filter></cal:filter></cal:calendar-query>`,
+			expectedStatus: 207,
+			contains:       []string{"task-1.ics"},
+			notContains:    []string{"task-2.ics"},
+		},
+		{
+			name: "Query Time Range",
+			body: `<cal:calendar-query xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/></d:prop>` +
+				`<cal:filter><cal:comp-filter name="VCALENDAR"><cal:comp-filter name="VTODO">` +
+				`<cal:time-range start="20250601T000000Z" end="20250701T000000Z"/>` +
+				`</cal:comp-filter></cal:comp-filter></cal:filter></cal:calendar-query>`,
+			expectedStatus: 207,
+			contains:       []string{"task-2.ics"},
+			notContains:    []string{"task-1.ics"},
+		},
+		{
+			name: "Multiget",
+			body: `<cal:calendar-multiget xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><cal:calendar-data/></d:prop>` +
+				`<d:href>/caldav/tasks/task-1.ics</d:href><d:href>/caldav/tasks/gone.ics</d:href></cal:calendar-multiget>`,
+			expectedStatus: 207,
+			contains:       []string{"SUMMARY:Buy milk", "<d:href>/caldav/tasks/gone.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			caldav := mock_service.NewMockCalDAV(c)
+			tasks.EXPECT().Sync(1, entity.SyncToken(0)).Return(entity.SyncResponse{Tasks: append([]entity.Task(nil), list...)}, nil)
+			caldav.EXPECT().GetCalendarObjects(1).Return(map[int]entity.CalendarObject{}, nil)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("REPORT", "/caldav/tasks/", strings.NewReader(tt.body))
+
+			newCalDAVRouter(tasks, caldav).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, s := range tt.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+			for _, s := range tt.notContains {
+				assert.NotContains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestHandler_syncCollection(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	since := entity.SyncToken(40)
+	report := func(token string) string {
+		return `<d:sync-collection xmlns:d="DAV:"><d:sync-token>` + token + `</d:sync-token><d:prop><d:getetag/></d:prop></d:sync-collection>`
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mock           func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV)
+		expectedStatus int
+		contains       []string
+		notContains    []string
+	}{
+		{
+			name: "Initial Sync",
+			body: report(""),
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				tasks.EXPECT().Sync(1, entity.SyncToken(0)).Return(entity.SyncResponse{
+					Tasks: []entity.Task{{ID: 1, Version: 1}, {ID: 2, Version: 3}},
+					Token: "next",
+				}, nil)
+				caldav.EXPECT().GetCalendarObjects(1).Return(map[int]entity.CalendarObject{}, nil)
+				caldav.EXPECT().GetRemovedCalendarObjects(1, entity.SyncToken(0)).Return(nil, nil)
+			},
+			expectedStatus: 207,
+			contains:       []string{"task-1.ics", "task-2.ics", "<d:sync-token>data:,next</d:sync-token>"},
+		},
+		{
+			name: "Changes Since Token",
+			body: report(davSyncToken(since.Encode())),
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				tasks.EXPECT().Sync(1, since).Return(entity.SyncResponse{
+					Tasks:   []entity.Task{{ID: 2, Version: 4}, {ID: 9, Version: 1}},
+					Deleted: []int{3, 7},
+					Token:   "next",
+				}, nil)
+				caldav.EXPECT().GetCalendarObjects(1).Return(map[int]entity.CalendarObject{
+					9: {TaskID: 9, UserID: 1, Name: "abc.ics", UID: "abc"},
+				}, nil)
+				// abc.ics удалили и создали заново, milk.ics ушла в корзину
+				caldav.EXPECT().GetRemovedCalendarObjects(1, since).Return([]entity.CalendarObject{
+					{TaskID: 5, UserID: 1, Name: "abc.ics"},
+					{TaskID: 7, UserID: 1, Name: "milk.ics"},
+				}, nil)
+			},
+			expectedStatus: 207,
+			contains: []string{
+				"<d:href>/caldav/tasks/task-2.ics</d:href><d:propstat>",
+				"<d:href>/caldav/tasks/abc.ics</d:href><d:propstat>",
+				"<d:href>/caldav/tasks/task-3.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>",
+				"<d:href>/caldav/tasks/milk.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>",
+				"<d:sync-token>data:,next</d:sync-token>",
+			},
+			notContains: []string{"task-1.ics", "<d:href>/caldav/tasks/abc.ics</d:href><d:status>"},
+		},
+		{
+			name:           "Foreign Sync Token",
+			body:           report("data:,old"),
+			mock:           func(*mock_service.MockTaskList, *mock_service.MockCalDAV) {},
+			expectedStatus: 403,
+			contains:       []string{"<d:valid-sync-token/>"},
+		},
+		{
+			name: "Expired Sync Token",
+			body: report(davSyncToken(since.Encode())),
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				tasks.EXPECT().Sync(1, since).Return(entity.SyncResponse{}, entity.ErrSyncTokenExpired)
+			},
+			expectedStatus: 403,
+			contains:       []string{"<d:valid-sync-token/>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			caldav := mock_service.NewMockCalDAV(c)
+			tt.mock(tasks, caldav)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("REPORT", "/caldav/tasks/", strings.NewReader(tt.body))
+
+			newCalDAVRouter(tasks, caldav).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, s := range tt.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+			for _, s := range tt.notContains {
+				assert.NotContains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestHandler_getCalendarObject(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	tasks := mock_service.NewMockTaskList(c)
+	caldav := mock_service.NewMockCalDAV(c)
+	caldav.EXPECT().FindCalendarObject(1, "abc.ics").Return(&entity.CalendarObject{TaskID: 9, UserID: 1, Name: "abc.ics", UID: "abc"}, nil)
+	tasks.EXPECT().GetTaskByID(1, 9).Return(entity.Task{ID: 9, Description: "Buy milk", Version: 3}, nil)
+	caldav.EXPECT().FindCalendarObject(1, "task-9.ics").Return(nil, nil)
+
+	r := newCalDAVRouter(tasks, caldav)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/caldav/tasks/abc.ics", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), "UID:abc\r\n")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/caldav/tasks/task-9.ics", nil))
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestHandler_putCalendarObject(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		headers        map[string]string
+		mock           func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name: "Create",
+			path: "/caldav/tasks/abc-123.ics",
+			body: testVTODO,
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				caldav.EXPECT().FindCalendarObject(1, "abc-123.ics").Return(nil, nil)
+				caldav.EXPECT().CalendarNameReserved("abc-123.ics").Return(false)
+				caldav.EXPECT().CalendarUIDTaken(1, "abc-123").Return(false, nil)
+				tasks.EXPECT().CreateCalendarTask(1, gomock.Any(), entity.CalendarObject{Name: "abc-123.ics", UID: "abc-123"}).
+					DoAndReturn(func(_ int, task entity.Task, _ entity.CalendarObject) (int, error) {
+						assert.Equal(t, "Buy milk", task.Description)
+						assert.Equal(t, entity.StatusDone, task.Status)
+						return 9, nil
+					})
+				tasks.EXPECT().GetTaskByID(1, 9).Return(entity.Task{ID: 9, Version: 1}, nil)
+			},
+			expectedStatus: 201,
+			expectedETag:   `"1"`,
+		},
+		{
+			name: "UID Taken",
+			path: "/caldav/tasks/other.ics",
+			body: testVTODO,
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				caldav.EXPECT().FindCalendarObject(1, "other.ics").Return(nil, nil)
+				caldav.EXPECT().CalendarNameReserved("other.ics").Return(false)
+				caldav.EXPECT().CalendarUIDTaken(1, "abc-123").Return(true, nil)
+			},
+			expectedStatus: 409,
+		},
+		{
+			// задачи 9 не видно, но её имя по умолчанию клиенту не отдаётся
+			name: "Default Name Reserved",
+			path: "/caldav/tasks/task-9.ics",
+			body: testVTODO,
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				caldav.EXPECT().FindCalendarObject(1, "task-9.ics").Return(nil, nil)
+				caldav.EXPECT().CalendarNameReserved("task-9.ics").Return(true)
+			},
+			expectedStatus: 409,
+		},
+		{
+			name: "Complete Recurring",
+			path: "/caldav/tasks/task-4.ics",
+			body: testVTODO,
+			headers: map[string]string{
+				"If-Match": `"2"`,
+			},
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				object := service.DefaultCalendarObject(1, 4)
+				caldav.EXPECT().FindCalendarObject(1, "task-4.ics").Return(&object, nil)
+				tasks.EXPECT().GetTaskByID(1, 4).Return(entity.Task{ID: 4, Status: entity.StatusTodo, Recurrence: "FREQ=DAILY", Version: 2}, nil)
+				tasks.EXPECT().PatchAndCompleteTask(1, 4, gomock.Any(), 2).DoAndReturn(func(_, _ int, patch entity.TaskPatch, _ int) (int, error) {
+					assert.False(t, patch.Status.Set)
+					assert.Equal(t, "Buy milk", *patch.Description.Value)
+					assert.Equal(t, 1, *patch.Priority.Value)
+					return 10, nil
+				})
+				tasks.EXPECT().GetTaskByID(1, 4).Return(entity.Task{ID: 4, Version: 4}, nil)
+			},
+			expectedStatus: 204,
+			expectedETag:   `"4"`,
+		},
+		{
+			name: "Version Mismatch",
+			path: "/caldav/tasks/task-4.ics",
+			body: testVTODO,
+			headers: map[string]string{
+				"If-Match": `"1"`,
+			},
+			mock: func(tasks *mock_service.MockTaskList, caldav *mock_service.MockCalDAV) {
+				object := service.DefaultCalendarObject(1, 4)
+				caldav.EXPECT().FindCalendarObject(1, "task-4.ics").Return(&object, nil)
+				tasks.EXPECT().GetTaskByID(1, 4).Return(entity.Task{ID: 4, Version: 2}, nil)
+				tasks.EXPECT().PatchTask(1, 4, gomock.Any(), 1).Return(entity.ErrVersionMismatch)
+			},
+			expectedStatus: 412,
+		},
+		{
+			name:           "Invalid Calendar Data",
+			path:           "/caldav/tasks/x.ics",
+			body:           "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+			mock:           func(*mock_service.MockTaskList, *mock_service.MockCalDAV) {},
+			expectedStatus: 403,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			caldav := mock_service.NewMockCalDAV(c)
+			tt.mock(tasks, caldav)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			newCalDAVRouter(tasks, caldav).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_deleteCalendarObject(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	tasks := mock_service.NewMockTaskList(c)
+	caldav := mock_service.NewMockCalDAV(c)
+	object := service.DefaultCalendarObject(1, 4)
+	caldav.EXPECT().FindCalendarObject(1, "task-4.ics").Return(&object, nil)
+	caldav.EXPECT().FindCalendarObject(1, "missing.ics").Return(nil, nil)
+	tasks.EXPECT().DeleteTask(1, 4, 2).Return(nil)
+
+	r := newCalDAVRouter(tasks, caldav)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/caldav/tasks/task-4.ics", nil)
+	req.Header.Set("If-Match", `"2"`)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 204, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/caldav/tasks/missing.ics", nil))
+	assert.Equal(t, 404, w.Code)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// XML namespaces of WebDAV, CalDAV and the CalendarServer extensions (getctag).
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+var davPrefixes = map[string]string{nsDAV: "d", nsCalDAV: "cal", nsCS: "cs"}
+
+var (
+	propResourceType       = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName        = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag            = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType     = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propSyncToken          = xml.Name{Space: nsDAV, Local: "sync-token"}
+	propCurrentUser        = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL       = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner              = xml.Name{Space: nsDAV, Local: "owner"}
+	propPrivileges         = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReports   = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propCalendarHome       = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarData       = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propSupportedComponent = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCTag               = xml.Name{Space: nsCS, Local: "getctag"}
+)
+
+// davAny is any element, only its name is kept.
+type davAny struct {
+	XMLName xml.Name
+}
+
+// davPropNames is a <d:prop> of a request: the names of wanted properties.
+type davPropNames struct {
+	Names []davAny `xml:",any"`
+}
+
+type davPropfind struct {
+	XMLName xml.Name      `xml:"DAV: propfind"`
+	Prop    *davPropNames `xml:"DAV: prop"`
+}
+
+// davReport is the body of REPORT: calendar-query, calendar-multiget or
+// sync-collection, told apart by XMLName.
+type davReport struct {
+	XMLName   xml.Name
+	Prop      *davPropNames  `xml:"DAV: prop"`
+	Hrefs     []string       `xml:"DAV: href"`
+	SyncToken string         `xml:"DAV: sync-token"`
+	Filter    *calCompFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+type calCompFilter struct {
+	Name       string          `xml:"name,attr"`
+	NotDefined *struct{}       `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange  *calTimeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Comps      []calCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props      []calPropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+type calPropFilter struct {
+	Name       string        `xml:"name,attr"`
+	NotDefined *struct{}     `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch  *calTextMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type calTextMatch struct {
+	Value  string `xml:",chardata"`
+	Negate string `xml:"negate-condition,attr"` // yes или no
+}
+
+type calTimeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// propNames returns the requested names, nil for allprop or an empty body.
+func (p *davPropNames) propNames() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, 0, len(p.Names))
+	for _, n := range p.Names {
+		names = append(names, n.XMLName)
+	}
+	return names
+}
+
+// davProps are the values of the properties of one resource: name -> inner XML.
+type davProps map[xml.Name]string
+
+// multistatus builds a 207 Multi-Status body.
+type multistatus struct {
+	b strings.Builder
+}
+
+func newMultistatus() *multistatus {
+	m := &multistatus{}
+	m.b.WriteString(xml.Header)
+	m.b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	return m
+}
+
+// response reports the properties of href: wanted ones the resource has are
+// in a 200 propstat, the others in a 404 one. Without wanted names all
+// properties are reported.
+func (m *multistatus) response(href string, props davProps, wanted []xml.Name) {
+	if wanted == nil {
+		for name := range props {
+			wanted = append(wanted, name)
+		}
+		sort.Slice(wanted, func(i, j int) bool {
+			return wanted[i].Space+" "+wanted[i].Local < wanted[j].Space+" "+wanted[j].Local
+		})
+	}
+
+	var found, missing strings.Builder
+	for _, name := range wanted {
+		value, ok := props[name]
+		if !ok {
+			missing.WriteString(element(name, ""))
+			continue
+		}
+		found.WriteString(element(name, value))
+	}
+
+	m.b.WriteString("<d:response><d:href>" + escapeXML(href) + "</d:href>")
+	if found.Len() > 0 {
+		m.b.WriteString("<d:propstat><d:prop>" + found.String() + "</d:prop>" + davStatus(http.StatusOK) + "</d:propstat>")
+	}
+	if missing.Len() > 0 {
+		m.b.WriteString("<d:propstat><d:prop>" + missing.String() + "</d:prop>" + davStatus(http.StatusNotFound) + "</d:propstat>")
+	}
+	m.b.WriteString("</d:response>")
+}
+
+// status reports href with a status instead of properties, like 404 of a
+// multiget or a member removed since the sync token.
+func (m *multistatus) status(href string, code int) {
+	m.b.WriteString("<d:response><d:href>" + escapeXML(href) + "</d:href>" + davStatus(code) + "</d:response>")
+}
+
+func (m *multistatus) syncToken(token string) {
+	m.b.WriteString("<d:sync-token>" + escapeXML(token) + "</d:sync-token>")
+}
+
+func (m *multistatus) bytes() []byte {
+	m.b.WriteString("</d:multistatus>")
+	return []byte(m.b.String())
+}
+
+func davStatus(code int) string {
+	return fmt.Sprintf("<d:status>HTTP/1.1 %d %s</d:status>", code, http.StatusText(code))
+}
+
+// element writes <prefix:name>inner</prefix:name>, a namespace we have no
+// prefix for is declared on the element itself.
+func element(name xml.Name, inner string) string {
+	tag, decl := name.Local, ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, decl = "x:"+name.Local, ` xmlns:x="`+escapeXML(name.Space)+`"`
+	}
+
+	if inner == "" {
+		return "<" + tag + decl + "/>"
+	}
+	return "<" + tag + decl + ">" + inner + "</" + tag + ">"
+}
+
+func hrefElement(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
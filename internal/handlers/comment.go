@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetCommentsResponse struct {
+	Data       []entity.Comment `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"` // пусто на последней странице
+}
+
+// @Summary Get task comments
+// @Security ApiKeyAuth
+// @Tags comments
+// @Description one page of the task's thread, oldest first; pass next_cursor of the response as ?cursor to get the next one
+// @ID get-task-comments
+// @Produce  json
+// @Param id path int true "task id"
+// @Param limit query int false "page size, 50 by default, 200 at most"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} GetCommentsResponse
+// @Failure 400,404 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/comments [get]
+func (h *Handler) getComments(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	limit := defaultPageSize
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 || limit > 200 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid limit",
+			})
+			return
+		}
+	}
+
+	var after *entity.CommentCursor
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := entity.DecodeCommentCursor(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid cursor",
+			})
+			return
+		}
+		after = &cursor
+	}
+
+	page, err := h.services.Comments.GetCommentPage(userID, id, after, limit)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task comments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetCommentsResponse{
+		Data:       page.Comments,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// @Summary Comment task
+// @Security ApiKeyAuth
+// @Tags comments
+// @Description viewers can read the thread but not write to it
+// @ID create-task-comment
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.CommentRequest true "comment text"
+// @Success 201 {integer} integer 1
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/comments [post]
+func (h *Handler) createComment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.CommentRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating comment",
+		})
+		return
+	}
+
+	commentID, err := h.services.Comments.CreateComment(userID, id, req.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create comment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      commentID,
+	})
+}
+
+// @Summary Edit comment
+// @Security ApiKeyAuth
+// @Tags comments
+// @Description only the author can edit a comment
+// @ID update-task-comment
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param comment_id path int true "comment id"
+// @Param input body entity.CommentRequest true "new comment text"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/comments/{comment_id} [put]
+func (h *Handler) updateComment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid comment id",
+		})
+		return
+	}
+
+	var req entity.CommentRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating comment",
+		})
+		return
+	}
+
+	if err := h.services.Comments.UpdateComment(userID, id, commentID, req.Body); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update comment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
+
+// @Summary Delete comment
+// @Security ApiKeyAuth
+// @Tags comments
+// @Description the author can delete their comment, the owner of the task can delete any comment
+// @ID delete-task-comment
+// @Produce  json
+// @Param id path int true "task id"
+// @Param comment_id path int true "comment id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/comments/{comment_id} [delete]
+func (h *Handler) deleteComment(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid comment id",
+		})
+		return
+	}
+
+	if err := h.services.Comments.DeleteComment(userID, id, commentID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not delete comment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
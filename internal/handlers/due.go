@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// getLocation reads IANA timezone from ?tz=, UTC by default.
+func getLocation(c *gin.Context) (*time.Location, error) {
+	tz := c.Query("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// isDueError reports whether err is a problem with the due date or reminder
+// in the request, the rest are not the client's fault.
+func isDueError(err error) bool {
+	for _, dueErr := range []error{entity.ErrInvalidDue, entity.ErrReminderNoDue, entity.ErrReminderAfterDue, entity.ErrRecurrenceNoDue} {
+		if errors.Is(err, dueErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// @Summary Set task due date
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description set or clear (null) due date and reminder, times are RFC 3339 with offset
+// @ID update-task-due
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskDueRequest true "due date and reminder"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/due [put]
+func (h *Handler) updateTaskDue(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskDueRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating due date",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.UpdateTaskDue(userID, id, req); err != nil {
+		if isDueError(err) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task due date in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "due date updated",
+	})
+}
+
+// @Summary Get overdue tasks
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description open tasks whose due date has passed
+// @ID get-overdue-tasks
+// @Produce  json
+// @Success 200 {object} GetAllTaskResponse
+// @Failure 500 {string} string "error"
+// @Router /api/overdue [get]
+func (h *Handler) getOverdueTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tasks, err := h.services.TaskList.GetOverdueTasks(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get overdue tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTaskResponse{
+		Data: tasks,
+	})
+}
+
+// @Summary Get tasks due today
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description tasks due between midnight and midnight in the given timezone
+// @ID get-tasks-due-today
+// @Produce  json
+// @Param tz query string false "IANA timezone, UTC by default" example(Europe/Moscow)
+// @Success 200 {object} GetAllTaskResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/due/today [get]
+func (h *Handler) getTasksDueToday(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	loc, err := getLocation(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid timezone",
+		})
+		return
+	}
+
+	tasks, err := h.services.TaskList.GetTasksDueToday(userID, loc)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get tasks due today",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTaskResponse{
+		Data: tasks,
+	})
+}
+
+// @Summary Get tasks due this week
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description tasks due from Monday to Sunday of the current week in the given timezone
+// @ID get-tasks-due-week
+// @Produce  json
+// @Param tz query string false "IANA timezone, UTC by default" example(Europe/Moscow)
+// @Success 200 {object} GetAllTaskResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/due/week [get]
+func (h *Handler) getTasksDueThisWeek(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	loc, err := getLocation(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid timezone",
+		})
+		return
+	}
+
+	tasks, err := h.services.TaskList.GetTasksDueThisWeek(userID, loc)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get tasks due this week",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTaskResponse{
+		Data: tasks,
+	})
+}
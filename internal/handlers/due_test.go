@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_updateTaskDue(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name                 string
+		serviceErr           error
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			expectedStatus:       200,
+			expectedResponseBody: `{"message":"due date updated"}`,
+		},
+		{
+			name:                 "Reminder After Due",
+			serviceErr:           entity.ErrReminderAfterDue,
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"Reminder must not be later than due date"}`,
+		},
+		{
+			name:                 "Forbidden",
+			serviceErr:           fmt.Errorf("failed to update task due date in repository: %w", entity.ErrForbidden),
+			expectedStatus:       403,
+			expectedResponseBody: `{"error":"Could not update task due date in database"}`,
+		},
+		{
+			name:                 "DB Error",
+			serviceErr:           errors.New("pq: connection refused"),
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not update task due date in database"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tasks.EXPECT().UpdateTaskDue(1, 5, gomock.Any()).Return(tt.serviceErr)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.PUT("/api/:id/due", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.updateTaskDue)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/5/due", bytes.NewBufferString(`{"due_at":"2025-05-06T18:00:00+03:00","remind_at":"2025-05-07T18:00:00+03:00"}`))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskETag is the entity tag of a task: its version in quotes. The version
+// changes with the row and with everything the task is served with: tags,
+// comment_count and the progress of its subtasks, see MigrateVersion.
+func taskETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion reads the task version the client expects from If-Match.
+// 0 means no precondition: the header is missing or "*".
+func ifMatchVersion(c *gin.Context) (int, error) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+
+	if strings.Contains(raw, ",") {
+		return 0, errors.New("only one entity tag is supported in If-Match")
+	}
+	if strings.HasPrefix(raw, "W/") {
+		return 0, errors.New("weak entity tags can not be used in If-Match")
+	}
+
+	unquoted, err := strconv.Unquote(raw)
+	if err != nil || !strings.HasPrefix(raw, `"`) {
+		return 0, errors.New("invalid If-Match")
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, errors.New("invalid If-Match")
+	}
+
+	return version, nil
+}
+
+// etagListed reports whether If-None-Match names the etag, weak tags match too.
+func etagListed(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
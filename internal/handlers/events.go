@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// eventsWriteTimeout bounds one write to an event stream, the server
+	// WriteTimeout would end the whole stream.
+	eventsWriteTimeout = 10 * time.Second
+	// eventsRetry is how long a client waits before it reconnects, in ms.
+	eventsRetry = 3000
+)
+
+// @Summary Stream task events
+// @Security ApiKeyAuth
+// @Tags sync
+// @Description Server-Sent Events stream of your tasks: task.created and task.updated events carry {"id", "task"}, task.deleted carries {"id"} of a task deleted, moved to the trash or no longer shared. The id of an event is a sync token: send it back as Last-Event-ID to resume a dropped stream, or as since to GET /api/sync. Within a stream a version of a task comes once, an event may come again after a reconnect. A ": ping" comment comes when nothing changed for a while. The stream needs the Authorization header, so browsers have to read it with fetch instead of EventSource
+// @ID stream-events
+// @Produce  text/event-stream
+// @Param Last-Event-ID header string false "id of the last event received"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {string} string "error"
+// @Failure 410 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/events [get]
+func (h *Handler) streamEvents(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var since entity.SyncToken
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		if since, err = entity.DecodeSyncToken(raw); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid Last-Event-ID",
+			})
+			return
+		}
+	}
+
+	// поток обрывается и при остановке сервера, иначе Shutdown ждал бы его вечно
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	stop := context.AfterFunc(h.streams, cancel)
+	defer stop()
+
+	rc := http.NewResponseController(c.Writer)
+	send := func(events []entity.TaskEvent, token entity.SyncToken) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(eventsWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		if !c.Writer.Written() {
+			header := c.Writer.Header()
+			header.Set("Content-Type", "text/event-stream")
+			header.Set("Cache-Control", "no-cache")
+			header.Set("X-Accel-Buffering", "no") // nginx не должен копить поток
+			c.Status(http.StatusOK)
+			fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetry)
+		}
+
+		if err := writeEvents(c.Writer, events, token); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+
+		return nil
+	}
+
+	if err := h.services.Events.StreamTasks(ctx, userID, since, send); err != nil {
+		if c.Writer.Written() {
+			// статус уже отправлен, обрываем соединение: клиент переподключится
+			// с последним id, а не примет закрытый поток за штатный конец
+			log.Printf("Could not stream events of user %d: %v", userID, err)
+			panic(http.ErrAbortHandler)
+		}
+
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not stream events",
+		})
+	}
+}
+
+// writeEvents writes a batch of events, only the last one carries the token:
+// a client that resumes from an id in the middle of a batch would skip the
+// rest of it. An empty batch is a ping.
+func writeEvents(w gin.ResponseWriter, events []entity.TaskEvent, token entity.SyncToken) error {
+	if len(events) == 0 {
+		_, err := w.WriteString(": ping\n\n")
+		return err
+	}
+
+	for i, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if i == len(events)-1 {
+			if _, err := fmt.Fprintf(w, "id: %s\n", token.Encode()); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
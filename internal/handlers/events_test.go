@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_streamEvents(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	created := entity.TaskEvent{Type: entity.EventTaskCreated, TaskID: 3, Task: &entity.Task{ID: 3, Description: "Gym"}}
+	createdJSON, _ := json.Marshal(created)
+	token := entity.SyncToken(100).Encode()
+
+	tests := []struct {
+		name                 string
+		lastEventID          string
+		mock                 func(s *mock_service.MockEvents)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Events",
+			lastEventID: entity.SyncToken(90).Encode(),
+			mock: func(s *mock_service.MockEvents) {
+				s.EXPECT().StreamTasks(gomock.Any(), 1, entity.SyncToken(90), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, _ entity.SyncToken, send func([]entity.TaskEvent, entity.SyncToken) error) error {
+						if err := send(nil, 95); err != nil {
+							return err
+						}
+						return send([]entity.TaskEvent{{Type: entity.EventTaskDeleted, TaskID: 2}, created}, 100)
+					})
+			},
+			expectedStatus: 200,
+			expectedResponseBody: "retry: 3000\n\n" +
+				": ping\n\n" +
+				"event: task.deleted\ndata: {\"id\":2}\n\n" +
+				"id: " + token + "\nevent: task.created\ndata: " + string(createdJSON) + "\n\n",
+		},
+		{
+			name: "From Now",
+			mock: func(s *mock_service.MockEvents) {
+				s.EXPECT().StreamTasks(gomock.Any(), 1, entity.SyncToken(0), gomock.Any()).Return(nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:                 "Malformed Last-Event-ID",
+			lastEventID:          "garbage",
+			mock:                 func(*mock_service.MockEvents) {},
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"invalid Last-Event-ID"}`,
+		},
+		{
+			name: "Service Error",
+			mock: func(s *mock_service.MockEvents) {
+				s.EXPECT().StreamTasks(gomock.Any(), 1, entity.SyncToken(0), gomock.Any()).Return(errors.New("redis error"))
+			},
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not stream events"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			events := mock_service.NewMockEvents(c)
+			tt.mock(events)
+
+			handler := NewHander(&service.Service{Events: events})
+
+			r := gin.New()
+			r.GET("/api/events", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.streamEvents)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			if tt.expectedStatus == 200 && tt.expectedResponseBody != "" {
+				assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// An error after the stream has started drops the connection.
+func TestHandler_streamEvents_FailedAfterStart(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	events := mock_service.NewMockEvents(c)
+	events.EXPECT().StreamTasks(gomock.Any(), 1, entity.SyncToken(0), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int, _ entity.SyncToken, send func([]entity.TaskEvent, entity.SyncToken) error) error {
+			if err := send(nil, 95); err != nil {
+				return err
+			}
+			return errors.New("db error")
+		})
+
+	handler := NewHander(&service.Service{Events: events})
+
+	r := gin.New()
+	r.Use(recovery)
+	r.GET("/api/events", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.streamEvents)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/events", nil)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { r.ServeHTTP(w, req) })
+	assert.Equal(t, "retry: 3000\n\n: ping\n\n", w.Body.String())
+}
+
+func TestHandler_CloseStreams(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	events := mock_service.NewMockEvents(c)
+	events.EXPECT().StreamTasks(gomock.Any(), 1, entity.SyncToken(0), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ int, _ entity.SyncToken, _ func([]entity.TaskEvent, entity.SyncToken) error) error {
+			<-ctx.Done()
+			return nil
+		})
+
+	handler := NewHander(&service.Service{Events: events})
+	handler.CloseStreams()
+
+	r := gin.New()
+	r.GET("/api/events", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.streamEvents)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/events", nil))
+
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// exportContentTypes are the media types of the export formats.
+var exportContentTypes = map[entity.ExportFormat]string{
+	entity.ExportCSV:      "text/csv; charset=utf-8",
+	entity.ExportJSON:     "application/json; charset=utf-8",
+	entity.ExportMarkdown: "text/markdown; charset=utf-8",
+	entity.ExportICS:      "text/calendar; charset=utf-8",
+}
+
+// @Summary Export tasks
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description streams every task you can see ordered by id, as a file download. csv has the columns of the csv import and more; json is an array of tasks; md is a checklist; ics is an iCalendar file where tasks with a due date are VTODO entries. The same tasks always give the same file
+// @ID export-tasks
+// @Produce  json
+// @Produce  text/csv
+// @Produce  text/markdown
+// @Produce  text/calendar
+// @Param format query string true "file format" Enums(csv, json, md, ics)
+// @Success 200 {file} file
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/export [get]
+func (h *Handler) exportTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	format := entity.ExportFormat(c.Query("format"))
+	if !format.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid export format",
+		})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", exportContentTypes[format])
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "tasks." + string(format)}))
+	c.Status(http.StatusOK)
+
+	if err := h.services.TaskList.ExportTasks(userID, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			// статус уже отправлен, остаётся оборвать соединение,
+			// иначе клиент примет обрезанный файл за целый
+			log.Printf("Could not finish export of user %d: %v", userID, err)
+			panic(http.ErrAbortHandler)
+		}
+
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not export tasks",
+		})
+	}
+}
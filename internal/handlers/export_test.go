@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_exportTasks(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedContentType  string
+		expectedResponseBody string
+	}{
+		{
+			name:  "CSV",
+			query: "?format=csv",
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().ExportTasks(1, entity.ExportCSV, gomock.Any()).DoAndReturn(func(_ int, _ entity.ExportFormat, w io.Writer) error {
+					_, err := io.WriteString(w, "title\nBuy milk\n")
+					return err
+				})
+			},
+			expectedStatus:       200,
+			expectedContentType:  "text/csv; charset=utf-8",
+			expectedResponseBody: "title\nBuy milk\n",
+		},
+		{
+			name:  "Failed Before Writing",
+			query: "?format=ics",
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().ExportTasks(1, entity.ExportICS, gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedStatus:       500,
+			expectedContentType:  "application/json; charset=utf-8",
+			expectedResponseBody: `{"error":"Could not export tasks"}`,
+		},
+		{
+			name:           "Unknown Format",
+			query:          "?format=xml",
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.GET("/api/export", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.exportTasks)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/export"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// Once the status is sent the handler can only drop the connection,
+// recovery must let http.ErrAbortHandler through to net/http.
+func TestHandler_exportTasks_FailedAfterWriting(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	tasks := mock_service.NewMockTaskList(c)
+	tasks.EXPECT().ExportTasks(1, entity.ExportCSV, gomock.Any()).DoAndReturn(func(_ int, _ entity.ExportFormat, w io.Writer) error {
+		if _, err := io.WriteString(w, "title\nBuy milk\n"); err != nil {
+			return err
+		}
+		return errors.New("db error")
+	})
+
+	handler := NewHander(&service.Service{TaskList: tasks})
+
+	r := gin.New()
+	r.Use(recovery)
+	r.GET("/api/export", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.exportTasks)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/export?format=csv", nil)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { r.ServeHTTP(w, req) })
+}
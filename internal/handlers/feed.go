@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create feed token
+// @Security ApiKeyAuth
+// @Tags feed
+// @Description issues a secret URL of an iCalendar feed with your tasks that have due dates, for calendar apps. The previous URL stops working. Keep the URL secret: anyone who has it can read the feed
+// @ID create-feed-token
+// @Produce  json
+// @Success 201 {object} entity.FeedTokenResponse
+// @Failure 500 {string} string "error"
+// @Router /api/feed [post]
+func (h *Handler) createFeedToken(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := h.services.Feeds.CreateFeedToken(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create feed token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entity.FeedTokenResponse{
+		Token: token,
+		URL:   "/feeds/" + token + ".ics",
+	})
+}
+
+// @Summary Revoke feed token
+// @Security ApiKeyAuth
+// @Tags feed
+// @Description the feed URL stops working
+// @ID revoke-feed-token
+// @Produce  json
+// @Success 200 {string} string "deleted"
+// @Failure 500 {string} string "error"
+// @Router /api/feed [delete]
+func (h *Handler) revokeFeedToken(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.services.Feeds.RevokeFeedToken(userID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not revoke feed token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
+
+// @Summary Calendar feed
+// @Tags feed
+// @Description RFC 5545 calendar of the token owner's tasks with due dates as VTODO entries. No JWT, the token is the secret. Supports If-None-Match and If-Modified-Since
+// @ID get-feed
+// @Produce  text/calendar
+// @Param token path string true "feed token with .ics"
+// @Success 200 {file} file
+// @Success 304 {string} string "not modified"
+// @Failure 404 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /feeds/{token}.ics [get]
+func (h *Handler) getFeed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "feed not found",
+		})
+		return
+	}
+
+	feed, err := h.services.Feeds.GetFeed(token)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get feed",
+		})
+		return
+	}
+
+	c.Header("ETag", feed.ETag)
+	c.Header("Last-Modified", feed.ModifiedAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache") // приложение может хранить копию, но проверяет её каждый раз
+
+	if notModified(c, feed) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, exportContentTypes[entity.ExportICS], feed.Body)
+}
+
+// notModified evaluates the conditional headers of a feed request, If-None-Match
+// wins over If-Modified-Since as RFC 9110 says.
+func notModified(c *gin.Context, feed entity.Feed) bool {
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		return etagListed(header, feed.ETag)
+	}
+
+	since, err := time.Parse(http.TimeFormat, c.GetHeader("If-Modified-Since"))
+	return err == nil && !feed.ModifiedAt.After(since)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getFeed(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	feed := entity.Feed{
+		ETag:       `"abc"`,
+		ModifiedAt: time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC),
+		Body:       []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"),
+	}
+
+	tests := []struct {
+		name                 string
+		path                 string
+		headers              map[string]string
+		mock                 func(s *mock_service.MockFeeds)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			path: "/feeds/secret.ics",
+			mock: func(s *mock_service.MockFeeds) {
+				s.EXPECT().GetFeed("secret").Return(feed, nil)
+			},
+			expectedStatus:       200,
+			expectedResponseBody: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+		},
+		{
+			name:    "ETag Matches",
+			path:    "/feeds/secret.ics",
+			headers: map[string]string{"If-None-Match": `W/"abc"`},
+			mock: func(s *mock_service.MockFeeds) {
+				s.EXPECT().GetFeed("secret").Return(feed, nil)
+			},
+			expectedStatus: 304,
+		},
+		{
+			name:    "ETag Wins Over Date",
+			path:    "/feeds/secret.ics",
+			headers: map[string]string{"If-None-Match": `"old"`, "If-Modified-Since": "Tue, 06 May 2025 12:00:00 GMT"},
+			mock: func(s *mock_service.MockFeeds) {
+				s.EXPECT().GetFeed("secret").Return(feed, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:    "Not Modified Since",
+			path:    "/feeds/secret.ics",
+			headers: map[string]string{"If-Modified-Since": "Tue, 06 May 2025 12:00:00 GMT"},
+			mock: func(s *mock_service.MockFeeds) {
+				s.EXPECT().GetFeed("secret").Return(feed, nil)
+			},
+			expectedStatus: 304,
+		},
+		{
+			name: "Revoked",
+			path: "/feeds/secret.ics",
+			mock: func(s *mock_service.MockFeeds) {
+				s.EXPECT().GetFeed("secret").Return(entity.Feed{}, entity.ErrFeedNotFound)
+			},
+			expectedStatus: 404,
+		},
+		{
+			name:           "No Extension",
+			path:           "/feeds/secret",
+			mock:           func(*mock_service.MockFeeds) {},
+			expectedStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			feeds := mock_service.NewMockFeeds(c)
+			tt.mock(feeds)
+
+			handler := NewHander(&service.Service{Feeds: feeds})
+
+			r := gin.New()
+			r.GET("/feeds/:file", handler.getFeed)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus < 300 {
+				assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+				assert.Equal(t, "Tue, 06 May 2025 12:00:00 GMT", w.Header().Get("Last-Modified"))
+			}
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_createFeedToken(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	feeds := mock_service.NewMockFeeds(c)
+	feeds.EXPECT().CreateFeedToken(1).Return("secret", nil)
+
+	handler := NewHander(&service.Service{Feeds: feeds})
+
+	r := gin.New()
+	r.POST("/api/feed", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.createFeedToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/feed", nil))
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, `{"token":"secret","url":"/feeds/secret.ics"}`, w.Body.String())
+}
@@ -24,11 +24,89 @@ func TestInitRoutes(t *testing.T) {
 		"POST /auth/sign-up",
 		"POST /auth/sign-in",
 		"POST /auth/refresh",
+		"GET /feeds/:file",
+		"HEAD /feeds/:file",
+		"GET /.well-known/caldav",
+		"PROPFIND /.well-known/caldav",
+		"PROPFIND /caldav/",
+		"PROPFIND /caldav/tasks/",
+		"REPORT /caldav/tasks/",
+		"PROPFIND /caldav/tasks/:name",
+		"GET /caldav/tasks/:name",
+		"HEAD /caldav/tasks/:name",
+		"PUT /caldav/tasks/:name",
+		"DELETE /caldav/tasks/:name",
 		"GET /api/",
 		"GET /api/:id",
 		"POST /api/",
 		"PUT /api/:id",
+		"PATCH /api/:id",
 		"DELETE /api/:id",
+		"POST /api/batch",
+		"GET /api/sync",
+		"POST /api/sync",
+		"GET /api/events",
+		"POST /api/import",
+		"GET /api/export",
+		"POST /api/feed",
+		"DELETE /api/feed",
+		"GET /api/app-passwords",
+		"POST /api/app-passwords",
+		"DELETE /api/app-passwords/:id",
+		"GET /api/:id/history",
+		"GET /api/:id/role",
+		"GET /api/:id/shares",
+		"PUT /api/:id/shares",
+		"DELETE /api/:id/shares/:user_id",
+		"GET /api/:id/assignees",
+		"PUT /api/:id/assignees",
+		"GET /api/assigned",
+		"GET /api/:id/comments",
+		"POST /api/:id/comments",
+		"PUT /api/:id/comments/:comment_id",
+		"DELETE /api/:id/comments/:comment_id",
+		"GET /api/:id/attachments",
+		"POST /api/:id/attachments",
+		"GET /api/:id/attachments/:attachment_id",
+		"DELETE /api/:id/attachments/:attachment_id",
+		"PUT /api/:id/status",
+		"POST /api/:id/complete",
+		"POST /api/:id/reopen",
+		"GET /api/search",
+		"PUT /api/:id/due",
+		"GET /api/overdue",
+		"GET /api/due/today",
+		"GET /api/due/week",
+		"PUT /api/:id/recurrence",
+		"GET /api/:id/occurrences",
+		"PUT /api/:id/priority",
+		"POST /api/:id/move",
+		"PUT /api/:id/project",
+		"GET /api/:id/subtasks",
+		"POST /api/:id/subtasks",
+		"PUT /api/:id/parent",
+		"POST /api/:id/tags/:tag_id",
+		"DELETE /api/:id/tags/:tag_id",
+		"GET /api/trash",
+		"DELETE /api/trash",
+		"POST /api/trash/:id/restore",
+		"DELETE /api/trash/:id",
+		"GET /api/tags",
+		"POST /api/tags",
+		"GET /api/tags/:id",
+		"PUT /api/tags/:id",
+		"DELETE /api/tags/:id",
+		"GET /api/projects",
+		"POST /api/projects",
+		"GET /api/projects/:pid",
+		"PUT /api/projects/:pid",
+		"DELETE /api/projects/:pid",
+		"GET /api/projects/:pid/tasks",
+		"POST /api/projects/:pid/tasks",
+		"GET /api/projects/:pid/role",
+		"GET /api/projects/:pid/shares",
+		"PUT /api/projects/:pid/shares",
+		"DELETE /api/projects/:pid/shares/:user_id",
 		"POST /api/admin/upload-file",
 		"GET /api/admin/get-files",
 	}
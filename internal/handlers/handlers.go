@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	_ "github.com/AronditFire/todo-app/docs"
 	"github.com/AronditFire/todo-app/internal/service"
@@ -12,21 +14,37 @@ import (
 
 type Handler struct {
 	services *service.Service
+
+	streams      context.Context // отменяется в CloseStreams
+	closeStreams context.CancelFunc
 }
 
 func NewHander(sv *service.Service) *Handler {
-	return &Handler{services: sv}
+	streams, closeStreams := context.WithCancel(context.Background())
+	return &Handler{services: sv, streams: streams, closeStreams: closeStreams}
+}
+
+// CloseStreams ends open event streams. Call it before shutting the server
+// down: Shutdown waits for connections to go idle and a stream never does.
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
 }
 
 func (h *Handler) InitRoutes(healthFunc http.HandlerFunc) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Logger(), recovery)
 
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match, Idempotency-Key, Last-Event-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed")
 		if c.Request.Method == "OPTIONS" {
+			if strings.HasPrefix(c.Request.URL.Path, "/caldav") {
+				c.Header("DAV", "1, 3, calendar-access")
+				c.Header("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+			}
 			c.AbortWithStatus(http.StatusOK)
 			return
 		}
@@ -45,18 +63,131 @@ func (h *Handler) InitRoutes(healthFunc http.HandlerFunc) *gin.Engine {
 
 	auth := router.Group("/auth")
 	{
-		auth.POST("/sign-up", h.registerUser)
+		auth.POST("/sign-up", h.idempotent, h.registerUser)
 		auth.POST("/sign-in", h.loginUser)
 		auth.POST("/refresh", h.refreshTokens)
 	}
 
+	// календарные приложения не умеют JWT, секрет - сам токен в пути
+	feeds := router.Group("/feeds")
+	{
+		feeds.GET("/:file", h.getFeed) // <token>.ics
+		feeds.HEAD("/:file", h.getFeed)
+	}
+
+	router.GET("/.well-known/caldav", h.caldavRedirect)
+	router.Handle("PROPFIND", "/.well-known/caldav", h.caldavRedirect)
+
+	caldav := router.Group("/caldav", h.appPasswordIdentify) // HTTP Basic: username and app password
+	{
+		caldav.Handle("PROPFIND", "/", h.propfindPrincipal)
+		caldav.Handle("PROPFIND", "/tasks/", h.propfindCalendar)
+		caldav.Handle("REPORT", "/tasks/", h.reportCalendar) // calendar-query, calendar-multiget, sync-collection
+		caldav.Handle("PROPFIND", "/tasks/:name", h.propfindCalendarObject)
+		caldav.GET("/tasks/:name", h.getCalendarObject) // VTODO of one task
+		caldav.HEAD("/tasks/:name", h.getCalendarObject)
+		caldav.PUT("/tasks/:name", h.putCalendarObject)
+		caldav.DELETE("/tasks/:name", h.deleteCalendarObject)
+	}
+
 	api := router.Group("/api", h.userIdentify)
 	{
-		api.GET("/", h.getAllTasks)      // get all tasks
-		api.GET("/:id", h.getTaskByID)   // get 1 task
-		api.POST("/", h.createTask)      // create task
-		api.PUT("/:id", h.updateTask)    // update task
-		api.DELETE("/:id", h.deleteTask) // delete task
+		api.GET("/", h.getAllTasks)                    // get all tasks
+		api.GET("/:id", h.getTaskByID)                 // get 1 task
+		api.POST("/", h.idempotent, h.createTask)      // create task, Idempotency-Key makes retries safe
+		api.PUT("/:id", h.updateTask)                  // update task
+		api.PATCH("/:id", h.patchTask)                 // merge patch of any task fields
+		api.DELETE("/:id", h.deleteTask)               // move task to trash
+		api.POST("/batch", h.idempotent, h.runBatch)   // many operations in one transaction
+		api.GET("/sync", h.getChanges)                 // ?since=<token>, changes and tombstones for offline clients
+		api.POST("/sync", h.idempotent, h.pushChanges) // changes made offline, conflicts by version
+		api.GET("/events", h.streamEvents)             // SSE stream of task events, resumes from Last-Event-ID
+		api.POST("/import", h.importTasks)             // multipart csv, todoist or trello export, ?dry_run=true previews
+		api.GET("/export", h.exportTasks)              // stream of all tasks as csv, json, md or ics
+		api.POST("/feed", h.createFeedToken)           // new secret calendar feed URL, the old one stops working
+		api.DELETE("/feed", h.revokeFeedToken)
+		api.GET("/app-passwords", h.getAppPasswords)
+		api.POST("/app-passwords", h.createAppPassword) // password for a CalDAV client, shown once
+		api.DELETE("/app-passwords/:id", h.deleteAppPassword)
+
+		api.GET("/:id/history", h.getTaskHistory) // who changed what and when
+
+		api.GET("/:id/role", h.getTaskRole) // what the user may do with the task
+		api.GET("/:id/shares", h.getTaskShares)
+		api.PUT("/:id/shares", h.shareTask)               // grant or change role
+		api.DELETE("/:id/shares/:user_id", h.unshareTask) // revoke or leave
+
+		api.GET("/:id/assignees", h.getAssignees)
+		api.PUT("/:id/assignees", h.setAssignees) // members of the task's project
+		api.GET("/assigned", h.getAssignedTasks)  // assigned to me in all projects
+
+		api.GET("/:id/comments", h.getComments) // ?limit=&cursor=, oldest first
+		api.POST("/:id/comments", h.createComment)
+		api.PUT("/:id/comments/:comment_id", h.updateComment)    // author only
+		api.DELETE("/:id/comments/:comment_id", h.deleteComment) // author or task owner
+
+		api.GET("/:id/attachments", h.getAttachments)
+		api.POST("/:id/attachments", h.uploadAttachment) // multipart, field "file"
+		api.GET("/:id/attachments/:attachment_id", h.downloadAttachment)
+		api.DELETE("/:id/attachments/:attachment_id", h.deleteAttachment)
+
+		api.PUT("/:id/status", h.updateTaskStatus) // change lifecycle state
+		api.POST("/:id/complete", h.completeTask)  // mark as done, ?subtasks=true
+		api.POST("/:id/reopen", h.reopenTask)      // back to todo
+
+		api.GET("/search", h.searchTasks) // ?q=buy mil
+
+		api.PUT("/:id/due", h.updateTaskDue)        // set due date and reminder
+		api.GET("/overdue", h.getOverdueTasks)      // open tasks past due
+		api.GET("/due/today", h.getTasksDueToday)   // ?tz=Europe/Moscow
+		api.GET("/due/week", h.getTasksDueThisWeek) // ?tz=Europe/Moscow
+
+		api.PUT("/:id/recurrence", h.updateTaskRecurrence) // RRULE, empty stops
+		api.GET("/:id/occurrences", h.getOccurrences)      // ?count=5
+
+		api.PUT("/:id/priority", h.updateTaskPriority) // P1-P4
+		api.POST("/:id/move", h.moveTask)              // manual reorder
+
+		api.PUT("/:id/project", h.updateTaskProject) // null moves back to inbox
+
+		api.GET("/:id/subtasks", h.getSubtasks)    // direct children and progress
+		api.POST("/:id/subtasks", h.createSubtask) // create child task
+		api.PUT("/:id/parent", h.updateTaskParent) // null makes task top level
+
+		api.POST("/:id/tags/:tag_id", h.attachTag)
+		api.DELETE("/:id/tags/:tag_id", h.detachTag)
+
+		trash := api.Group("/trash")
+		{
+			trash.GET("", h.getTrash)
+			trash.DELETE("", h.emptyTrash)            // purge everything
+			trash.POST("/:id/restore", h.restoreTask) // back with its subtasks
+			trash.DELETE("/:id", h.purgeTask)         // purge for good
+		}
+
+		tags := api.Group("/tags")
+		{
+			tags.GET("", h.getAllTags)
+			tags.POST("", h.createTag)
+			tags.GET("/:id", h.getTagByID)
+			tags.PUT("/:id", h.updateTag)
+			tags.DELETE("/:id", h.deleteTag)
+		}
+
+		projects := api.Group("/projects")
+		{
+			projects.GET("", h.getAllProjects)
+			projects.POST("", h.createProject)
+			projects.GET("/:pid", h.getProjectByID)
+			projects.PUT("/:pid", h.updateProject)
+			projects.DELETE("/:pid", h.deleteProject) // ?mode=cascade|move&target=ID
+			projects.GET("/:pid/tasks", h.getProjectTasks)
+			projects.POST("/:pid/tasks", h.createProjectTask)
+			projects.GET("/:pid/role", h.getProjectRole)
+			projects.GET("/:pid/shares", h.getProjectShares)
+			projects.PUT("/:pid/shares", h.shareProject)
+			projects.DELETE("/:pid/shares/:user_id", h.unshareProject)
+		}
 
 		admin := api.Group("/admin", h.adminIdentify)
 		{
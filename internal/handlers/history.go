@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetTaskHistoryResponse struct {
+	Data []entity.TaskHistory `json:"data"`
+}
+
+// @Summary Get task history
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description changes of the task, oldest first; before and after hold only the changed fields, also works for tasks in the trash
+// @ID get-task-history
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} GetTaskHistoryResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/history [get]
+func (h *Handler) getTaskHistory(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	history, err := h.services.History.GetTaskHistory(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetTaskHistoryResponse{
+		Data: history,
+	})
+}
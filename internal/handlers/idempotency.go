@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	replayedHeader    = "Idempotent-Replayed"
+	maxIdempotencyKey = 255
+)
+
+// idempotent makes a POST safe to retry: the first response to an
+// Idempotency-Key is stored and a retry with the same key and body gets it
+// back without running the handler again. Requests without the header pass
+// through. Keys of signed in users are their own, sign-up keys are shared.
+func (h *Handler) idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKey {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Idempotency-Key is too long",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not read request body",
+		})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	scope := "anonymous"
+	if userID, err := getUserId(c); err == nil {
+		scope = fmt.Sprintf("user:%d", userID)
+	}
+	fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+	stored, err := h.services.Idempotency.ReserveKey(scope, key, fingerprint)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if stored != nil {
+		c.Header(replayedHeader, "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+		c.Abort()
+		return
+	}
+
+	// отметку продлеваем, пока идёт обработчик; после паники она истечёт сама
+	stop := h.services.Idempotency.HoldKey(scope, key)
+	defer stop()
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+	// продление не должно срезать срок сохранённого ответа
+	stop()
+
+	if err := h.services.Idempotency.SaveResponse(scope, key, entity.IdempotentResponse{
+		Fingerprint: fingerprint,
+		Status:      recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	}); err != nil {
+		// ответ уже ушёл клиенту, повтор просто выполнится заново
+		log.Printf("Could not save idempotent response: %v", err)
+	}
+}
+
+// requestFingerprint tells apart different requests sent with the same key.
+func requestFingerprint(method, path string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(method + " " + path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
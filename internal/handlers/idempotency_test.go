@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_idempotent(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	body := `{"description":"test"}`
+	fingerprint := requestFingerprint("POST", "/api/", []byte(body))
+
+	tests := []struct {
+		name                 string
+		key                  string
+		mock                 func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+		expectedReplayed     string
+	}{
+		{
+			name: "No Key",
+			mock: func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList) {
+				tasks.EXPECT().CreateTask(1, entity.Task{Description: "test"}).Return(7, nil)
+			},
+			expectedStatus:       201,
+			expectedResponseBody: `{"id":7,"message":"created"}`,
+		},
+		{
+			name: "First Request",
+			key:  "k1",
+			mock: func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList) {
+				s.EXPECT().ReserveKey("user:1", "k1", fingerprint).Return(nil, nil)
+				s.EXPECT().HoldKey("user:1", "k1").Return(func() {})
+				tasks.EXPECT().CreateTask(1, entity.Task{Description: "test"}).Return(7, nil)
+				s.EXPECT().SaveResponse("user:1", "k1", entity.IdempotentResponse{
+					Fingerprint: fingerprint,
+					Status:      201,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"id":7,"message":"created"}`),
+				}).Return(nil)
+			},
+			expectedStatus:       201,
+			expectedResponseBody: `{"id":7,"message":"created"}`,
+		},
+		{
+			name: "Retry",
+			key:  "k1",
+			mock: func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList) {
+				s.EXPECT().ReserveKey("user:1", "k1", fingerprint).Return(&entity.IdempotentResponse{
+					Fingerprint: fingerprint,
+					Status:      201,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"id":7,"message":"created"}`),
+				}, nil)
+			},
+			expectedStatus:       201,
+			expectedResponseBody: `{"id":7,"message":"created"}`,
+			expectedReplayed:     "true",
+		},
+		{
+			name: "Key Reused",
+			key:  "k1",
+			mock: func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList) {
+				s.EXPECT().ReserveKey("user:1", "k1", fingerprint).Return(nil, entity.ErrIdempotencyKeyReused)
+			},
+			expectedStatus:       422,
+			expectedResponseBody: `{"error":"Idempotency-Key was already used with another request"}`,
+		},
+		{
+			name: "In Progress",
+			key:  "k1",
+			mock: func(s *mock_service.MockIdempotency, tasks *mock_service.MockTaskList) {
+				s.EXPECT().ReserveKey("user:1", "k1", fingerprint).Return(nil, entity.ErrIdempotencyInProgress)
+			},
+			expectedStatus: 409,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			idempotency := mock_service.NewMockIdempotency(c)
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(idempotency, tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks, Idempotency: idempotency})
+
+			r := gin.New()
+			r.POST("/api/", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.idempotent, handler.createTask)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/", bytes.NewBufferString(body))
+			if tt.key != "" {
+				req.Header.Set(idempotencyHeader, tt.key)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+			assert.Equal(t, tt.expectedReplayed, w.Header().Get(replayedHeader))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Import tasks
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description creates tasks from a file exported by another tool. csv has the columns title, due_date, priority, labels (comma separated), completed and checklist (semicolon separated); todoist is a project exported as CSV; trello is a board exported as JSON. Labels become tags, checklist items become subtasks. Rows that can not be imported are listed in errors, the rest is created in one transaction. With dry_run=true nothing is created
+// @ID import-tasks
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param format query string true "export format" Enums(csv, todoist, trello)
+// @Param dry_run query bool false "only preview the import"
+// @Param file formData file true "exported file, up to 5 MiB"
+// @Success 200 {object} entity.ImportReport "dry run"
+// @Success 201 {object} entity.ImportReport
+// @Failure 400 {string} string "error"
+// @Failure 413 {string} string "error"
+// @Failure 422 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/import [post]
+func (h *Handler) importTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	format := entity.ImportFormat(c.Query("format"))
+	if !format.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid import format",
+		})
+		return
+	}
+
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid dry_run",
+			})
+			return
+		}
+	}
+
+	var bindfile entity.BindFile
+	if err := c.ShouldBind(&bindfile); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "could not bind uploaded file",
+		})
+		return
+	}
+
+	report, err := h.services.TaskList.ImportTasks(userID, format, bindfile.File, dryRun)
+	if err != nil {
+		msg := "Could not import tasks"
+		if errors.Is(err, entity.ErrImportFile) {
+			msg = err.Error() // клиенту нужно знать, что не так с файлом
+		}
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": msg,
+		})
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_importTasks(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	report := entity.ImportReport{
+		Tasks:  []entity.ImportTask{{Row: 2, Task: entity.Task{ID: 10, Description: "Buy milk"}}},
+		Errors: []entity.ImportError{{Row: 3, Error: "task has no title"}},
+	}
+
+	tests := []struct {
+		name                 string
+		query                string
+		withFile             bool
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:     "Created",
+			query:    "?format=csv",
+			withFile: true,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().ImportTasks(1, entity.ImportCSV, gomock.Any(), false).Return(report, nil)
+			},
+			expectedStatus: 201,
+		},
+		{
+			name:     "Dry Run",
+			query:    "?format=trello&dry_run=true",
+			withFile: true,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().ImportTasks(1, entity.ImportTrello, gomock.Any(), true).Return(entity.ImportReport{DryRun: true}, nil)
+			},
+			expectedStatus:       200,
+			expectedResponseBody: `{"dry_run":true,"tasks":null,"errors":null}`,
+		},
+		{
+			name:     "Unreadable File",
+			query:    "?format=todoist",
+			withFile: true,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().ImportTasks(1, entity.ImportTodoist, gomock.Any(), false).
+					Return(entity.ImportReport{}, fmt.Errorf("%w: no CONTENT column", entity.ErrImportFile))
+			},
+			expectedStatus:       422,
+			expectedResponseBody: `{"error":"Could not read import file: no CONTENT column"}`,
+		},
+		{
+			name:           "Unknown Format",
+			query:          "?format=asana",
+			withFile:       true,
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+		{
+			name:           "Invalid Dry Run",
+			query:          "?format=csv&dry_run=maybe",
+			withFile:       true,
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+		{
+			name:           "No File",
+			query:          "?format=csv",
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			if tt.withFile {
+				part, err := writer.CreateFormFile("file", "export")
+				assert.NoError(t, err)
+				_, err = part.Write([]byte("title\nBuy milk\n"))
+				assert.NoError(t, err)
+			}
+			writer.Close()
+
+			r := gin.New()
+			r.POST("/api/import", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.importTasks)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/import"+tt.query, &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
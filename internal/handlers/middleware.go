@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
+	"runtime/debug"
 	"strings"
 
+	"github.com/AronditFire/todo-app/entity"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,6 +18,27 @@ const (
 	adminCtx            = "isAdmin"
 )
 
+// recovery answers 500 to a panicking handler like gin.Recovery, except for
+// http.ErrAbortHandler: it goes on to net/http, which drops the connection.
+// That is how a handler breaks a response whose status is already sent, the
+// client sees a broken download instead of a complete looking short one.
+func recovery(c *gin.Context) {
+	defer func() {
+		err := recover()
+		if err == nil {
+			return
+		}
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+
+		log.Printf("[Recovery] panic recovered: %v\n%s", err, debug.Stack())
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}()
+
+	c.Next()
+}
+
 func (h *Handler) userIdentify(c *gin.Context) {
 	header := c.GetHeader(authorizationHeader)
 
@@ -76,3 +101,34 @@ func getUserId(c *gin.Context) (int, error) {
 
 	return idInt, nil
 }
+
+// errorStatus turns a service error into a response code: an app password is
+// wrong - 401, a feed does not exist - 404, the user can see
+// the item but their role is too low - 403, If-Match names an old version - 412,
+// an upload is rejected - 413 or 415, fields of a request are invalid, an
+// Idempotency-Key is reused or an import file is unreadable - 422, the keyed
+// request is still running - 409, anything else is 500.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, entity.ErrFeedNotFound), errors.Is(err, entity.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entity.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, entity.ErrSyncTokenExpired):
+		return http.StatusGone
+	case errors.Is(err, entity.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, entity.ErrFileType):
+		return http.StatusUnsupportedMediaType
+	case errors.As(err, new(entity.FieldErrors)), errors.Is(err, entity.ErrIdempotencyKeyReused),
+		errors.Is(err, entity.ErrImportFile):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, entity.ErrIdempotencyInProgress):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/AronditFire/todo-app/entity"
 	"github.com/AronditFire/todo-app/internal/service"
 	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
 	"github.com/gin-gonic/gin"
@@ -93,8 +96,8 @@ func TestHandler_userIdentify(t *testing.T) {
 			repo := mock_service.NewMockAuthorization(c)
 			tt.mockBehaivor(repo, tt.token)
 
-			service := &service.Service{Authorization: /*repo*/ nil}
-			handler := Handler{service}
+			service := &service.Service{Authorization: repo}
+			handler := NewHander(service)
 
 			r := gin.New()
 			r.GET("/identify", handler.userIdentify, func(c *gin.Context) {
@@ -212,3 +215,33 @@ func TestHandler_getUserId(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "Not Found",
+			err:  fmt.Errorf("failed to get task from repository: %w", entity.ErrNotFound),
+			want: http.StatusNotFound,
+		},
+		{
+			name: "Forbidden",
+			err:  entity.ErrForbidden,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "Unknown",
+			err:  errors.New("db error"),
+			want: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorStatus(tt.err))
+		})
+	}
+}
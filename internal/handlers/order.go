@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Set task priority
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description set priority from 1 (P1, highest) to 4 (P4, lowest)
+// @ID update-task-priority
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskPriorityRequest true "priority"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/priority [put]
+func (h *Handler) updateTaskPriority(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskPriorityRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "priority must be between 1 and 4",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.UpdateTaskPriority(userID, id, req.Priority); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task priority in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "priority updated",
+	})
+}
+
+// @Summary Move task
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description put task right before or right after another task in the manual order
+// @ID move-task
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskMoveRequest true "before_id or after_id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/move [post]
+func (h *Handler) moveTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskMoveRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while moving task",
+		})
+		return
+	}
+
+	if (req.BeforeID > 0) == (req.AfterID > 0) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "exactly one of before_id and after_id must be set",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.MoveTask(userID, id, req); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not move task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "moved",
+	})
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetAllProjectsResponse struct {
+	Data []entity.Project `json:"data"`
+}
+
+// @Summary Get all projects
+// @Security ApiKeyAuth
+// @Tags projects
+// @Description get projects of the user, archived are hidden by default
+// @ID get-all-projects
+// @Produce  json
+// @Param archived query bool false "include archived projects"
+// @Success 200 {object} GetAllProjectsResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects [get]
+func (h *Handler) getAllProjects(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	withArchived := false
+	if raw := c.Query("archived"); raw != "" {
+		withArchived, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid archived flag",
+			})
+			return
+		}
+	}
+
+	projects, err := h.services.Projects.GetAllProjects(userID, withArchived)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get projects for this user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllProjectsResponse{
+		Data: projects,
+	})
+}
+
+// @Summary Get project
+// @Security ApiKeyAuth
+// @Tags projects
+// @ID get-project
+// @Produce  json
+// @Param pid path int true "project id"
+// @Success 200 {object} entity.Project
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid} [get]
+func (h *Handler) getProjectByID(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	project, err := h.services.Projects.GetProjectByID(userID, pid)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get project by ID",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// @Summary Create project
+// @Security ApiKeyAuth
+// @Tags projects
+// @ID create-project
+// @Accept  json
+// @Produce  json
+// @Param input body entity.ProjectRequest true "project"
+// @Success 201 {string} string "id"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects [post]
+func (h *Handler) createProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req entity.ProjectRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating project",
+		})
+		return
+	}
+
+	id, err := h.services.Projects.CreateProject(userID, entity.Project{
+		Name:     req.Name,
+		Color:    req.Color,
+		Archived: req.Archived,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create project in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      id,
+	})
+}
+
+// @Summary Update project
+// @Security ApiKeyAuth
+// @Tags projects
+// @Description rename, recolor, archive or unarchive project
+// @ID update-project
+// @Accept  json
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param input body entity.ProjectRequest true "project"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid} [put]
+func (h *Handler) updateProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	var req entity.ProjectRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating project",
+		})
+		return
+	}
+
+	if err := h.services.Projects.UpdateProject(userID, pid, req); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update project in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
+
+// @Summary Delete project
+// @Security ApiKeyAuth
+// @Tags projects
+// @Description delete project together with its tasks (cascade) or move them to another project (move, inbox if target is omitted)
+// @ID delete-project
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param mode query string true "what to do with tasks" Enums(cascade, move)
+// @Param target query int false "target project id for move mode"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid} [delete]
+func (h *Handler) deleteProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	mode := entity.ProjectDeleteMode(c.Query("mode"))
+	if !mode.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid delete mode",
+		})
+		return
+	}
+
+	targetID := 0
+	if raw := c.Query("target"); raw != "" {
+		targetID, err = strconv.Atoi(raw)
+		if err != nil || targetID <= 0 || mode != entity.ProjectDeleteMove {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid target project",
+			})
+			return
+		}
+	}
+
+	if err := h.services.Projects.DeleteProject(userID, pid, mode, targetID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not delete project in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
+
+// @Summary Get project tasks
+// @Security ApiKeyAuth
+// @Tags projects
+// @Description get one page of project tasks, accepts the same query as GET /api/
+// @ID get-project-tasks
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param status query string false "filter by status" Enums(todo, in_progress, done, cancelled)
+// @Param order query string false "sort key, manual position by default, - prefix for descending"
+// @Param limit query int false "page size, 50 by default, 200 at most"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} GetAllTaskResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/tasks [get]
+func (h *Handler) getProjectTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	filter, err := parseTaskQuery(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	filter.ProjectID = pid
+
+	page, err := h.services.TaskList.GetTaskPage(userID, filter)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get tasks of the project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTaskResponse{
+		Data:       page.Tasks,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// @Summary Create task in project
+// @Security ApiKeyAuth
+// @Tags projects
+// @ID create-project-task
+// @Accept  json
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param input body entity.Task true "task"
+// @Success 201 {string} string "id"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/tasks [post]
+func (h *Handler) createProjectTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	var req entity.Task
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating task",
+		})
+		return
+	}
+	req.ProjectID = &pid
+
+	id, err := h.services.TaskList.CreateTask(userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create task in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      id,
+	})
+}
+
+// @Summary Move task to project
+// @Security ApiKeyAuth
+// @Tags projects
+// @Description move task into project, null project_id moves it back to inbox
+// @ID update-task-project
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskProjectRequest true "project"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/project [put]
+func (h *Handler) updateTaskProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskProjectRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while moving task to project",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.UpdateTaskProject(userID, id, req.ProjectID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task project in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/rrule"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultOccurrences is the preview size when ?count is omitted.
+const defaultOccurrences = 5
+
+type GetOccurrencesResponse struct {
+	Data []time.Time `json:"data"`
+}
+
+// @Summary Set task recurrence
+// @Security ApiKeyAuth
+// @Tags recurrence
+// @Description set RFC 5545 RRULE counted from the due date, empty string stops the recurrence
+// @ID update-task-recurrence
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskRecurrenceRequest true "rule"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/recurrence [put]
+func (h *Handler) updateTaskRecurrence(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskRecurrenceRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating task recurrence",
+		})
+		return
+	}
+
+	if req.Recurrence != "" {
+		if _, err := rrule.Parse(req.Recurrence); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid recurrence rule: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := h.services.TaskList.UpdateTaskRecurrence(userID, id, req.Recurrence); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task recurrence in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
+
+// @Summary Preview task occurrences
+// @Security ApiKeyAuth
+// @Tags recurrence
+// @Description list next occurrences of a recurring task that follow the current one
+// @ID get-task-occurrences
+// @Produce  json
+// @Param id path int true "task id"
+// @Param count query int false "how many occurrences, 5 by default, 50 at most"
+// @Success 200 {object} GetOccurrencesResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/occurrences [get]
+func (h *Handler) getOccurrences(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	count := defaultOccurrences
+	if raw := c.Query("count"); raw != "" {
+		count, err = strconv.Atoi(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid count",
+			})
+			return
+		}
+	}
+
+	occurrences, err := h.services.TaskList.GetOccurrences(userID, id, count)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task occurrences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetOccurrencesResponse{
+		Data: occurrences,
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSearchLimit is used when ?limit is omitted.
+const defaultSearchLimit = 20
+
+type SearchTasksResponse struct {
+	Data []entity.TaskSearchResult `json:"data"`
+}
+
+// @Summary Search tasks
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description ranked full-text search over task descriptions, every word of the query matches as a prefix; snippet is HTML: the description with special characters escaped and matches wrapped into <mark></mark>
+// @ID search-tasks
+// @Produce  json
+// @Param q query string true "search query" example(buy mil)
+// @Param limit query int false "how many results, 20 by default, 100 at most"
+// @Success 200 {object} SearchTasksResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/search [get]
+func (h *Handler) searchTasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	query := c.Query("q")
+	if query == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "empty search query",
+		})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > 100 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid limit",
+			})
+			return
+		}
+	}
+
+	results, err := h.services.Search.SearchTasks(userID, query, limit)
+	if err != nil {
+		if errors.Is(err, entity.ErrSearchQuery) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not search tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SearchTasksResponse{
+		Data: results,
+	})
+}
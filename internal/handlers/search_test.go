@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_searchTasks(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mock                 func(s *mock_service.MockSearch)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?q=mil",
+			mock: func(s *mock_service.MockSearch) {
+				s.EXPECT().SearchTasks(1, "mil", 20).Return([]entity.TaskSearchResult{{
+					Task:    entity.Task{ID: 3, Description: "Buy milk & <bread>"},
+					Snippet: "Buy <mark>milk</mark> &amp; &lt;bread&gt;",
+				}}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:  "Blank Query",
+			query: "?q=%20%20",
+			mock: func(s *mock_service.MockSearch) {
+				s.EXPECT().SearchTasks(1, "  ", 20).Return(nil, entity.ErrSearchQuery)
+			},
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"Invalid search query length"}`,
+		},
+		{
+			name:  "DB Error",
+			query: "?q=mil&limit=5",
+			mock: func(s *mock_service.MockSearch) {
+				s.EXPECT().SearchTasks(1, "mil", 5).Return(nil, errors.New("pq: canceling statement"))
+			},
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not search tasks"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			search := mock_service.NewMockSearch(c)
+			tt.mock(search)
+
+			handler := NewHander(&service.Service{Search: search})
+
+			r := gin.New()
+			r.GET("/api/search", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.searchTasks)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/api/search"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
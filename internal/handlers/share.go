@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetTaskSharesResponse struct {
+	Data []entity.TaskShare `json:"data"`
+}
+
+type GetProjectSharesResponse struct {
+	Data []entity.ProjectShare `json:"data"`
+}
+
+type RoleResponse struct {
+	Role entity.Role `json:"role" example:"editor"`
+}
+
+// @Summary Get task role
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description role of the current user for the task: owner, editor or viewer
+// @ID get-task-role
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} RoleResponse
+// @Failure 400 {string} string "error"
+// @Failure 404 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/role [get]
+func (h *Handler) getTaskRole(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	role, err := h.services.Sharing.TaskRole(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task role",
+		})
+		return
+	}
+	if role == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "task not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RoleResponse{
+		Role: role,
+	})
+}
+
+// @Summary Get project role
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description role of the current user for the project: owner, editor or viewer
+// @ID get-project-role
+// @Produce  json
+// @Param pid path int true "project id"
+// @Success 200 {object} RoleResponse
+// @Failure 400 {string} string "error"
+// @Failure 404 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/role [get]
+func (h *Handler) getProjectRole(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	role, err := h.services.Sharing.ProjectRole(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get project role",
+		})
+		return
+	}
+	if role == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "project not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RoleResponse{
+		Role: role,
+	})
+}
+
+// @Summary Get task shares
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description users the task is shared with and their roles, the owner is not listed
+// @ID get-task-shares
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} GetTaskSharesResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/shares [get]
+func (h *Handler) getTaskShares(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	shares, err := h.services.Sharing.GetTaskShares(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get task shares",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetTaskSharesResponse{
+		Data: shares,
+	})
+}
+
+// @Summary Share task
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description give another user access to the task and its subtasks; only the owner can share, sharing again changes the role
+// @ID share-task
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.ShareRequest true "user and role: viewer, editor or owner"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/shares [put]
+func (h *Handler) shareTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.ShareRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while sharing task",
+		})
+		return
+	}
+
+	if !req.Role.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid role",
+		})
+		return
+	}
+
+	if err := h.services.Sharing.ShareTask(userID, id, req); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not share task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "shared",
+	})
+}
+
+// @Summary Unshare task
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description take the access away; the owner can remove anybody, other users can only leave
+// @ID unshare-task
+// @Produce  json
+// @Param id path int true "task id"
+// @Param user_id path int true "user id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/shares/{user_id} [delete]
+func (h *Handler) unshareTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	granteeID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user id",
+		})
+		return
+	}
+
+	if err := h.services.Sharing.UnshareTask(userID, id, granteeID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not unshare task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "unshared",
+	})
+}
+
+// @Summary Get project shares
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description users the project is shared with and their roles, the owner is not listed
+// @ID get-project-shares
+// @Produce  json
+// @Param pid path int true "project id"
+// @Success 200 {object} GetProjectSharesResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/shares [get]
+func (h *Handler) getProjectShares(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	shares, err := h.services.Sharing.GetProjectShares(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get project shares",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetProjectSharesResponse{
+		Data: shares,
+	})
+}
+
+// @Summary Share project
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description give another user access to the project and all of its tasks; only the owner can share, sharing again changes the role
+// @ID share-project
+// @Accept  json
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param input body entity.ShareRequest true "user and role: viewer, editor or owner"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/shares [put]
+func (h *Handler) shareProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	var req entity.ShareRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while sharing project",
+		})
+		return
+	}
+
+	if !req.Role.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid role",
+		})
+		return
+	}
+
+	if err := h.services.Sharing.ShareProject(userID, id, req); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not share project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "shared",
+	})
+}
+
+// @Summary Unshare project
+// @Security ApiKeyAuth
+// @Tags sharing
+// @Description take the access away; the owner can remove anybody, other users can only leave
+// @ID unshare-project
+// @Produce  json
+// @Param pid path int true "project id"
+// @Param user_id path int true "user id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 403 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/projects/{pid}/shares/{user_id} [delete]
+func (h *Handler) unshareProject(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("pid"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid project id",
+		})
+		return
+	}
+
+	granteeID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user id",
+		})
+		return
+	}
+
+	if err := h.services.Sharing.UnshareProject(userID, id, granteeID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not unshare project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "unshared",
+	})
+}
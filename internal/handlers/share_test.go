@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getTaskRole(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name                 string
+		role                 entity.Role
+		serviceErr           error
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			role:                 entity.RoleEditor,
+			expectedStatus:       200,
+			expectedResponseBody: `{"role":"editor"}`,
+		},
+		{
+			name:                 "No Access",
+			expectedStatus:       404,
+			expectedResponseBody: `{"error":"task not found"}`,
+		},
+		{
+			name:                 "DB Error",
+			serviceErr:           errors.New("pq: connection refused"),
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not get task role"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sharing := mock_service.NewMockSharing(c)
+			sharing.EXPECT().TaskRole(1, 5).Return(tt.role, tt.serviceErr)
+
+			handler := NewHander(&service.Service{Sharing: sharing})
+
+			r := gin.New()
+			r.GET("/api/:id/role", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.getTaskRole)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/5/role", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type GetSubtasksResponse struct {
+	Data     []entity.Task `json:"data"`
+	Progress *int          `json:"progress,omitempty"`
+}
+
+// @Summary Get subtasks
+// @Security ApiKeyAuth
+// @Tags subtasks
+// @Description get direct subtasks of the task and share of done ones in percent
+// @ID get-subtasks
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {object} GetSubtasksResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/subtasks [get]
+func (h *Handler) getSubtasks(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	subtasks, err := h.services.TaskList.GetSubtasks(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get subtasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetSubtasksResponse{
+		Data:     subtasks,
+		Progress: service.SubtaskProgress(subtasks),
+	})
+}
+
+// @Summary Create subtask
+// @Security ApiKeyAuth
+// @Tags subtasks
+// @ID create-subtask
+// @Accept  json
+// @Produce  json
+// @Param id path int true "parent task id"
+// @Param input body entity.Task true "task"
+// @Success 201 {string} string "id"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/subtasks [post]
+func (h *Handler) createSubtask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.Task
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating subtask",
+		})
+		return
+	}
+	req.ParentID = &id
+
+	subtaskID, err := h.services.TaskList.CreateTask(userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create subtask in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      subtaskID,
+	})
+}
+
+// @Summary Change task parent
+// @Security ApiKeyAuth
+// @Tags subtasks
+// @Description make task a subtask of another one, null parent_id makes it top level
+// @ID update-task-parent
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskParentRequest true "parent"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/parent [put]
+func (h *Handler) updateTaskParent(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskParentRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating task parent",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.UpdateTaskParent(userID, id, req.ParentID); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task parent in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
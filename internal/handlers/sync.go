@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get changes
+// @Security ApiKeyAuth
+// @Tags sync
+// @Description tasks created or changed since the token and ids of tasks deleted, moved to the trash or no longer shared since then, with the token for the next call. Without a token returns all tasks. A change may be returned more than once. A token older than 30 days gets 410, sync again without it
+// @ID get-changes
+// @Produce  json
+// @Param since query string false "token of the previous sync"
+// @Success 200 {object} entity.SyncResponse
+// @Failure 400 {string} string "error"
+// @Failure 410 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/sync [get]
+func (h *Handler) getChanges(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var since entity.SyncToken
+	if raw := c.Query("since"); raw != "" {
+		if since, err = entity.DecodeSyncToken(raw); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid sync token",
+			})
+			return
+		}
+	}
+
+	changes, err := h.services.TaskList.Sync(userID, since)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get changes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// @Summary Push changes
+// @Security ApiKeyAuth
+// @Tags sync
+// @Description applies changes made offline, in order, as a best_effort batch of up to 500 operations. A change with the version of a task that has changed since is not applied and gets the status "conflict" with the current task; merge and push it again with the new version
+// @ID push-changes
+// @Accept  json
+// @Produce  json
+// @Param input body entity.SyncPushRequest true "changes"
+// @Param Idempotency-Key header string false "retry with the same key and body replays the first response"
+// @Success 200 {object} entity.SyncPushResponse
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/sync [post]
+func (h *Handler) pushChanges(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req entity.SyncPushRequest
+	if err := c.BindJSON(&req); err != nil || len(req.Changes) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while pushing changes",
+		})
+		return
+	}
+
+	results, err := h.services.TaskList.SyncPush(userID, req.Changes)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not push changes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.SyncPushResponse{
+		Results: results,
+	})
+}
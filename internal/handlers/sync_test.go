@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getChanges(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	token := entity.SyncToken(100).Encode()
+
+	tests := []struct {
+		name                 string
+		query                string
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name: "Full",
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Sync(1, entity.SyncToken(0)).Return(entity.SyncResponse{Tasks: []entity.Task{}, Created: []int{}, Deleted: []int{}, Token: "next"}, nil)
+			},
+			expectedStatus:       200,
+			expectedResponseBody: `{"tasks":[],"created":[],"deleted":[],"token":"next"}`,
+		},
+		{
+			name:  "Delta",
+			query: "?since=" + token,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Sync(1, entity.SyncToken(100)).Return(entity.SyncResponse{Tasks: []entity.Task{}, Created: []int{}, Deleted: []int{3}, Token: "next"}, nil)
+			},
+			expectedStatus:       200,
+			expectedResponseBody: `{"tasks":[],"created":[],"deleted":[3],"token":"next"}`,
+		},
+		{
+			name:  "Expired Token",
+			query: "?since=" + token,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Sync(1, entity.SyncToken(100)).Return(entity.SyncResponse{}, entity.ErrSyncTokenExpired)
+			},
+			expectedStatus:       410,
+			expectedResponseBody: `{"error":"Could not get changes"}`,
+		},
+		{
+			name:                 "Malformed Token",
+			query:                "?since=garbage",
+			mock:                 func(*mock_service.MockTaskList) {},
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"invalid sync token"}`,
+		},
+		{
+			name:  "Service Error",
+			query: "?since=" + token,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().Sync(1, entity.SyncToken(100)).Return(entity.SyncResponse{}, errors.New("db error"))
+			},
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not get changes"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.GET("/api/sync", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.getChanges)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/sync"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_pushChanges(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name: "Conflict",
+			body: `{"changes":[{"op":"delete","id":3,"version":1}]}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().SyncPush(1, []entity.BatchOperation{{Op: entity.BatchDelete, ID: 3, Version: 1}}).Return([]entity.SyncResult{{
+					BatchResult: entity.BatchResult{Op: entity.BatchDelete, Status: entity.SyncConflict, Error: "Version mismatch"},
+					Task:        &entity.Task{ID: 3, Description: "Gym", Version: 2},
+				}}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:                 "No Changes",
+			body:                 `{"changes":[]}`,
+			mock:                 func(*mock_service.MockTaskList) {},
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"Could not unbind request while pushing changes"}`,
+		},
+		{
+			name: "Service Error",
+			body: `{"changes":[{"op":"complete","id":3}]}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().SyncPush(1, gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus:       500,
+			expectedResponseBody: `{"error":"Could not push changes"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.POST("/api/sync", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.pushChanges)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/sync", strings.NewReader(tt.body))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), `"status":"conflict"`)
+				assert.Contains(t, w.Body.String(), `"task":{"id":3,"description":"Gym"`)
+			}
+		})
+	}
+}
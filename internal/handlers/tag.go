@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetAllTagsResponse struct {
+	Data []entity.Tag `json:"data"`
+}
+
+// @Summary Get all tags
+// @Security ApiKeyAuth
+// @Tags tags
+// @Description get all tags of the user
+// @ID get-all-tags
+// @Produce  json
+// @Success 200 {object} GetAllTagsResponse
+// @Failure 500 {string} string "error"
+// @Router /api/tags [get]
+func (h *Handler) getAllTags(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tags, err := h.services.Tags.GetAllTags(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get tags for this user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAllTagsResponse{
+		Data: tags,
+	})
+}
+
+// @Summary Get tag
+// @Security ApiKeyAuth
+// @Tags tags
+// @ID get-tag
+// @Produce  json
+// @Param id path int true "tag id"
+// @Success 200 {object} entity.Tag
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/tags/{id} [get]
+func (h *Handler) getTagByID(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid tag id",
+		})
+		return
+	}
+
+	tag, err := h.services.Tags.GetTagByID(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get tag by ID",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// @Summary Create tag
+// @Security ApiKeyAuth
+// @Tags tags
+// @ID create-tag
+// @Accept  json
+// @Produce  json
+// @Param input body entity.TagRequest true "tag"
+// @Success 201 {string} string "id"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/tags [post]
+func (h *Handler) createTag(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req entity.TagRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while creating tag",
+		})
+		return
+	}
+
+	id, err := h.services.Tags.CreateTag(userID, entity.Tag{Name: req.Name})
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not create tag in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "created",
+		"id":      id,
+	})
+}
+
+// @Summary Rename tag
+// @Security ApiKeyAuth
+// @Tags tags
+// @ID update-tag
+// @Accept  json
+// @Produce  json
+// @Param id path int true "tag id"
+// @Param input body entity.TagRequest true "tag"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/tags/{id} [put]
+func (h *Handler) updateTag(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid tag id",
+		})
+		return
+	}
+
+	var req entity.TagRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating tag",
+		})
+		return
+	}
+
+	if err := h.services.Tags.UpdateTag(userID, id, req.Name); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update tag in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "updated",
+	})
+}
+
+// @Summary Delete tag
+// @Security ApiKeyAuth
+// @Tags tags
+// @Description delete tag and detach it from all tasks
+// @ID delete-tag
+// @Produce  json
+// @Param id path int true "tag id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/tags/{id} [delete]
+func (h *Handler) deleteTag(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid tag id",
+		})
+		return
+	}
+
+	if err := h.services.Tags.DeleteTag(userID, id); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not delete tag in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deleted",
+	})
+}
+
+// @Summary Attach tag to task
+// @Security ApiKeyAuth
+// @Tags tags
+// @ID attach-tag
+// @Produce  json
+// @Param id path int true "task id"
+// @Param tag_id path int true "tag id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/tags/{tag_id} [post]
+func (h *Handler) attachTag(c *gin.Context) {
+	h.changeTaskTag(c, true)
+}
+
+// @Summary Detach tag from task
+// @Security ApiKeyAuth
+// @Tags tags
+// @ID detach-tag
+// @Produce  json
+// @Param id path int true "task id"
+// @Param tag_id path int true "tag id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/tags/{tag_id} [delete]
+func (h *Handler) detachTag(c *gin.Context) {
+	h.changeTaskTag(c, false)
+}
+
+func (h *Handler) changeTaskTag(c *gin.Context, attach bool) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid tag id",
+		})
+		return
+	}
+
+	if attach {
+		err = h.services.Tags.AttachTag(userID, taskID, tagID)
+	} else {
+		err = h.services.Tags.DetachTag(userID, taskID, tagID)
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not change task tags",
+		})
+		return
+	}
+
+	message := "detached"
+	if attach {
+		message = "attached"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
+// parseIDList parses comma separated ids like "1,2,3".
+func parseIDList(raw string) ([]int, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	ids := make([]int, 0, len(parts))
+	for _, p := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || id <= 0 {
+			return nil, strconv.ErrSyntax
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
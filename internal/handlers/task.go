@@ -1,24 +1,41 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AronditFire/todo-app/entity"
 	"github.com/gin-gonic/gin"
 )
 
+// defaultPageSize is used when ?limit is omitted.
+const defaultPageSize = 50
+
 type GetAllTaskResponse struct {
-	Data []entity.Task `json:"data"`
+	Data       []entity.Task `json:"data"`
+	NextCursor string        `json:"next_cursor,omitempty"` // пусто на последней странице
 }
 
 // @Summary Get All tasks
 // @Security ApiKeyAuth
 // @Tags tasks
-// @Description get all tasks
+// @Description get one page of tasks, pass next_cursor of the response as ?cursor to get the next one
 // @ID get-all-lists
 // @Accept  json
 // @Produce  json
+// @Param status query string false "filter by status" Enums(todo, in_progress, done, cancelled)
+// @Param open query bool false "only todo and in_progress tasks"
+// @Param project query int false "only tasks of the project"
+// @Param due_after query string false "due date not earlier than, RFC 3339"
+// @Param due_before query string false "due date earlier than, RFC 3339"
+// @Param tags query string false "comma separated tag ids" example(1,2)
+// @Param tag_match query string false "match any (default) or all of the tags" Enums(any, all)
+// @Param order query string false "sort key, manual position by default, - prefix for descending" Enums(position, priority, id, created_at, due_at, -position, -priority, -id, -created_at, -due_at)
+// @Param limit query int false "page size, 50 by default, 200 at most"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Success 200 {object} GetAllTaskResponse
 // @Failure 400,404 {string} string "error"
 // @Failure 500 {string} string "error"
@@ -30,20 +47,96 @@ func (h *Handler) getAllTasks(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
+		return
 	}
 
-	tasks, err := h.services.TaskList.GetAllTask(userID)
+	filter, err := parseTaskQuery(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	page, err := h.services.TaskList.GetTaskPage(userID, filter)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
 			"error": "Could not get tasks for this user",
 		})
 		return
 	}
 	c.JSON(http.StatusOK, GetAllTaskResponse{
-		Data: tasks,
+		Data:       page.Tasks,
+		NextCursor: page.NextCursor,
 	})
 }
 
+// parseTaskQuery reads filters, sort order and page of a task list from the query string.
+func parseTaskQuery(c *gin.Context) (entity.TaskFilter, error) {
+	tagIDs, err := parseIDList(c.Query("tags"))
+	if err != nil {
+		return entity.TaskFilter{}, errors.New("invalid tags filter")
+	}
+
+	order := c.Query("order")
+	filter := entity.TaskFilter{
+		Status:   entity.TaskStatus(c.Query("status")),
+		TagIDs:   tagIDs,
+		TagMatch: entity.TagMatch(c.Query("tag_match")),
+		Order:    entity.TaskOrder(strings.TrimPrefix(order, "-")),
+		Desc:     strings.HasPrefix(order, "-"),
+		Limit:    defaultPageSize,
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return entity.TaskFilter{}, errors.New("invalid status filter")
+	}
+	if !filter.TagMatch.IsValid() {
+		return entity.TaskFilter{}, errors.New("invalid tag_match")
+	}
+	if !filter.Order.IsValid() {
+		return entity.TaskFilter{}, errors.New("invalid order")
+	}
+
+	if raw := c.Query("open"); raw != "" {
+		if filter.OnlyOpen, err = strconv.ParseBool(raw); err != nil {
+			return entity.TaskFilter{}, errors.New("invalid open flag")
+		}
+	}
+	if raw := c.Query("project"); raw != "" {
+		if filter.ProjectID, err = strconv.Atoi(raw); err != nil || filter.ProjectID <= 0 {
+			return entity.TaskFilter{}, errors.New("invalid project filter")
+		}
+	}
+	if raw := c.Query("due_after"); raw != "" {
+		if filter.DueFrom, err = time.Parse(time.RFC3339, raw); err != nil {
+			return entity.TaskFilter{}, errors.New("invalid due_after")
+		}
+	}
+	if raw := c.Query("due_before"); raw != "" {
+		if filter.DueTo, err = time.Parse(time.RFC3339, raw); err != nil {
+			return entity.TaskFilter{}, errors.New("invalid due_before")
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit <= 0 || filter.Limit > 200 {
+			return entity.TaskFilter{}, errors.New("invalid limit")
+		}
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := entity.DecodeTaskCursor(raw)
+		if err != nil {
+			return entity.TaskFilter{}, errors.New("invalid cursor")
+		}
+		if !cursor.Matches(filter.Order, filter.Desc) {
+			return entity.TaskFilter{}, errors.New("cursor does not match order")
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
 func (h *Handler) getTaskByID(c *gin.Context) {
 	userID, err := getUserId(c)
 	if err != nil {
@@ -62,12 +155,19 @@ func (h *Handler) getTaskByID(c *gin.Context) {
 
 	task, err := h.services.TaskList.GetTaskByID(userID, id)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
 			"error": "Could not get task by ID",
 		})
 		return
 	}
 
+	etag := taskETag(task.Version)
+	c.Header("ETag", etag)
+	if etagListed(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, task)
 }
 
@@ -89,7 +189,7 @@ func (h *Handler) createTask(c *gin.Context) {
 
 	id, err := h.services.TaskList.CreateTask(userID, req)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
 			"error": "Could not create task in database",
 		})
 		return
@@ -116,6 +216,14 @@ func (h *Handler) updateTask(c *gin.Context) {
 		})
 		return
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	var updatedDesc entity.TaskRequest
 	if err := c.BindJSON(&updatedDesc); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
@@ -124,9 +232,9 @@ func (h *Handler) updateTask(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TaskList.UpdateTask(userID, id, updatedDesc.Description)
+	err = h.services.TaskList.UpdateTask(userID, id, updatedDesc.Description, version)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
 			"error": "Could not update task in database",
 		})
 		return
@@ -137,12 +245,31 @@ func (h *Handler) updateTask(c *gin.Context) {
 	})
 }
 
-func (h *Handler) deleteTask(c *gin.Context) {
+// mergePatchType is the media type of RFC 7396 documents.
+const mergePatchType = "application/merge-patch+json"
+
+// @Summary Patch task
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description change any subset of description, status, due_at, remind_at, priority, project_id, parent_id and recurrence at once, null clears a field
+// @ID patch-task
+// @Accept  application/merge-patch+json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param If-Match header string false "ETag of the version the patch is based on"
+// @Param input body object true "JSON merge patch"
+// @Success 200 {object} entity.Task
+// @Failure 400,403,412,415 {string} string "error"
+// @Failure 422 {object} map[string]any "error and fields: field -> problem"
+// @Failure 500 {string} string "error"
+// @Router /api/{id} [patch]
+func (h *Handler) patchTask(c *gin.Context) {
 	userID, err := getUserId(c)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
+		return
 	}
 
 	id, err := strconv.Atoi(c.Param("id"))
@@ -152,10 +279,103 @@ func (h *Handler) deleteTask(c *gin.Context) {
 		})
 		return
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if c.ContentType() != mergePatchType {
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Content-Type must be " + mergePatchType,
+		})
+		return
+	}
+
+	doc, err := c.GetRawData()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not read request while patching task",
+		})
+		return
+	}
+
+	patch, err := entity.ParseTaskPatch(doc)
+	if err != nil {
+		if abortFieldErrors(c, err) {
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.services.TaskList.PatchTask(userID, id, patch, version); err != nil {
+		if abortFieldErrors(c, err) {
+			return
+		}
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not patch task in database",
+		})
+		return
+	}
+
+	task, err := h.services.TaskList.GetTaskByID(userID, id)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not get patched task",
+		})
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+// abortFieldErrors answers 422 with the field level report if err is one.
+func abortFieldErrors(c *gin.Context, err error) bool {
+	var fields entity.FieldErrors
+	if !errors.As(err, &fields) {
+		return false
+	}
 
-	err = h.services.TaskList.DeleteTask(userID, id)
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  "Invalid task fields",
+		"fields": fields,
+	})
+	return true
+}
+
+func (h *Handler) deleteTask(c *gin.Context) {
+	userID, err := getUserId(c)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = h.services.TaskList.DeleteTask(userID, id, version)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
 			"error": "Could not delete task in database",
 		})
 		return
@@ -165,3 +385,157 @@ func (h *Handler) deleteTask(c *gin.Context) {
 		"message": "deleted",
 	})
 }
+
+// @Summary Update task status
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description move task to another lifecycle state
+// @ID update-task-status
+// @Accept  json
+// @Produce  json
+// @Param id path int true "task id"
+// @Param input body entity.TaskStatusRequest true "new status"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/status [put]
+func (h *Handler) updateTaskStatus(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	var req entity.TaskStatusRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Could not unbind request while updating task status",
+		})
+		return
+	}
+
+	if !req.Status.IsValid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task status",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.UpdateTaskStatus(userID, id, req.Status); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not update task status in database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "status updated",
+	})
+}
+
+// @Summary Complete task
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description mark task as done, optionally with all its open subtasks; for a recurring task the next occurrence is created and its id is returned as next_id
+// @ID complete-task
+// @Produce  json
+// @Param id path int true "task id"
+// @Param subtasks query bool false "complete open subtasks of any depth too"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/complete [post]
+func (h *Handler) completeTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	withSubtasks := false
+	if raw := c.Query("subtasks"); raw != "" {
+		withSubtasks, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid subtasks flag",
+			})
+			return
+		}
+	}
+
+	nextID, err := h.services.TaskList.CompleteTask(userID, id, withSubtasks)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not complete task",
+		})
+		return
+	}
+
+	resp := gin.H{
+		"message": "completed",
+	}
+	if nextID != 0 {
+		resp["next_id"] = nextID // следующее повторение
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Reopen task
+// @Security ApiKeyAuth
+// @Tags tasks
+// @Description move done or cancelled task back to todo
+// @ID reopen-task
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/{id}/reopen [post]
+func (h *Handler) reopenTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	if err := h.services.TaskList.ReopenTask(userID, id); err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{
+			"error": "Could not reopen task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "reopened",
+	})
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/service"
+	mock_service "github.com/AronditFire/todo-app/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getTaskByID_ETag(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{name: "No Header", expectedStatus: 200},
+		{name: "Same Version", ifNoneMatch: `"3"`, expectedStatus: 304},
+		{name: "Weak Tag In List", ifNoneMatch: `"1", W/"3"`, expectedStatus: 304},
+		{name: "Old Version", ifNoneMatch: `"2"`, expectedStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tasks.EXPECT().GetTaskByID(1, 5).Return(entity.Task{ID: 5, Description: "test", Version: 3}, nil)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.GET("/api/:id", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.getTaskByID)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/5", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			if tt.expectedStatus == 304 {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+// Attaching a tag bumps the version of the task, a client holding the old
+// ETag gets the task with its new tags instead of 304.
+func TestHandler_getTaskByID_AfterTagAttached(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	tasks := mock_service.NewMockTaskList(c)
+	tags := mock_service.NewMockTags(c)
+	gomock.InOrder(
+		tasks.EXPECT().GetTaskByID(1, 5).Return(entity.Task{ID: 5, Description: "test", Version: 3}, nil),
+		tags.EXPECT().AttachTag(1, 5, 7).Return(nil),
+		tasks.EXPECT().GetTaskByID(1, 5).Return(entity.Task{ID: 5, Description: "test", Version: 4, Tags: []entity.Tag{{ID: 7, Name: "home"}}}, nil),
+	)
+
+	handler := NewHander(&service.Service{TaskList: tasks, Tags: tags})
+
+	r := gin.New()
+	setUser := func(c *gin.Context) { c.Set(userCtx, 1) }
+	r.GET("/api/:id", setUser, handler.getTaskByID)
+	r.POST("/api/:id/tags/:tag_id", setUser, handler.attachTag)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/5", nil))
+	assert.Equal(t, 200, w.Code)
+	etag := w.Header().Get("ETag")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/5/tags/7", nil))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/5", nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"home"`)
+}
+
+func TestHandler_updateTask_IfMatch(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		mock           func(s *mock_service.MockTaskList)
+		expectedStatus int
+	}{
+		{
+			name: "No Precondition",
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().UpdateTask(1, 5, "new", 0).Return(nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:    "Current Version",
+			ifMatch: `"3"`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().UpdateTask(1, 5, "new", 3).Return(nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:    "Changed Meanwhile",
+			ifMatch: `"2"`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().UpdateTask(1, 5, "new", 2).Return(entity.ErrVersionMismatch)
+			},
+			expectedStatus: 412,
+		},
+		{
+			name:           "Weak Tag",
+			ifMatch:        `W/"3"`,
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+		{
+			name:           "Malformed",
+			ifMatch:        `3`,
+			mock:           func(*mock_service.MockTaskList) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.PUT("/api/:id", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.updateTask)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/5", bytes.NewBufferString(`{"description":"new"}`))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_patchTask(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	description := "new"
+
+	tests := []struct {
+		name                 string
+		contentType          string
+		ifMatch              string
+		body                 string
+		mock                 func(s *mock_service.MockTaskList)
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Success",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"3"`,
+			body:        `{"description":"new","due_at":null}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().PatchTask(1, 5, entity.TaskPatch{
+					Description: entity.PatchField[string]{Set: true, Value: &description},
+					DueAt:       entity.PatchField[time.Time]{Set: true},
+				}, 3).Return(nil)
+				s.EXPECT().GetTaskByID(1, 5).Return(entity.Task{ID: 5, Description: "new", Version: 4}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:                 "Plain JSON",
+			contentType:          "application/json",
+			body:                 `{"description":"new"}`,
+			mock:                 func(*mock_service.MockTaskList) {},
+			expectedStatus:       415,
+			expectedResponseBody: `{"error":"Content-Type must be application/merge-patch+json"}`,
+		},
+		{
+			name:                 "Not An Object",
+			contentType:          "application/merge-patch+json",
+			body:                 `["description"]`,
+			mock:                 func(*mock_service.MockTaskList) {},
+			expectedStatus:       400,
+			expectedResponseBody: `{"error":"Merge patch must be a JSON object"}`,
+		},
+		{
+			name:                 "Unknown And Read-Only Fields",
+			contentType:          "application/merge-patch+json",
+			body:                 `{"colour":"red","id":7}`,
+			mock:                 func(*mock_service.MockTaskList) {},
+			expectedStatus:       422,
+			expectedResponseBody: `{"error":"Invalid task fields","fields":{"colour":"unknown field","id":"field is read-only"}}`,
+		},
+		{
+			name:        "Invalid Values",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			body:        `{"priority":9}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().PatchTask(1, 5, gomock.Any(), 0).
+					Return(fmt.Errorf("failed to patch task in repository: %w", entity.FieldErrors{"priority": "must be from 1 to 4"}))
+			},
+			expectedStatus:       422,
+			expectedResponseBody: `{"error":"Invalid task fields","fields":{"priority":"must be from 1 to 4"}}`,
+		},
+		{
+			name:        "Changed Meanwhile",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"2"`,
+			body:        `{"priority":1}`,
+			mock: func(s *mock_service.MockTaskList) {
+				s.EXPECT().PatchTask(1, 5, gomock.Any(), 2).Return(entity.ErrVersionMismatch)
+			},
+			expectedStatus: 412,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tasks := mock_service.NewMockTaskList(c)
+			tt.mock(tasks)
+
+			handler := NewHander(&service.Service{TaskList: tasks})
+
+			r := gin.New()
+			r.PATCH("/api/:id", func(c *gin.Context) { c.Set(userCtx, 1) }, handler.patchTask)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/api/5", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResponseBody != "" {
+				assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+			}
+			if tt.expectedStatus == 200 {
+				assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type GetTrashResponse struct {
+	Data []entity.Task `json:"data"`
+}
+
+// @Summary Get trash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description deleted tasks, recently deleted first; they are purged automatically after the retention period
+// @ID get-trash
+// @Produce  json
+// @Success 200 {object} GetTrashResponse
+// @Failure 500 {string} string "error"
+// @Router /api/trash [get]
+func (h *Handler) getTrash(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tasks, err := h.services.Trash.GetTrash(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not get trash for this user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetTrashResponse{
+		Data: tasks,
+	})
+}
+
+// @Summary Restore task
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description bring the task back with the subtasks deleted along with it; it becomes top level if its parent is still in the trash and goes to the inbox if its project was deleted
+// @ID restore-task
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/trash/{id}/restore [post]
+func (h *Handler) restoreTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	if err := h.services.Trash.RestoreTask(userID, id); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not restore task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "restored",
+	})
+}
+
+// @Summary Purge task
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description delete the trashed task and the subtasks deleted along with it for good
+// @ID purge-task
+// @Produce  json
+// @Param id path int true "task id"
+// @Success 200 {string} string "message"
+// @Failure 400 {string} string "error"
+// @Failure 500 {string} string "error"
+// @Router /api/trash/{id} [delete]
+func (h *Handler) purgeTask(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid task id",
+		})
+		return
+	}
+
+	if err := h.services.Trash.PurgeTask(userID, id); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not purge task",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "purged",
+	})
+}
+
+// @Summary Empty trash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description delete all trashed tasks for good
+// @ID empty-trash
+// @Produce  json
+// @Success 200 {string} string "message"
+// @Failure 500 {string} string "error"
+// @Router /api/trash [delete]
+func (h *Handler) emptyTrash(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.services.Trash.EmptyTrash(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Could not empty trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "emptied",
+	})
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// parseCSV reads the app's own table. The header names the columns in any
+// order, only title is required: title, due_date, priority (1-4), labels
+// (comma separated), completed, checklist (items separated by ";").
+// Other columns are ignored.
+func parseCSV(r io.Reader) ([]entity.ImportTask, []entity.ImportError, error) {
+	reader, columns, err := readHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, errors.New("CSV must have a title column")
+	}
+
+	var (
+		tasks []entity.ImportTask
+		errs  []entity.ImportError
+	)
+	err = eachRecord(reader, columns, &errs, func(line int, cell func(string) string) error {
+		item, err := csvTask(cell)
+		if err != nil {
+			return err
+		}
+		item.Row = line
+		tasks = append(tasks, item)
+		return nil
+	})
+
+	return tasks, errs, err
+}
+
+func csvTask(cell func(string) string) (entity.ImportTask, error) {
+	item := entity.ImportTask{
+		Task: entity.Task{Description: cell("title")},
+	}
+	if item.Task.Description == "" {
+		return item, errNoTitle
+	}
+
+	dueAt, err := parseDate(cell("due_date"), time.UTC)
+	if err != nil {
+		return item, err
+	}
+	item.Task.DueAt = dueAt
+
+	if raw := cell("priority"); raw != "" {
+		if item.Task.Priority, err = strconv.Atoi(raw); err != nil {
+			return item, fmt.Errorf("invalid priority %q", raw)
+		}
+	}
+
+	completed, err := parseCompleted(cell("completed"))
+	if err != nil {
+		return item, err
+	}
+	if completed {
+		item.Task.Status = entity.StatusDone
+	}
+
+	item.Labels = splitList(cell("labels"), ",")
+	for _, point := range splitList(cell("checklist"), ";") {
+		item.Subtasks = append(item.Subtasks, entity.Task{Description: point})
+	}
+
+	return item, nil
+}
+
+// readHeader reads the first record and maps lower case column names to their indexes.
+func readHeader(r io.Reader) (*csv.Reader, map[string]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel дописывает BOM в начало файла
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	return reader, columns, nil
+}
+
+// eachRecord calls fn for every data record with its line in the file and an
+// accessor of trimmed cells by column name. Errors of fn and broken records
+// are collected in errs, an error is returned only when reading can not go on.
+func eachRecord(reader *csv.Reader, columns map[string]int, errs *[]entity.ImportError, fn func(line int, cell func(string) string) error) error {
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			*errs = append(*errs, entity.ImportError{Row: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		cell := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if err := fn(line, cell); err != nil {
+			*errs = append(*errs, entity.ImportError{Row: line, Error: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// icsStatuses maps VTODO STATUS values onto task statuses.
+var icsStatuses = map[string]entity.TaskStatus{
+	"NEEDS-ACTION": entity.StatusTodo,
+	"IN-PROCESS":   entity.StatusInProgress,
+	"COMPLETED":    entity.StatusDone,
+	"CANCELLED":    entity.StatusCancelled,
+}
+
+var icsUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+var errNoVTODO = errors.New("calendar object has no VTODO")
+
+// ParseVTODO reads a calendar object a CalDAV client sends: a calendar with
+// one VTODO. It returns the task and the UID the client gave it. Only
+// alarms with an absolute time become the reminder, relative triggers and
+// properties the task has no field for are dropped.
+func ParseVTODO(r io.Reader) (entity.Task, string, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return entity.Task{}, "", err
+	}
+
+	var (
+		task  entity.Task
+		uid   string
+		stack []string // открытые компоненты, VCALENDAR > VTODO > VALARM
+		found bool
+	)
+	for _, line := range lines {
+		name, params, value, err := parseContentLine(line)
+		if err != nil {
+			return entity.Task{}, "", err
+		}
+
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if strings.EqualFold(value, "VTODO") {
+				if found {
+					return entity.Task{}, "", errors.New("calendar object has more than one VTODO")
+				}
+				found = true
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(value) {
+				return entity.Task{}, "", fmt.Errorf("unexpected END:%s", value)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		depth := len(stack)
+		switch {
+		case depth >= 2 && stack[depth-1] == "VTODO":
+			if err := setTodoProperty(&task, &uid, name, params, value); err != nil {
+				return entity.Task{}, "", fmt.Errorf("%s: %w", name, err)
+			}
+		case depth >= 3 && stack[depth-1] == "VALARM" && stack[depth-2] == "VTODO":
+			if name == "TRIGGER" && strings.EqualFold(params["VALUE"], "DATE-TIME") && task.RemindAt == nil {
+				if task.RemindAt, err = parseICSTime(value, params); err != nil {
+					return entity.Task{}, "", fmt.Errorf("TRIGGER: %w", err)
+				}
+			}
+		}
+	}
+
+	if !found {
+		return entity.Task{}, "", errNoVTODO
+	}
+	if len(stack) != 0 {
+		return entity.Task{}, "", fmt.Errorf("%s is not closed", stack[len(stack)-1])
+	}
+	if task.Description == "" {
+		return entity.Task{}, "", errNoTitle
+	}
+
+	return task, uid, nil
+}
+
+func setTodoProperty(task *entity.Task, uid *string, name string, params map[string]string, value string) error {
+	var err error
+	switch name {
+	case "UID":
+		*uid = icsUnescaper.Replace(value)
+	case "SUMMARY":
+		task.Description = strings.TrimSpace(icsUnescaper.Replace(value))
+	case "DUE":
+		task.DueAt, err = parseICSTime(value, params)
+	case "COMPLETED":
+		task.CompletedAt, err = parseICSTime(value, params)
+	case "STATUS":
+		status, ok := icsStatuses[strings.ToUpper(value)]
+		if !ok {
+			return fmt.Errorf("unknown status %q", value)
+		}
+		task.Status = status
+	case "PRIORITY":
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < 0 || priority > 9 {
+			return fmt.Errorf("invalid priority %q", value)
+		}
+		task.Priority = taskPriority(priority)
+	case "RRULE":
+		task.Recurrence = value
+	}
+	return err
+}
+
+// taskPriority maps the 0-9 scale of iCalendar onto P1-P4: 1 is P1, 2-4 P2,
+// 5 (medium) P3, 6-9 and 0 (undefined) P4.
+func taskPriority(priority int) int {
+	switch {
+	case priority == 1:
+		return 1
+	case priority >= 2 && priority <= 4:
+		return 2
+	case priority == 5:
+		return 3
+	}
+	return entity.PriorityLowest
+}
+
+// parseICSTime reads a DATE-TIME in UTC ("...Z"), with a TZID or floating
+// (taken as UTC), and a DATE (midnight UTC).
+func parseICSTime(value string, params map[string]string) (*time.Time, error) {
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	layout := "20060102T150405"
+	switch {
+	case strings.EqualFold(params["VALUE"], "DATE") || len(value) == 8:
+		layout = "20060102"
+	case strings.HasSuffix(value, "Z"):
+		layout, loc = "20060102T150405Z", time.UTC
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", value)
+	}
+	return &t, nil
+}
+
+// unfoldLines joins folded lines back: a line that starts with a space or a
+// tab continues the previous one (RFC 5545 3.1).
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits "NAME;PARAM=x;PARAM2="a:b":value". Names and
+// parameter names are upper cased, quotes around parameter values removed.
+func parseContentLine(line string) (string, map[string]string, string, error) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", fmt.Errorf("invalid content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, line[colon+1:], nil
+}
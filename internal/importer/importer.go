@@ -0,0 +1,79 @@
+// Package importer reads tasks from files exported by other tools. It only
+// maps external fields onto entity.ImportTask, validation of the tasks
+// themselves is left to the service.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// Parse reads the whole export. Rows that can not be mapped are reported
+// one by one and the rest is still returned, an error means the file as a
+// whole is unreadable.
+func Parse(format entity.ImportFormat, r io.Reader) ([]entity.ImportTask, []entity.ImportError, error) {
+	switch format {
+	case entity.ImportCSV:
+		return parseCSV(r)
+	case entity.ImportTodoist:
+		return parseTodoist(r)
+	case entity.ImportTrello:
+		return parseTrello(r)
+	}
+
+	return nil, nil, fmt.Errorf("unknown import format %q", format)
+}
+
+// dateLayouts are the due date formats accepted in CSV files, a date without
+// time means midnight.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseDate(raw string, loc *time.Location) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported date %q, use YYYY-MM-DD or RFC 3339", raw)
+}
+
+func parseCompleted(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "0", "false", "no", "todo":
+		return false, nil
+	case "1", "true", "yes", "done", "completed":
+		return true, nil
+	}
+
+	return false, fmt.Errorf("invalid completed value %q", raw)
+}
+
+// splitList cuts a cell like "work, home" into trimmed non empty items.
+func splitList(raw, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+var errNoTitle = errors.New("task has no title")
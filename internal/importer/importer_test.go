@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int, loc *time.Location) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+
+	tests := []struct {
+		name      string
+		format    entity.ImportFormat
+		input     string
+		wantTasks []entity.ImportTask
+		wantErrs  []entity.ImportError
+		wantErr   bool
+	}{
+		{
+			name:   "CSV",
+			format: entity.ImportCSV,
+			input: "\ufeffTitle,Due_Date,Priority,Labels,Completed,Checklist\n" +
+				"Buy milk,2025-05-06,2,\"home, shop\",no,Milk;Bread\n" +
+				",2025-05-06,,,,\n" +
+				"Pay rent,tomorrow,,,,\n" +
+				"Call mom,,,,yes,\n",
+			wantTasks: []entity.ImportTask{
+				{
+					Row:      2,
+					Task:     entity.Task{Description: "Buy milk", DueAt: date(2025, 5, 6, time.UTC), Priority: 2},
+					Labels:   []string{"home", "shop"},
+					Subtasks: []entity.Task{{Description: "Milk"}, {Description: "Bread"}},
+				},
+				{Row: 5, Task: entity.Task{Description: "Call mom", Status: entity.StatusDone}},
+			},
+			wantErrs: []entity.ImportError{
+				{Row: 3, Error: "task has no title"},
+				{Row: 4, Error: `unsupported date "tomorrow", use YYYY-MM-DD or RFC 3339`},
+			},
+		},
+		{
+			name:    "CSV Without Title",
+			format:  entity.ImportCSV,
+			input:   "name,due_date\nBuy milk,\n",
+			wantErr: true,
+		},
+		{
+			name:   "Todoist",
+			format: entity.ImportTodoist,
+			input: "TYPE,CONTENT,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+				"section,Errands,,,,,,,\n" +
+				"task,Buy milk @home,1,1,,,2025-05-06,en,Europe/Berlin\n" +
+				"task,Check price,4,2,,,,en,Europe/Berlin\n" +
+				"note,Remember the discount,,,,,,,\n" +
+				"task,Walk the dog,4,1,,,every day,en,Europe/Berlin\n" +
+				"task,Find the leash,4,2,,,,en,Europe/Berlin\n",
+			wantTasks: []entity.ImportTask{{
+				Row:      3,
+				Task:     entity.Task{Description: "Buy milk", DueAt: date(2025, 5, 6, berlin), Priority: 1},
+				Labels:   []string{"home"},
+				Subtasks: []entity.Task{{Description: "Check price", Priority: 4}},
+			}},
+			wantErrs: []entity.ImportError{
+				{Row: 6, Error: `unsupported date "every day", use YYYY-MM-DD or RFC 3339`},
+				{Row: 7, Error: "subtask has no imported parent task"},
+			},
+		},
+		{
+			name:   "Trello",
+			format: entity.ImportTrello,
+			input: `{
+				"lists": [{"id": "l1"}, {"id": "l2", "closed": true}],
+				"cards": [
+					{"id": "c1", "name": "Buy milk", "idList": "l1", "due": "2025-05-06T00:00:00Z", "dueComplete": true,
+					 "labels": [{"name": "home"}, {"name": "", "color": "red"}]},
+					{"id": "c2", "name": "Old card", "idList": "l1", "closed": true},
+					{"id": "c3", "name": "Archived list", "idList": "l2"},
+					{"id": "c4", "name": "", "idList": "l1"}
+				],
+				"checklists": [{"idCard": "c1", "checkItems": [
+					{"name": "Bread", "state": "incomplete", "pos": 2},
+					{"name": "Milk", "state": "complete", "pos": 1}
+				]}]
+			}`,
+			wantTasks: []entity.ImportTask{{
+				Row:    1,
+				Task:   entity.Task{Description: "Buy milk", Status: entity.StatusDone, DueAt: date(2025, 5, 6, time.UTC)},
+				Labels: []string{"home", "red"},
+				Subtasks: []entity.Task{
+					{Description: "Milk", Status: entity.StatusDone},
+					{Description: "Bread"},
+				},
+			}},
+			wantErrs: []entity.ImportError{{Row: 4, Error: "task has no title"}},
+		},
+		{
+			name:    "Trello Invalid JSON",
+			format:  entity.ImportTrello,
+			input:   `{"cards": [`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Format",
+			format:  "asana",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, errs, err := Parse(tt.format, strings.NewReader(tt.input))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantErrs, errs)
+
+			assert.Len(t, tasks, len(tt.wantTasks))
+			for i := range tt.wantTasks {
+				if i >= len(tasks) {
+					break
+				}
+				want, got := tt.wantTasks[i], tasks[i]
+				if want.Task.DueAt != nil {
+					assert.True(t, want.Task.DueAt.Equal(*got.Task.DueAt), "due %v, got %v", want.Task.DueAt, got.Task.DueAt)
+					assert.Equal(t, want.Task.DueAt.Location().String(), got.Task.DueAt.Location().String())
+					want.Task.DueAt, got.Task.DueAt = nil, nil
+				}
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+func TestParseVTODO(t *testing.T) {
+	due := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	remind := time.Date(2025, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		wantTask entity.Task
+		wantUID  string
+		wantErr  bool
+	}{
+		{
+			name: "Full",
+			input: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:abc-123\r\n" +
+				"SUMMARY:Buy milk\\, bread\r\n  and eggs\r\n" +
+				"DUE:20250506T120000Z\r\nSTATUS:IN-PROCESS\r\nPRIORITY:5\r\nRRULE:FREQ=WEEKLY\r\n" +
+				"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER;VALUE=DATE-TIME:20250506T090000Z\r\nEND:VALARM\r\n" +
+				"END:VTODO\r\nEND:VCALENDAR\r\n",
+			wantTask: entity.Task{
+				Description: "Buy milk, bread and eggs",
+				Status:      entity.StatusInProgress,
+				DueAt:       &due,
+				RemindAt:    &remind,
+				Priority:    3,
+				Recurrence:  "FREQ=WEEKLY",
+			},
+			wantUID: "abc-123",
+		},
+		{
+			name: "Relative Alarm Dropped",
+			input: "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:x\nSUMMARY:Call mom\n" +
+				"BEGIN:VALARM\nTRIGGER:-PT15M\nEND:VALARM\nEND:VTODO\nEND:VCALENDAR\n",
+			wantTask: entity.Task{Description: "Call mom"},
+			wantUID:  "x",
+		},
+		{
+			name:    "No VTODO",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Party\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "No Summary",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:x\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			wantErr: true,
+		},
+		{
+			name: "Two VTODO",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:a\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:b\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, uid, err := ParseVTODO(strings.NewReader(tt.input))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTask, task)
+			assert.Equal(t, tt.wantUID, uid)
+		})
+	}
+}
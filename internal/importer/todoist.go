@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// parseTodoist reads a project exported from Todoist as CSV (TYPE, CONTENT,
+// PRIORITY, INDENT, DATE, TIMEZONE, ...). Sections and notes are skipped.
+// Labels are the @words of CONTENT, tasks with INDENT above 1 become subtasks
+// of the last top level task; deeper levels are flattened. Only ISO dates
+// are imported, natural language dates like "every monday" are reported.
+func parseTodoist(r io.Reader) ([]entity.ImportTask, []entity.ImportError, error) {
+	reader, columns, err := readHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := columns["content"]; !ok {
+		return nil, nil, errors.New("Todoist export must have a CONTENT column")
+	}
+
+	var (
+		tasks []entity.ImportTask
+		errs  []entity.ImportError
+	)
+	parent := -1 // индекс последней задачи верхнего уровня в tasks, -1 - её нет или она не импортирована
+	err = eachRecord(reader, columns, &errs, func(line int, cell func(string) string) error {
+		if kind := strings.ToLower(cell("type")); kind != "" && kind != "task" {
+			return nil
+		}
+
+		indent := 1
+		if raw := cell("indent"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				parent = -1
+				return fmt.Errorf("invalid indent %q", raw)
+			}
+			indent = n
+		}
+
+		task, labels, err := todoistTask(cell)
+		if indent == 1 {
+			parent = -1
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, entity.ImportTask{Row: line, Task: task, Labels: labels})
+			parent = len(tasks) - 1
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+		if parent < 0 {
+			return errors.New("subtask has no imported parent task")
+		}
+		tasks[parent].Subtasks = append(tasks[parent].Subtasks, task)
+		return nil
+	})
+
+	return tasks, errs, err
+}
+
+func todoistTask(cell func(string) string) (entity.Task, []string, error) {
+	var (
+		words  []string
+		labels []string
+	)
+	for _, word := range strings.Fields(cell("content")) {
+		if len(word) > 1 && strings.HasPrefix(word, "@") {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+
+	task := entity.Task{Description: strings.Join(words, " ")}
+	if task.Description == "" {
+		return task, nil, errNoTitle
+	}
+
+	if raw := cell("priority"); raw != "" {
+		priority, err := strconv.Atoi(raw)
+		if err != nil {
+			return task, nil, fmt.Errorf("invalid priority %q", raw)
+		}
+		task.Priority = priority
+	}
+
+	loc := time.UTC
+	if tz := cell("timezone"); tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	dueAt, err := parseDate(cell("date"), loc)
+	if err != nil {
+		return task, nil, err
+	}
+	task.DueAt = dueAt
+
+	return task, labels, nil
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards      []trelloCard `json:"cards"`
+	Checklists []struct {
+		IDCard     string `json:"idCard"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"` // complete или incomplete
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+type trelloCard struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	IDList      string     `json:"idList"`
+	Due         *time.Time `json:"due"`
+	DueComplete bool       `json:"dueComplete"`
+	Closed      bool       `json:"closed"`
+	Labels      []struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+}
+
+// parseTrello reads a board exported from Trello as JSON. Every open card
+// becomes a task, archived cards and cards of archived lists are skipped.
+// A card with a completed due date is done, labels without a name are
+// imported by their color, checklist items become subtasks. Rows of the
+// report are positions of the cards in the export, starting with 1.
+func parseTrello(r io.Reader) ([]entity.ImportTask, []entity.ImportError, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, nil, fmt.Errorf("could not decode Trello board: %w", err)
+	}
+
+	closedLists := make(map[string]bool, len(board.Lists))
+	for _, list := range board.Lists {
+		closedLists[list.ID] = list.Closed
+	}
+
+	subtasks := make(map[string][]entity.Task)
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+
+		for _, item := range items {
+			task := entity.Task{Description: item.Name}
+			if item.State == "complete" {
+				task.Status = entity.StatusDone
+			}
+			subtasks[checklist.IDCard] = append(subtasks[checklist.IDCard], task)
+		}
+	}
+
+	var (
+		tasks []entity.ImportTask
+		errs  []entity.ImportError
+	)
+	for i, card := range board.Cards {
+		if card.Closed || closedLists[card.IDList] {
+			continue
+		}
+		if card.Name == "" {
+			errs = append(errs, entity.ImportError{Row: i + 1, Error: errNoTitle.Error()})
+			continue
+		}
+
+		item := entity.ImportTask{
+			Row:      i + 1,
+			Task:     entity.Task{Description: card.Name, DueAt: card.Due},
+			Subtasks: subtasks[card.ID],
+		}
+		if card.DueComplete {
+			item.Task.Status = entity.StatusDone
+		}
+		for _, label := range card.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			if name != "" {
+				item.Labels = append(item.Labels, name)
+			}
+		}
+
+		tasks = append(tasks, item)
+	}
+
+	return tasks, errs, nil
+}
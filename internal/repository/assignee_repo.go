@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AssigneeRepo struct {
+	db *gorm.DB
+}
+
+func NewAssigneeRepo(db *gorm.DB) *AssigneeRepo {
+	return &AssigneeRepo{db: db}
+}
+
+func (r *AssigneeRepo) GetAssignees(userID, taskID int) ([]entity.TaskAssignee, error) {
+	var assignees []entity.TaskAssignee
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", taskID).Order("user_id").Find(&assignees).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return assignees, tx.Commit().Error
+}
+
+// SetAssignees replaces assignees of the task and records the change in
+// history. Every assignee must be a member of the task's project, otherwise
+// the result is entity.FieldErrors.
+func (r *AssigneeRepo) SetAssignees(userID, taskID int, userIDs []int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	// строка задачи заблокирована: перенос в другой проект дождётся записи
+	// и снимет исполнителей, которые в новом проекте не участвуют
+	task, err := lockTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after := slices.Compact(slices.Sorted(slices.Values(userIDs)))
+	if after == nil {
+		after = []int{}
+	}
+
+	if len(after) > 0 {
+		if err := checkAssignees(tx, task, after); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	before := []int{}
+	if err := tx.Model(&entity.TaskAssignee{}).Where("task_id = ?", task.ID).
+		Order("user_id").Pluck("user_id", &before).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if slices.Equal(before, after) {
+		return tx.Commit().Error
+	}
+
+	// оставшиеся исполнители не трогаем, чтобы сохранить дату назначения
+	query := tx.Where("task_id = ?", task.ID)
+	if len(after) > 0 {
+		query = query.Where("user_id NOT IN ?", after)
+	}
+	if err := query.Delete(&entity.TaskAssignee{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(after) > 0 {
+		assignees := make([]entity.TaskAssignee, 0, len(after))
+		for _, id := range after {
+			assignees = append(assignees, entity.TaskAssignee{TaskID: task.ID, UserID: id})
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignees).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := recordAssignees(tx, userID, task.ID, before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetAssignedTasks returns tasks assigned to the user in all projects,
+// nearest due date first. Tasks the user lost access to are skipped.
+func (r *AssigneeRepo) GetAssignedTasks(userID int) ([]entity.Task, error) {
+	var tasks []entity.Task
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(withCommentCountSQL).Preload("Tags").
+		Where("id IN (SELECT task_id FROM task_assignees WHERE user_id = ?)", userID).
+		Where(visibleTasksSQL, userID).
+		Order("due_at IS NULL, due_at, position, id").
+		Find(&tasks).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tasks, tx.Commit().Error
+}
+
+// checkAssignees makes sure every user is a member of the task's project.
+// The project row stays locked until the end of the transaction, so nobody
+// loses access to the project between the check and the write.
+func checkAssignees(tx *gorm.DB, task entity.Task, userIDs []int) error {
+	if task.ProjectID == nil {
+		return entity.FieldErrors{"user_ids": "only tasks in a project can be assigned"}
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&entity.Project{}, *task.ProjectID).Error; err != nil {
+		return err
+	}
+
+	var members []int
+	if err := tx.Raw("SELECT DISTINCT user_id FROM project_access WHERE project_id = ? AND user_id IN ?",
+		*task.ProjectID, userIDs).Scan(&members).Error; err != nil {
+		return err
+	}
+
+	for _, id := range userIDs {
+		if !slices.Contains(members, id) {
+			return entity.FieldErrors{"user_ids": fmt.Sprintf("user %d is not a member of the project", id)}
+		}
+	}
+
+	return nil
+}
+
+// dropStaleAssignees unassigns users who are not members of the project of
+// the tasks matching the query anymore, tasks without a project lose all
+// assignees. The query is about tasks aliased as t.
+func dropStaleAssignees(tx *gorm.DB, query string, args ...any) error {
+	return tx.Exec(`DELETE FROM task_assignees a USING tasks t
+		WHERE a.task_id = t.id AND (`+query+`)
+			AND (t.project_id IS NULL OR a.user_id NOT IN (SELECT user_id FROM project_access WHERE project_id = t.project_id))`,
+		args...).Error
+}
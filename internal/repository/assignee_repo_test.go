@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAssignees(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewAssigneeRepo(gormDB)
+
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`)
+	lockProject := regexp.QuoteMeta(`SELECT * FROM "projects" WHERE "projects"."id" = $1 ORDER BY "projects"."id" LIMIT $2 FOR SHARE`)
+	selectMembers := regexp.QuoteMeta(`SELECT DISTINCT user_id FROM project_access WHERE project_id = $1 AND user_id IN ($2,$3)`)
+	selectAssignees := regexp.QuoteMeta(`SELECT "user_id" FROM "task_assignees" WHERE task_id = $1 ORDER BY user_id`)
+
+	tests := []struct {
+		name     string
+		mock     func()
+		userIDs  []int
+		wantErr  bool
+		fieldErr entity.FieldErrors
+	}{
+		{
+			name: "Replace",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "project_id"}).AddRow(5, 1, 3))
+				mock.ExpectQuery(lockProject).WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 1))
+				mock.ExpectQuery(selectMembers).WithArgs(3, 1, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(3))
+				mock.ExpectQuery(selectAssignees).WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "task_assignees" WHERE task_id = $1 AND user_id NOT IN ($2,$3)`)).
+					WithArgs(5, 1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`INSERT INTO "task_assignees" ("task_id","user_id") VALUES ($1,$2),($3,$4) ON CONFLICT DO NOTHING RETURNING "created_at"`,
+				)).WithArgs(5, 1, 5, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()).AddRow(time.Now()))
+				mock.ExpectQuery(`INSERT INTO "task_histories"`).
+					WithArgs(5, 1, "assigned", []byte(`{"assignees":[2]}`), []byte(`{"assignees":[1,3]}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectCommit()
+			},
+			userIDs: []int{3, 1, 3},
+			wantErr: false,
+		},
+		{
+			name: "Nothing Changed",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "project_id"}).AddRow(5, 1, 3))
+				mock.ExpectQuery(lockProject).WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT user_id FROM project_access WHERE project_id = $1 AND user_id IN ($2)`)).
+					WithArgs(3, 2).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+				mock.ExpectQuery(selectAssignees).WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+				mock.ExpectCommit()
+			},
+			userIDs: []int{2},
+			wantErr: false,
+		},
+		{
+			name: "Not A Member",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "project_id"}).AddRow(5, 1, 3))
+				mock.ExpectQuery(lockProject).WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 1))
+				mock.ExpectQuery(selectMembers).WithArgs(3, 1, 4).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			userIDs:  []int{4, 1},
+			wantErr:  true,
+			fieldErr: entity.FieldErrors{"user_ids": "user 4 is not a member of the project"},
+		},
+		{
+			name: "Task In Inbox",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, 1))
+				mock.ExpectRollback()
+			},
+			userIDs:  []int{1},
+			wantErr:  true,
+			fieldErr: entity.FieldErrors{"user_ids": "only tasks in a project can be assigned"},
+		},
+		{
+			name: "Viewer Can Not Assign",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectRollback()
+			},
+			userIDs: []int{1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetAssignees(1, 5, tt.userIDs)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.fieldErr != nil {
+					assert.Equal(t, tt.fieldErr, err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAssignedTasks(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewAssigneeRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_assignees WHERE user_id = $1) AND id IN (SELECT task_id FROM task_access_for($2)) AND "tasks"."deleted_at" IS NULL ORDER BY due_at IS NULL, due_at, position, id`,
+	)).WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "comment_count"}).AddRow(5, "Review", 1, 3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`)).
+		WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+	mock.ExpectCommit()
+
+	tasks, err := r.GetAssignedTasks(2)
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, 1, tasks[0].UserID)
+	assert.Equal(t, 3, tasks[0].CommentCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
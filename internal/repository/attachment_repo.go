@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepo(db *gorm.DB) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+func (r *AttachmentRepo) GetAttachments(userID, taskID int) ([]entity.Attachment, error) {
+	var attachments []entity.Attachment
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", taskID).Order("id").Find(&attachments).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return attachments, tx.Commit().Error
+}
+
+func (r *AttachmentRepo) GetAttachment(userID, taskID, attachmentID int) (entity.Attachment, error) {
+	var attachment entity.Attachment
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return entity.Attachment{}, err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return entity.Attachment{}, err
+	}
+
+	if err := tx.Where("task_id = ? AND id = ?", taskID, attachmentID).First(&attachment).Error; err != nil {
+		tx.Rollback()
+		return entity.Attachment{}, err
+	}
+
+	return attachment, tx.Commit().Error
+}
+
+// CreateAttachment records a blob that is already in the storage.
+func (r *AttachmentRepo) CreateAttachment(userID, taskID int, attachment entity.Attachment) (int, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	attachment.TaskID = task.ID
+	attachment.UserID = userID
+
+	if err := tx.Create(&attachment).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return attachment.ID, tx.Commit().Error
+}
+
+// DeleteAttachment removes the row and queues its blob for deletion.
+func (r *AttachmentRepo) DeleteAttachment(userID, taskID, attachmentID int) error {
+	var attachment entity.Attachment
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleEditor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("task_id = ? AND id = ?", taskID, attachmentID).First(&attachment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&entity.PurgedBlob{StorageKey: attachment.StorageKey}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(&attachment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetPurgedBlobs returns up to limit blobs waiting for deletion, oldest first.
+func (r *AttachmentRepo) GetPurgedBlobs(limit int) ([]entity.PurgedBlob, error) {
+	var blobs []entity.PurgedBlob
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Order("created_at, storage_key").Limit(limit).Find(&blobs).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return blobs, tx.Commit().Error
+}
+
+// ForgetPurgedBlobs drops blobs that are already gone from the storage.
+func (r *AttachmentRepo) ForgetPurgedBlobs(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("storage_key IN ?", keys).Delete(&entity.PurgedBlob{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// queuePurgedBlobs queues blobs of the tasks matching the query, purgeWhere
+// calls it before the attachment rows are deleted.
+func queuePurgedBlobs(tx *gorm.DB, query string, args ...any) error {
+	return tx.Exec(`INSERT INTO purged_blobs (storage_key)
+		SELECT storage_key FROM attachments WHERE task_id IN (SELECT id FROM tasks WHERE `+query+`)
+		ON CONFLICT DO NOTHING`, args...).Error
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAttachment(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewAttachmentRepo(gormDB)
+
+	selectAttachment := regexp.QuoteMeta(
+		`SELECT * FROM "attachments" WHERE task_id = $1 AND id = $2 ORDER BY "attachments"."id" LIMIT $3`,
+	)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Blob Queued",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectQuery(selectAttachment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "storage_key"}).AddRow(7, 5, "tasks/5/abc"))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`INSERT INTO "purged_blobs" ("storage_key") VALUES ($1) RETURNING "created_at"`,
+				)).WithArgs("tasks/5/abc").
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "attachments" WHERE "attachments"."id" = $1`)).
+					WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Viewer Can Not Delete",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteAttachment(1, 5, 7)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -81,3 +81,27 @@ func (r *AuthRepo) GetUserByID(id int) (entity.User, error) {
 
 	return user, tx.Commit().Error
 }
+
+// GetProjectMembers returns the owner of the project and everybody it is shared with.
+func (r *AuthRepo) GetProjectMembers(projectID int) ([]entity.User, error) {
+	var users []entity.User
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("id IN (SELECT user_id FROM project_access WHERE project_id = ?)", projectID).
+		Order("id").Find(&users).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return users, tx.Commit().Error
+}
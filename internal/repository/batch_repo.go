@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// batchSavepoint is set before every operation of a best effort batch.
+const batchSavepoint = "batch_op"
+
+// TaskOp is one write of TaskRepo.Batch, prepared and validated by the service.
+type TaskOp struct {
+	Op      entity.BatchOp
+	TaskID  int                           // update, delete, complete
+	Version int                           // update, delete, complete: как If-Match, 0 - без проверки
+	Task    entity.Task                   // create
+	Object  *entity.CalendarObject        // create: имя и UID от клиента CalDAV, nil - без них
+	Apply   func(task *entity.Task) error // update, как в PatchTask
+	// complete: следующее повторение, вызывается с заблокированной строкой
+	// и только пока у задачи есть правило
+	Next        func(task entity.Task) (*entity.Task, error)
+	CompletedAt time.Time // complete
+	Subtasks    bool      // complete: вместе со всеми открытыми подзадачами
+}
+
+// TaskOpResult is the outcome of one TaskOp. ID is the created task for
+// create and the next occurrence for complete.
+type TaskOpResult struct {
+	ID  int
+	Err error
+}
+
+// Batch runs the operations in one transaction. An atomic batch stops at the
+// first failed operation and rolls everything back, the returned results end
+// with that operation. Otherwise every operation gets its own savepoint: a
+// failed one is undone and the rest go on.
+func (r *TaskRepo) Batch(userID int, ops []TaskOp, atomic bool) ([]TaskOpResult, error) {
+	results := make([]TaskOpResult, 0, len(ops))
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		if !atomic {
+			if err := tx.SavePoint(batchSavepoint).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		id, err := runTaskOp(tx, userID, op)
+		results = append(results, TaskOpResult{ID: id, Err: err})
+		if err == nil {
+			continue
+		}
+
+		if atomic {
+			tx.Rollback()
+			return results, nil
+		}
+		if err := tx.RollbackTo(batchSavepoint).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return results, tx.Commit().Error
+}
+
+func runTaskOp(tx *gorm.DB, userID int, op TaskOp) (int, error) {
+	switch op.Op {
+	case entity.BatchCreate:
+		id, err := createTask(tx, userID, op.Task)
+		if err != nil || op.Object == nil {
+			return id, err
+		}
+
+		object := *op.Object
+		object.TaskID = id
+		return id, tx.Create(&object).Error
+	case entity.BatchUpdate:
+		return 0, patchTask(tx, userID, op.TaskID, op.Version, op.Apply)
+	case entity.BatchDelete:
+		return 0, deleteTask(tx, userID, op.TaskID, op.Version)
+	case entity.BatchComplete:
+		task, err := findTaskVersion(tx, userID, op.TaskID, entity.RoleEditor, op.Version)
+		if err != nil {
+			return 0, err
+		}
+
+		if op.Subtasks {
+			if err := completeSubtasks(tx, userID, task, op.CompletedAt); err != nil {
+				return 0, err
+			}
+		}
+
+		// правило проверяется на заблокированной строке: одновременное
+		// выполнение ждёт здесь и видит, что правило уже ушло к следующей
+		var next *entity.Task
+		if task.Recurrence != "" {
+			if next, err = op.Next(task); err != nil {
+				return 0, err
+			}
+		}
+
+		return completeOccurrence(tx, userID, task, op.CompletedAt, next)
+	}
+
+	return 0, errors.New("unknown batch operation")
+}
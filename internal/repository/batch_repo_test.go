@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBatch(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	// выполнение обычной задачи: проверка доступа, блокировка и сохранение строки
+	expectComplete := func(taskID int) {
+		expectTaskRole(mock, 1, taskID, entity.RoleOwner)
+		mock.ExpectQuery(selectTask+" FOR UPDATE").WithArgs(taskID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(taskID, "Test Task", 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO "task_histories"`).WithArgs(taskID, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+	complete := func(taskID int) TaskOp {
+		return TaskOp{Op: entity.BatchComplete, TaskID: taskID, CompletedAt: now}
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		ops     []TaskOp
+		atomic  bool
+		wantErr []error
+	}{
+		{
+			name: "Atomic Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectComplete(1)
+				expectComplete(2)
+				mock.ExpectCommit()
+			},
+			ops:     []TaskOp{complete(1), complete(2)},
+			atomic:  true,
+			wantErr: []error{nil, nil},
+		},
+		{
+			name: "Atomic Stops At Failure",
+			mock: func() {
+				mock.ExpectBegin()
+				expectComplete(1)
+				expectTaskRole(mock, 1, 2, "")
+				mock.ExpectRollback()
+			},
+			ops:     []TaskOp{complete(1), complete(2), complete(3)},
+			atomic:  true,
+			wantErr: []error{nil, gorm.ErrRecordNotFound},
+		},
+		{
+			name: "Best Effort Skips Failure",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectComplete(1)
+				mock.ExpectExec("SAVEPOINT batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectTaskRole(mock, 1, 2, "")
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("SAVEPOINT batch_op").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectComplete(3)
+				mock.ExpectCommit()
+			},
+			ops:     []TaskOp{complete(1), complete(2), complete(3)},
+			wantErr: []error{nil, gorm.ErrRecordNotFound, nil},
+		},
+		{
+			name: "Complete Stale Version",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(selectTask+" FOR UPDATE").WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "version"}).AddRow(1, "Test Task", 1, 3))
+				mock.ExpectRollback()
+			},
+			ops:     []TaskOp{{Op: entity.BatchComplete, TaskID: 1, Version: 2, CompletedAt: now}},
+			atomic:  true,
+			wantErr: []error{entity.ErrVersionMismatch},
+		},
+		{
+			name: "Create With Calendar Object",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks"`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectQuery(`INSERT INTO "tasks"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+				mock.ExpectQuery(`INSERT INTO "task_histories"`).WithArgs(9, 1, "created", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				// имя пишется в той же транзакции, что и задача
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "calendar_objects" ("task_id","user_id","name","uid") VALUES ($1,$2,$3,$4)`)).
+					WithArgs(9, 1, "abc.ics", "abc").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ops: []TaskOp{{
+				Op:     entity.BatchCreate,
+				Task:   entity.Task{Description: "Buy milk", Status: entity.StatusTodo, Priority: 4},
+				Object: &entity.CalendarObject{UserID: 1, Name: "abc.ics", UID: "abc"},
+			}},
+			atomic:  true,
+			wantErr: []error{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			results, err := r.Batch(1, tt.ops, tt.atomic)
+
+			assert.NoError(t, err)
+			errs := make([]error, len(results))
+			for i, res := range results {
+				errs[i] = res.Err
+			}
+			assert.Equal(t, tt.wantErr, errs)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type CalDAVRepo struct {
+	db *gorm.DB
+}
+
+func NewCalDAVRepo(db *gorm.DB) *CalDAVRepo {
+	return &CalDAVRepo{db: db}
+}
+
+func (r *CalDAVRepo) CreateAppPassword(password entity.AppPassword) (int, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Create(&password).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return password.ID, tx.Commit().Error
+}
+
+func (r *CalDAVRepo) GetAppPasswords(userID int) ([]entity.AppPassword, error) {
+	var passwords []entity.AppPassword
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Order("id").Find(&passwords).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return passwords, tx.Commit().Error
+}
+
+func (r *CalDAVRepo) DeleteAppPassword(userID, passwordID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	res := tx.Delete(&entity.AppPassword{}, "id = ? AND user_id = ?", passwordID, userID)
+	if res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	return tx.Commit().Error
+}
+
+// GetAppPasswordUser finds the user who signs in with the name and the hash
+// of an app password.
+func (r *CalDAVRepo) GetAppPasswordUser(username, hash string) (int, error) {
+	var password entity.AppPassword
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	err := tx.Joins("JOIN users ON users.id = app_passwords.user_id").
+		Where("app_passwords.hash = ? AND users.username = ?", hash, username).
+		First(&password).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, entity.ErrUnauthorized
+		}
+		return 0, err
+	}
+
+	return password.UserID, tx.Commit().Error
+}
+
+// GetCalendarObjects returns the resources the user's CalDAV clients created.
+func (r *CalDAVRepo) GetCalendarObjects(userID int) ([]entity.CalendarObject, error) {
+	var objects []entity.CalendarObject
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Find(&objects).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return objects, tx.Commit().Error
+}
+
+// FindCalendarObject resolves a resource name of the user's calendar: the
+// object a client of the user created under the name or, when taskID is not
+// 0, the task with that id unless a client of the user named it otherwise.
+// The latter comes back with TaskID and UserID only. The task must be
+// visible to the user and out of the trash. Returns nil when nothing matches.
+func (r *CalDAVRepo) FindCalendarObject(userID int, name string, taskID int) (*entity.CalendarObject, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	var objects []entity.CalendarObject
+	if err := tx.Where("user_id = ? AND (name = ? OR task_id = ?)", userID, name, taskID).Find(&objects).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var found *entity.CalendarObject
+	for i := range objects {
+		if objects[i].Name == name {
+			found = &objects[i]
+		}
+	}
+	if found == nil {
+		// у задачи, которой клиент дал своё имя, другого имени нет
+		if taskID == 0 || len(objects) > 0 {
+			return nil, tx.Commit().Error
+		}
+		found = &entity.CalendarObject{TaskID: taskID, UserID: userID}
+	}
+
+	var visible int64
+	if err := tx.Model(&entity.Task{}).Where("id = ?", found.TaskID).Where(visibleTasksSQL, userID).
+		Count(&visible).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if visible == 0 {
+		found = nil
+	}
+
+	return found, tx.Commit().Error
+}
+
+// HasCalendarUID tells whether a client of the user already created an
+// object with the UID.
+func (r *CalDAVRepo) HasCalendarUID(userID int, uid string) (bool, error) {
+	var count int64
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return false, err
+	}
+
+	if err := tx.Model(&entity.CalendarObject{}).Where("user_id = ? AND uid = ?", userID, uid).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return count > 0, tx.Commit().Error
+}
+
+// GetRemovedCalendarObjects returns the objects of the user's clients whose
+// tasks went to the trash since the token. Only TaskID, UserID and Name are
+// kept.
+func (r *CalDAVRepo) GetRemovedCalendarObjects(userID int, since entity.SyncToken) ([]entity.CalendarObject, error) {
+	var objects []entity.CalendarObject
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	err := tx.Raw(`SELECT task_id, user_id, name FROM calendar_object_removals WHERE user_id = ? AND xid >= ? ORDER BY id`,
+		userID, since).Scan(&objects).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return objects, tx.Commit().Error
+}
+
+// MigrateCalDAV ties calendar objects to their tasks. A purged task takes its
+// object with it through the foreign key. A task going to the trash gives
+// its name up at once, so a client may create a new object under it, and
+// leaves a row in calendar_object_removals: sync-collection reports the
+// name as removed, the task is no longer there to tell it.
+func MigrateCalDAV(db *gorm.DB) error {
+	statements := []string{
+		// имена задач, которые уже в корзине или стёрты, освобождаются, как это делает триггер
+		`DELETE FROM calendar_objects o
+			WHERE NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = o.task_id AND t.deleted_at IS NULL)`,
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_calendar_objects_task') THEN
+				ALTER TABLE calendar_objects ADD CONSTRAINT fk_calendar_objects_task
+					FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE;
+			END IF;
+		END
+		$$`,
+
+		`CREATE TABLE IF NOT EXISTS calendar_object_removals (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL,
+			task_id bigint NOT NULL,
+			name varchar(255) NOT NULL,
+			xid bigint NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_calendar_object_removals_user_xid ON calendar_object_removals (user_id, xid)`,
+
+		`CREATE OR REPLACE FUNCTION remove_trashed_calendar_objects() RETURNS trigger AS $$
+		BEGIN
+			WITH removed AS (
+				DELETE FROM calendar_objects
+				WHERE task_id IN (SELECT id FROM changed WHERE deleted_at IS NOT NULL)
+				RETURNING user_id, task_id, name
+			)
+			INSERT INTO calendar_object_removals (user_id, task_id, name, xid)
+			SELECT user_id, task_id, name, ` + currentXIDSQL + ` FROM removed;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_calendar_objects_trash AFTER UPDATE ON tasks
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION remove_trashed_calendar_objects()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
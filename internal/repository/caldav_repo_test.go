@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetAppPasswordUser(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCalDAVRepo(gormDB)
+
+	selectPassword := regexp.QuoteMeta(`SELECT "app_passwords"."id","app_passwords"."user_id","app_passwords"."name","app_passwords"."hash","app_passwords"."created_at" FROM "app_passwords" JOIN users ON users.id = app_passwords.user_id WHERE app_passwords.hash = $1 AND users.username = $2`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantID  int
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectPassword).WithArgs("hash", "alice", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 7))
+				mock.ExpectCommit()
+			},
+			wantID: 7,
+		},
+		{
+			name: "Wrong Password",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectPassword).WithArgs("hash", "alice", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			id, err := r.GetAppPasswordUser("alice", "hash")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, id)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteAppPassword(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCalDAVRepo(gormDB)
+
+	deletePassword := regexp.QuoteMeta(`DELETE FROM "app_passwords" WHERE id = $1 AND user_id = $2`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(deletePassword).WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Someone Else's",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(deletePassword).WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(deletePassword).WithArgs(3, 1).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := r.DeleteAppPassword(1, 3)
+
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFindCalendarObject(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCalDAVRepo(gormDB)
+
+	selectObjects := regexp.QuoteMeta(`SELECT * FROM "calendar_objects" WHERE user_id = $1 AND (name = $2 OR task_id = $3)`)
+	countVisible := regexp.QuoteMeta(
+		`SELECT count(*) FROM "tasks" WHERE id = $1 AND id IN (SELECT task_id FROM task_access_for($2)) AND "tasks"."deleted_at" IS NULL`,
+	)
+	objectRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"task_id", "user_id", "name", "uid"}).AddRow(9, 1, "abc.ics", "abc")
+	}
+
+	tests := []struct {
+		name   string
+		object string
+		taskID int
+		mock   func()
+		want   *entity.CalendarObject
+	}{
+		{
+			name:   "Named By Client",
+			object: "abc.ics",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectObjects).WithArgs(1, "abc.ics", 0).WillReturnRows(objectRows())
+				mock.ExpectQuery(countVisible).WithArgs(9, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			want: &entity.CalendarObject{TaskID: 9, UserID: 1, Name: "abc.ics", UID: "abc"},
+		},
+		{
+			name:   "Default Name",
+			object: "task-5.ics",
+			taskID: 5,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectObjects).WithArgs(1, "task-5.ics", 5).WillReturnRows(sqlmock.NewRows([]string{"task_id"}))
+				mock.ExpectQuery(countVisible).WithArgs(5, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			want: &entity.CalendarObject{TaskID: 5, UserID: 1},
+		},
+		{
+			name:   "Default Name Of A Named Task",
+			object: "task-9.ics",
+			taskID: 9,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectObjects).WithArgs(1, "task-9.ics", 9).WillReturnRows(objectRows())
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Task Not Visible",
+			object: "abc.ics",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectObjects).WithArgs(1, "abc.ics", 0).WillReturnRows(objectRows())
+				mock.ExpectQuery(countVisible).WithArgs(9, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			object, err := r.FindCalendarObject(1, tt.object, tt.taskID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, object)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetRemovedCalendarObjects(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCalDAVRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT task_id, user_id, name FROM calendar_object_removals WHERE user_id = $1 AND xid >= $2 ORDER BY id`)).
+		WithArgs(1, 40).WillReturnRows(sqlmock.NewRows([]string{"task_id", "user_id", "name"}).AddRow(7, 1, "milk.ics"))
+	mock.ExpectCommit()
+
+	objects, err := r.GetRemovedCalendarObjects(1, 40)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.CalendarObject{{TaskID: 7, UserID: 1, Name: "milk.ics"}}, objects)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
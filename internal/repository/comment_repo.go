@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// withCommentCountSQL selects tasks together with the size of their threads
+// into Task.CommentCount.
+const withCommentCountSQL = "tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count"
+
+type CommentRepo struct {
+	db *gorm.DB
+}
+
+func NewCommentRepo(db *gorm.DB) *CommentRepo {
+	return &CommentRepo{db: db}
+}
+
+// GetComments returns up to limit comments of the task after the comment
+// afterID, oldest first. Threads of tasks in the trash can still be read.
+func (r *CommentRepo) GetComments(userID, taskID, afterID, limit int) ([]entity.Comment, error) {
+	var comments []entity.Comment
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query := tx.Where("task_id = ?", taskID)
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Order("id").Find(&comments).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return comments, tx.Commit().Error
+}
+
+// CreateComment adds a comment to the thread, viewers can only read it.
+func (r *CommentRepo) CreateComment(userID, taskID int, body string) (int, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	comment := entity.Comment{TaskID: task.ID, UserID: userID, Body: body}
+	if err := tx.Create(&comment).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return comment.ID, tx.Commit().Error
+}
+
+// UpdateComment changes the text, only the author can do it.
+func (r *CommentRepo) UpdateComment(userID, taskID, commentID int, body string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	comment, err := findComment(tx, userID, taskID, commentID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if comment.UserID != userID {
+		tx.Rollback()
+		return entity.ErrForbidden
+	}
+
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+
+	if err := tx.Save(&comment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteComment removes a comment of the user or, for the owner of the task, any comment.
+func (r *CommentRepo) DeleteComment(userID, taskID, commentID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	comment, err := findComment(tx, userID, taskID, commentID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if comment.UserID != userID {
+		if err := requireTaskRole(tx, userID, taskID, entity.RoleOwner); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Delete(&comment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// findComment loads a comment of a task the user can see.
+func findComment(tx *gorm.DB, userID, taskID, commentID int) (entity.Comment, error) {
+	var comment entity.Comment
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		return entity.Comment{}, err
+	}
+
+	err := tx.Where("task_id = ? AND id = ?", taskID, commentID).First(&comment).Error
+
+	return comment, err
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetComments(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCommentRepo(gormDB)
+
+	mock.ExpectBegin()
+	expectTaskRole(mock, 1, 5, entity.RoleViewer)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "comments" WHERE task_id = $1 AND id > $2 ORDER BY id LIMIT $3`,
+	)).WithArgs(5, 10, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "body"}).
+			AddRow(11, 5, 2, "first").
+			AddRow(12, 5, 1, "second"))
+	mock.ExpectCommit()
+
+	comments, err := r.GetComments(1, 5, 10, 3)
+
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, 11, comments[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateComment(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCommentRepo(gormDB)
+
+	selectComment := regexp.QuoteMeta(
+		`SELECT * FROM "comments" WHERE task_id = $1 AND id = $2 ORDER BY "comments"."id" LIMIT $3`,
+	)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Author",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectQuery(selectComment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "body"}).AddRow(7, 5, 1, "old"))
+				mock.ExpectExec(`UPDATE "comments" SET .*"body"=\$3.*"edited_at"=\$5 WHERE "id" = \$6`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not Author",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectComment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "body"}).AddRow(7, 5, 2, "old"))
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateComment(1, 5, 7, "new")
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewCommentRepo(gormDB)
+
+	selectComment := regexp.QuoteMeta(
+		`SELECT * FROM "comments" WHERE task_id = $1 AND id = $2 ORDER BY "comments"."id" LIMIT $3`,
+	)
+	deleteComment := regexp.QuoteMeta(`DELETE FROM "comments" WHERE "comments"."id" = $1`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Author",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectQuery(selectComment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id"}).AddRow(7, 5, 1))
+				mock.ExpectExec(deleteComment).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Task Owner",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectComment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id"}).AddRow(7, 5, 2))
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectExec(deleteComment).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Editor Can Not Delete Others",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectQuery(selectComment).WithArgs(5, 7, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id"}).AddRow(7, 5, 2))
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteComment(1, 5, 7)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
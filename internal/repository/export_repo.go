@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many tasks ExportTasks reads per query.
+const exportBatchSize = 500
+
+// ExportTasks calls fn for every task the user can see, ordered by id. Tasks
+// are read in batches from one snapshot of the database, so the whole list
+// is never held in memory and concurrent writes do not tear the export.
+func (r *TaskRepo) ExportTasks(userID int, fn func(task entity.Task) error) error {
+	tx := r.db.Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	var batch []entity.Task
+	err := tx.Select(withCommentCountSQL).Where(visibleTasksSQL, userID).Preload("Tags").
+		FindInBatches(&batch, exportBatchSize, func(_ *gorm.DB, _ int) error {
+			for _, task := range batch {
+				if err := fn(task); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTasks(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	selectTasks := regexp.QuoteMeta(`FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	stop := errors.New("client went away")
+
+	tests := []struct {
+		name    string
+		mock    func()
+		fn      func(task entity.Task) error
+		wantIDs []int
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTasks).WithArgs(1, exportBatchSize).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "A", 1).AddRow(2, "B", 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" IN ($1,$2)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int{1, 2},
+		},
+		{
+			name: "Writer Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTasks).WithArgs(1, exportBatchSize).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "A", 1).AddRow(2, "B", 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_tags"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectRollback()
+			},
+			fn:      func(entity.Task) error { return stop },
+			wantErr: stop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			var ids []int
+			fn := tt.fn
+			if fn == nil {
+				fn = func(task entity.Task) error {
+					ids = append(ids, task.ID)
+					return nil
+				}
+			}
+			err := r.ExportTasks(1, fn)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type FeedRepo struct {
+	db *gorm.DB
+}
+
+func NewFeedRepo(db *gorm.DB) *FeedRepo {
+	return &FeedRepo{db: db}
+}
+
+// SaveFeedToken issues the user a new feed token, the old one stops working.
+// The hash of the replaced token is returned, "" if there was none.
+func (r *FeedRepo) SaveFeedToken(userID int, tokenHash string) (string, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return "", err
+	}
+
+	old, err := deleteFeedToken(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Create(&entity.FeedToken{UserID: userID, TokenHash: tokenHash}).Error; err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return old, tx.Commit().Error
+}
+
+// DeleteFeedToken revokes the user's feed token and returns its hash, "" if
+// the user had none.
+func (r *FeedRepo) DeleteFeedToken(userID int) (string, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return "", err
+	}
+
+	old, err := deleteFeedToken(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return old, tx.Commit().Error
+}
+
+// GetFeedUser finds the owner of a feed token by its hash.
+func (r *FeedRepo) GetFeedUser(tokenHash string) (int, error) {
+	var token entity.FeedToken
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, entity.ErrFeedNotFound
+		}
+		return 0, err
+	}
+
+	return token.UserID, tx.Commit().Error
+}
+
+func deleteFeedToken(tx *gorm.DB, userID int) (string, error) {
+	var tokens []entity.FeedToken
+	if err := tx.Where("user_id = ?", userID).Find(&tokens).Error; err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	if err := tx.Delete(&entity.FeedToken{}, "user_id = ?", userID).Error; err != nil {
+		return "", err
+	}
+
+	return tokens[0].TokenHash, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestSaveFeedToken(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewFeedRepo(gormDB)
+
+	selectToken := regexp.QuoteMeta(`SELECT * FROM "feed_tokens" WHERE user_id = $1`)
+	deleteToken := regexp.QuoteMeta(`DELETE FROM "feed_tokens" WHERE user_id = $1`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantOld string
+		wantErr bool
+	}{
+		{
+			name: "Replaces Old Token",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectToken).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "token_hash"}).AddRow(1, "old"))
+				mock.ExpectExec(deleteToken).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "feed_tokens"`)).
+					WithArgs(1, "new", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantOld: "old",
+		},
+		{
+			name: "First Token",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectToken).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "token_hash"}))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "feed_tokens"`)).
+					WithArgs(1, "new", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Insert Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectToken).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "token_hash"}))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "feed_tokens"`)).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			old, err := r.SaveFeedToken(1, "new")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantOld, old)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetFeedUser(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewFeedRepo(gormDB)
+
+	selectToken := regexp.QuoteMeta(`SELECT * FROM "feed_tokens" WHERE token_hash = $1 ORDER BY "feed_tokens"."user_id" LIMIT $2`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectToken).WithArgs("hash", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "token_hash"}).AddRow(7, "hash"))
+	mock.ExpectCommit()
+
+	userID, err := r.GetFeedUser("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, userID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectToken).WithArgs("revoked", 1).WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectRollback()
+
+	_, err = r.GetFeedUser("revoked")
+	assert.ErrorIs(t, err, entity.ErrFeedNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type HistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepo(db *gorm.DB) *HistoryRepo {
+	return &HistoryRepo{db: db}
+}
+
+// GetTaskHistory returns changes of the task, oldest first.
+// History of a trashed task is still available.
+func (r *HistoryRepo) GetTaskHistory(userID, taskID int) ([]entity.TaskHistory, error) {
+	var history []entity.TaskHistory
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// доступ не зависит от deleted_at, так что история задачи из корзины тоже доступна
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", taskID).Order("id").Find(&history).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return history, tx.Commit().Error
+}
+
+// recordHistory writes history entries inside the caller's transaction,
+// so a change and its record are committed or rolled back together.
+func recordHistory(tx *gorm.DB, userID int, action entity.TaskAction, changes ...taskChange) error {
+	entries := make([]entity.TaskHistory, 0, len(changes))
+	for _, c := range changes {
+		before, after, err := c.diff()
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entity.TaskHistory{
+			TaskID: c.taskID(),
+			UserID: userID,
+			Action: action,
+			Before: before,
+			After:  after,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return tx.Create(&entries).Error
+}
+
+// recordAssignees writes a change of assignees, both lists are sorted user ids.
+func recordAssignees(tx *gorm.DB, userID, taskID int, before, after []int) error {
+	b, err := json.Marshal(map[string][]int{"assignees": before})
+	if err != nil {
+		return err
+	}
+	a, err := json.Marshal(map[string][]int{"assignees": after})
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entity.TaskHistory{
+		TaskID: taskID,
+		UserID: userID,
+		Action: entity.ActionAssigned,
+		Before: b,
+		After:  a,
+	}).Error
+}
+
+// taskChange is a task before and after a change, nil for a task that
+// did not exist yet or is already deleted.
+type taskChange struct {
+	before, after *entity.Task
+}
+
+func (c taskChange) taskID() int {
+	if c.after != nil {
+		return c.after.ID
+	}
+	return c.before.ID
+}
+
+// diff returns the task fields that differ between before and after.
+func (c taskChange) diff() (json.RawMessage, json.RawMessage, error) {
+	if c.before == nil || c.after == nil {
+		before, err := marshalTask(c.before)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := marshalTask(c.after)
+		return before, after, err
+	}
+
+	var before, after map[string]json.RawMessage
+	if err := remarshal(c.before, &before); err != nil {
+		return nil, nil, err
+	}
+	if err := remarshal(c.after, &after); err != nil {
+		return nil, nil, err
+	}
+
+	// omitempty поля могут быть только с одной стороны, их пустое значение - null
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			before[field] = json.RawMessage("null")
+		}
+	}
+	for field, old := range before {
+		updated, ok := after[field]
+		if !ok {
+			updated = json.RawMessage("null")
+		}
+		if bytes.Equal(old, updated) {
+			delete(before, field)
+			delete(after, field)
+			continue
+		}
+		after[field] = updated
+	}
+
+	b, err := json.Marshal(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := json.Marshal(after)
+	return b, a, err
+}
+
+func marshalTask(task *entity.Task) (json.RawMessage, error) {
+	if task == nil {
+		return nil, nil
+	}
+	return json.Marshal(task)
+}
+
+func remarshal(task *entity.Task, fields *map[string]json.RawMessage) error {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, fields)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskHistory(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewHistoryRepo(gormDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_histories" WHERE task_id = $1 ORDER BY id`)).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "action", "before", "after"}).
+						AddRow(1, 5, 1, "created", nil, []byte(`{"id":5}`)).
+						AddRow(2, 5, 1, "updated", []byte(`{"description":"a"}`), []byte(`{"description":"b"}`)))
+				mock.ExpectCommit()
+			},
+			wantLen: 2,
+			wantErr: false,
+		},
+		{
+			name: "Foreign Task",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, "")
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			history, err := r.GetTaskHistory(1, 5)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, history, tt.wantLen)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskChangeDiff(t *testing.T) {
+	dueAt := time.Date(2025, 5, 1, 18, 0, 0, 0, time.UTC)
+	created := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		change     taskChange
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name: "Only Changed Fields",
+			change: taskChange{
+				before: &entity.Task{ID: 1, Description: "old", Status: entity.StatusTodo, CreatedAt: created},
+				after:  &entity.Task{ID: 1, Description: "new", Status: entity.StatusTodo, CreatedAt: created},
+			},
+			wantBefore: `{"description":"old"}`,
+			wantAfter:  `{"description":"new"}`,
+		},
+		{
+			name: "Omitted Field Is Null",
+			change: taskChange{
+				before: &entity.Task{ID: 1, CreatedAt: created},
+				after:  &entity.Task{ID: 1, DueAt: &dueAt, CreatedAt: created},
+			},
+			wantBefore: `{"due_at":null}`,
+			wantAfter:  `{"due_at":"2025-05-01T18:00:00Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after, err := tt.change.diff()
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.wantBefore, string(before))
+			assert.JSONEq(t, tt.wantAfter, string(after))
+		})
+	}
+
+	t.Run("Created", func(t *testing.T) {
+		before, after, err := taskChange{after: &entity.Task{ID: 1, Description: "new"}}.diff()
+
+		assert.NoError(t, err)
+		assert.Nil(t, before)
+		assert.Contains(t, string(after), `"description":"new"`)
+	})
+}
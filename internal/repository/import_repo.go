@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// ImportTasks creates imported tasks with their subtasks in one transaction.
+// Labels are attached as tags of the user, missing tags are created. The
+// returned ids follow the order of tasks.
+func (r *TaskRepo) ImportTasks(userID int, tasks []entity.ImportTask) ([]int, error) {
+	ids := make([]int, 0, len(tasks))
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	tagIDs, err := ensureTags(tx, userID, tasks)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, item := range tasks {
+		id, err := createTask(tx, userID, item.Task)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		for _, label := range item.Labels {
+			if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", id, tagIDs[label]).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		for _, sub := range item.Subtasks {
+			sub.ParentID = &id
+			if _, err := createTask(tx, userID, sub); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, tx.Commit().Error
+}
+
+// ensureTags finds the user's tags named by the labels and creates the
+// missing ones, the result maps a name to the tag id.
+func ensureTags(tx *gorm.DB, userID int, tasks []entity.ImportTask) (map[string]int, error) {
+	var names []string
+	tagIDs := make(map[string]int)
+	for _, item := range tasks {
+		for _, label := range item.Labels {
+			if _, ok := tagIDs[label]; !ok {
+				tagIDs[label] = 0
+				names = append(names, label)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return tagIDs, nil
+	}
+
+	var existing []entity.Tag
+	if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range existing {
+		tagIDs[tag.Name] = tag.ID
+	}
+
+	for _, name := range names {
+		if tagIDs[name] != 0 {
+			continue
+		}
+		tag := entity.Tag{Name: name, UserID: userID}
+		if err := tx.Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		tagIDs[name] = tag.ID
+	}
+
+	return tagIDs, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTasks(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	selectTags := regexp.QuoteMeta(`SELECT * FROM "tags" WHERE user_id = $1 AND name IN ($2,$3)`)
+	tasks := []entity.ImportTask{{
+		Row:    1,
+		Task:   entity.Task{Description: "Test Task", Status: entity.StatusTodo, Priority: entity.PriorityLowest},
+		Labels: []string{"work", "home"},
+	}}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantIDs []int
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTags).WithArgs(1, "work", "home").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(3, "work", 1))
+				mock.ExpectQuery(`INSERT INTO "tags"`).WithArgs("home", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks"`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectQuery(`INSERT INTO "tasks"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery(`INSERT INTO "task_histories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`)).
+					WithArgs(7, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`)).
+					WithArgs(7, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int{7},
+		},
+		{
+			name: "Tags Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTags).WithArgs(1, "work", "home").WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Insert Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTags).WithArgs(1, "work", "home").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(3, "work", 1).AddRow(4, "home", 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks"`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectQuery(`INSERT INTO "tasks"`).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			ids, err := r.ImportTasks(1, tasks)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
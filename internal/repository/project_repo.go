@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type ProjectRepo struct {
+	db *gorm.DB
+}
+
+func NewProjectRepo(db *gorm.DB) *ProjectRepo {
+	return &ProjectRepo{db: db}
+}
+
+func (r *ProjectRepo) CreateProject(userID int, project entity.Project) (int, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	project.UserID = userID
+
+	if err := tx.Create(&project).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return project.ID, tx.Commit().Error
+}
+
+func (r *ProjectRepo) GetAllProjects(userID int, withArchived bool) ([]entity.Project, error) {
+	var projects []entity.Project
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	query := tx.Where("id IN (SELECT project_id FROM project_access WHERE user_id = ?)", userID)
+	if !withArchived {
+		query = query.Where("archived = ?", false)
+	}
+
+	if err := query.Order("id").Find(&projects).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return projects, tx.Commit().Error
+}
+
+func (r *ProjectRepo) GetProjectByID(userID, projectID int) (entity.Project, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return entity.Project{}, err
+	}
+
+	project, err := findProject(tx, userID, projectID, entity.RoleViewer)
+	if err != nil {
+		tx.Rollback()
+		return entity.Project{}, err
+	}
+
+	return project, tx.Commit().Error
+}
+
+func (r *ProjectRepo) UpdateProject(userID, projectID int, upd entity.ProjectRequest) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	project, err := findProject(tx, userID, projectID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	project.Name = upd.Name
+	project.Color = upd.Color
+	project.Archived = upd.Archived
+
+	if err := tx.Save(&project).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteProject removes the project, only its owner can do it. Tasks of all
+// members are either moved to the trash (cascade) or moved to the project
+// targetID, 0 means inbox (move).
+func (r *ProjectRepo) DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	project, err := findProject(tx, userID, projectID, entity.RoleOwner)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	switch mode {
+	case entity.ProjectDeleteCascade:
+		// задачи всех участников уходят в корзину, при восстановлении они попадут во входящие
+		if err := tx.Where("project_id = ?", project.ID).Delete(&entity.Task{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	case entity.ProjectDeleteMove:
+		var target *int
+		if targetID != 0 {
+			if targetID == project.ID {
+				tx.Rollback()
+				return errors.New("can not move tasks into the deleted project")
+			}
+			if err := checkProject(tx, userID, targetID); err != nil {
+				tx.Rollback()
+				return err
+			}
+			target = &targetID
+		}
+
+		var moved []int
+		if err := tx.Model(&entity.Task{}).Where("project_id = ?", project.ID).Pluck("id", &moved).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(&entity.Task{}).Where("project_id = ?", project.ID).
+			Update("project_id", target).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := dropStaleAssignees(tx, "t.id IN ?", moved); err != nil {
+			tx.Rollback()
+			return err
+		}
+	default:
+		tx.Rollback()
+		return errors.New("unknown project delete mode")
+	}
+
+	if err := tx.Delete(&project).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+var errProjectArchived = errors.New("project is archived")
+
+// checkProject makes sure the user can edit the project and it still takes new tasks.
+func checkProject(tx *gorm.DB, userID, projectID int) error {
+	project, err := findProject(tx, userID, projectID, entity.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if project.Archived {
+		return errProjectArchived
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateProject(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewProjectRepo(gormDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantID  int
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO \"projects\"").WithArgs("Work", "#ff8800", false, 1).WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			wantID:  3,
+			wantErr: false,
+		},
+		{
+			name: "Insert Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO \"projects\"").WithArgs("Work", "#ff8800", false, 1).WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			id, err := r.CreateProject(1, entity.Project{Name: "Work", Color: "#ff8800"})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, id)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAllProjects(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewProjectRepo(gormDB)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "color", "archived", "user_id"}).AddRow(3, "Work", "#ff8800", false, 1)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "projects" WHERE id IN (SELECT project_id FROM project_access WHERE user_id = $1) AND archived = $2 ORDER BY id`)).
+		WithArgs(1, false).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	projects, err := r.GetAllProjects(1, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Project{{ID: 3, Name: "Work", Color: "#ff8800", UserID: 1}}, projects)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProject(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewProjectRepo(gormDB)
+
+	selectProject := regexp.QuoteMeta(`SELECT * FROM "projects" WHERE "projects"."id" = $1 ORDER BY "projects"."id" LIMIT $2`)
+	deleteProject := regexp.QuoteMeta(`DELETE FROM "projects" WHERE "projects"."id" = $1`)
+	projectRow := func(id int, archived bool) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "color", "archived", "user_id"}).AddRow(id, "Work", "", archived, 1)
+	}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		mode     entity.ProjectDeleteMode
+		targetID int
+		wantErr  bool
+	}{
+		{
+			name: "Cascade",
+			mock: func() {
+				mock.ExpectBegin()
+				expectProjectRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectProject).WithArgs(3, 1).WillReturnRows(projectRow(3, false))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "deleted_at"=$1 WHERE project_id = $2 AND "tasks"."deleted_at" IS NULL`,
+				)).WithArgs(sqlmock.AnyArg(), 3).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(deleteProject).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			mode:    entity.ProjectDeleteCascade,
+			wantErr: false,
+		},
+		{
+			name: "Move To Inbox",
+			mock: func() {
+				mock.ExpectBegin()
+				expectProjectRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectProject).WithArgs(3, 1).WillReturnRows(projectRow(3, false))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tasks" WHERE project_id = $1 AND "tasks"."deleted_at" IS NULL`)).
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "project_id"=$1 WHERE project_id = $2 AND "tasks"."deleted_at" IS NULL`)).
+					WithArgs(nil, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+				// во входящих задачи без исполнителей
+				mock.ExpectExec(`DELETE FROM task_assignees a USING tasks t\s+WHERE a.task_id = t.id AND \(t.id IN \(\$1,\$2\)\)`).
+					WithArgs(7, 8).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteProject).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			mode:    entity.ProjectDeleteMove,
+			wantErr: false,
+		},
+		{
+			name: "Move To Archived Project",
+			mock: func() {
+				mock.ExpectBegin()
+				expectProjectRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectProject).WithArgs(3, 1).WillReturnRows(projectRow(3, false))
+				expectProjectRole(mock, 1, 4, entity.RoleEditor)
+				mock.ExpectQuery(selectProject).WithArgs(4, 1).WillReturnRows(projectRow(4, true))
+				mock.ExpectRollback()
+			},
+			mode:     entity.ProjectDeleteMove,
+			targetID: 4,
+			wantErr:  true,
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				expectProjectRole(mock, 1, 3, "")
+				mock.ExpectRollback()
+			},
+			mode:    entity.ProjectDeleteCascade,
+			wantErr: true,
+		},
+		{
+			name: "Editor Can Not Delete",
+			mock: func() {
+				mock.ExpectBegin()
+				expectProjectRole(mock, 1, 3, entity.RoleEditor)
+				mock.ExpectRollback()
+			},
+			mode:    entity.ProjectDeleteCascade,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteProject(1, 3, tt.mode, tt.targetID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/AronditFire/todo-app/entity"
 	"gorm.io/gorm"
 )
@@ -9,16 +11,106 @@ import (
 
 type TaskList interface {
 	CreateTask(userID int, task entity.Task) (int, error)
-	GetAllTask(userID int) ([]entity.Task, error)
+	GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error)
 	GetTaskByID(userID, id int) (entity.Task, error)
-	UpdateTask(userID, taskId int, desc string) error
-	DeleteTask(userID, taskID int) error
+	UpdateTask(userID, taskId int, desc string, version int) error
+	PatchTask(userID, taskID, version int, apply func(task *entity.Task) error) error
+	UpdateTaskStatus(userID, taskID int, status entity.TaskStatus, completedAt *time.Time) error
+	UpdateTaskDue(userID, taskID int, dueAt, remindAt *time.Time) error
+	UpdateTaskPriority(userID, taskID, priority int) error
+	MoveTask(userID, taskID, anchorID int, after bool) error
+	UpdateTaskProject(userID, taskID int, projectID *int) error
+	UpdateTaskParent(userID, taskID int, parentID *int) error
+	UpdateTaskRecurrence(userID, taskID int, rule string) error
+	DeleteTask(userID, taskID, version int) error
+	Batch(userID int, ops []TaskOp, atomic bool) ([]TaskOpResult, error)
+	ImportTasks(userID int, tasks []entity.ImportTask) ([]int, error)
+	ExportTasks(userID int, fn func(task entity.Task) error) error
+	GetChanges(userID int, since entity.SyncToken) (entity.TaskChanges, error)
+	CurrentSyncToken() (entity.SyncToken, error)
+	PruneChanges(before time.Time) (int64, error)
+}
+
+type Tags interface {
+	CreateTag(userID int, tag entity.Tag) (int, error)
+	GetAllTags(userID int) ([]entity.Tag, error)
+	GetTagByID(userID, tagID int) (entity.Tag, error)
+	GetTagTaskIDs(userID, tagID int) ([]int, error)
+	UpdateTag(userID, tagID int, name string) error
+	DeleteTag(userID, tagID int) error
+	AttachTag(userID, taskID, tagID int) error
+	DetachTag(userID, taskID, tagID int) error
+}
+
+type Projects interface {
+	CreateProject(userID int, project entity.Project) (int, error)
+	GetAllProjects(userID int, withArchived bool) ([]entity.Project, error)
+	GetProjectByID(userID, projectID int) (entity.Project, error)
+	UpdateProject(userID, projectID int, upd entity.ProjectRequest) error
+	DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error
+}
+
+// Trash holds soft deleted tasks until they are restored or purged.
+type Trash interface {
+	GetTrash(userID int) ([]entity.Task, error)
+	RestoreTask(userID, taskID int) error
+	PurgeTask(userID, taskID int) error
+	EmptyTrash(userID int) error
+	PurgeExpired(before time.Time) (int64, error)
+}
+
+// History is read only, entries are written by TaskList in its own transactions.
+type History interface {
+	GetTaskHistory(userID, taskID int) ([]entity.TaskHistory, error)
+}
+
+// Sharing grants other users access to tasks and projects. Roles and
+// audiences are also used by the cache to know whose lists to invalidate.
+type Sharing interface {
+	TaskRole(userID, taskID int) (entity.Role, error)
+	ProjectRole(userID, projectID int) (entity.Role, error)
+	TaskAudience(taskID int) ([]int, error)
+	TasksAudience(taskIDs []int) ([]int, error)
+	ProjectAudience(projectID int) ([]int, error)
+	GetTaskShares(userID, taskID int) ([]entity.TaskShare, error)
+	ShareTask(userID, taskID, granteeID int, role entity.Role) error
+	UnshareTask(userID, taskID, granteeID int) error
+	GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error)
+	ShareProject(userID, projectID, granteeID int, role entity.Role) error
+	UnshareProject(userID, projectID, granteeID int) error
+}
+
+// Assignments ties tasks in shared projects to the members responsible for them.
+type Assignments interface {
+	GetAssignees(userID, taskID int) ([]entity.TaskAssignee, error)
+	SetAssignees(userID, taskID int, userIDs []int) error
+	GetAssignedTasks(userID int) ([]entity.Task, error)
+}
+
+// Comments is a discussion thread under a task, the oldest comment goes first.
+type Comments interface {
+	GetComments(userID, taskID, afterID, limit int) ([]entity.Comment, error)
+	CreateComment(userID, taskID int, body string) (int, error)
+	UpdateComment(userID, taskID, commentID int, body string) error
+	DeleteComment(userID, taskID, commentID int) error
+}
+
+// Attachments describes files of tasks, the files themselves are in the blob
+// storage. Deleted blobs are queued and removed from the storage later.
+type Attachments interface {
+	GetAttachments(userID, taskID int) ([]entity.Attachment, error)
+	GetAttachment(userID, taskID, attachmentID int) (entity.Attachment, error)
+	CreateAttachment(userID, taskID int, attachment entity.Attachment) (int, error)
+	DeleteAttachment(userID, taskID, attachmentID int) error
+	GetPurgedBlobs(limit int) ([]entity.PurgedBlob, error)
+	ForgetPurgedBlobs(keys []string) error
 }
 
 type Authorization interface {
 	CreateUser(userReg entity.UserRegisterRequest) error
 	GetUser(username string) (entity.User, error)
 	GetUserByID(id int) (entity.User, error)
+	GetProjectMembers(projectID int) ([]entity.User, error)
 }
 
 type ParsingJSON interface {
@@ -26,8 +118,42 @@ type ParsingJSON interface {
 	GetJsonTable() ([]map[string]any, error)
 }
 
+// Feeds stores the tokens of the calendar feeds.
+type Feeds interface {
+	SaveFeedToken(userID int, tokenHash string) (string, error)
+	DeleteFeedToken(userID int) (string, error)
+	GetFeedUser(tokenHash string) (int, error)
+}
+
+// CalDAV stores app passwords and the names CalDAV clients gave tasks.
+type CalDAV interface {
+	CreateAppPassword(password entity.AppPassword) (int, error)
+	GetAppPasswords(userID int) ([]entity.AppPassword, error)
+	DeleteAppPassword(userID, passwordID int) error
+	GetAppPasswordUser(username, hash string) (int, error)
+	GetCalendarObjects(userID int) ([]entity.CalendarObject, error)
+	FindCalendarObject(userID int, name string, taskID int) (*entity.CalendarObject, error)
+	HasCalendarUID(userID int, uid string) (bool, error)
+	GetRemovedCalendarObjects(userID int, since entity.SyncToken) ([]entity.CalendarObject, error)
+}
+
+type Search interface {
+	SearchTasks(userID int, query string, limit int) ([]entity.TaskSearchResult, error)
+}
+
 type Repository struct {
 	TaskList
+	Tags
+	Projects
+	Trash
+	History
+	Sharing
+	Assignments
+	Comments
+	Attachments
+	Search
+	Feeds
+	CalDAV
 	Authorization
 	ParsingJSON
 }
@@ -35,6 +161,17 @@ type Repository struct {
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{
 		TaskList:      NewTaskRepo(db),
+		Tags:          NewTagRepo(db),
+		Projects:      NewProjectRepo(db),
+		Trash:         NewTrashRepo(db),
+		History:       NewHistoryRepo(db),
+		Sharing:       NewShareRepo(db),
+		Assignments:   NewAssigneeRepo(db),
+		Comments:      NewCommentRepo(db),
+		Attachments:   NewAttachmentRepo(db),
+		Search:        NewSearchRepo(db),
+		Feeds:         NewFeedRepo(db),
+		CalDAV:        NewCalDAVRepo(db),
 		Authorization: NewAuthRepo(db),
 		ParsingJSON:   NewParseRepo(db),
 	}
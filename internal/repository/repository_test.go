@@ -15,6 +15,15 @@ func TestNewService(t *testing.T) {
 	assert.NotNil(t, svc)
 
 	assert.NotNil(t, svc.TaskList)
+	assert.NotNil(t, svc.Tags)
+	assert.NotNil(t, svc.Projects)
+	assert.NotNil(t, svc.Trash)
+	assert.NotNil(t, svc.History)
+	assert.NotNil(t, svc.Sharing)
+	assert.NotNil(t, svc.Assignments)
+	assert.NotNil(t, svc.Comments)
+	assert.NotNil(t, svc.Attachments)
+	assert.NotNil(t, svc.Search)
 	assert.NotNil(t, svc.Authorization)
 	assert.NotNil(t, svc.ParsingJSON)
 
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// searchConfig is the text search configuration of tasks.search_vector:
+// без стемминга, зато одинаково работает для любого языка.
+const searchConfig = "simple"
+
+// escapedDescriptionSQL is the description with HTML special characters
+// escaped: ts_headline adds <mark> tags to the snippet, the user's text must
+// not be markup. The parser reads &lt; and the like as separate tokens, so
+// the words still match.
+const escapedDescriptionSQL = `replace(replace(replace(replace(replace(description,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+var searchWordRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+type SearchRepo struct {
+	db *gorm.DB
+}
+
+func NewSearchRepo(db *gorm.DB) *SearchRepo {
+	return &SearchRepo{db: db}
+}
+
+// SearchTasks ranks tasks of the user whose description contains every word
+// of the query, the last letters of each word may be missing.
+func (r *SearchRepo) SearchTasks(userID int, query string, limit int) ([]entity.TaskSearchResult, error) {
+	var results []entity.TaskSearchResult
+
+	tsQuery := prefixQuery(query)
+	if tsQuery == "" {
+		return results, nil
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Raw(`SELECT tasks.*,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline(?, `+escapedDescriptionSQL+`, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20') AS snippet
+		FROM tasks, to_tsquery(?, ?) q
+		WHERE `+visibleTasksSQL+` AND deleted_at IS NULL AND search_vector @@ q
+		ORDER BY rank DESC, id
+		LIMIT ?`,
+		searchConfig, searchConfig, tsQuery, userID, limit).Scan(&results).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return results, tx.Commit().Error
+}
+
+// prefixQuery turns user input into a tsquery where every word is a prefix:
+// "buy mil" -> "buy:* & mil:*". Operators of tsquery syntax are dropped.
+func prefixQuery(query string) string {
+	words := searchWordRe.FindAllString(strings.ToLower(query), -1)
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// MigrateSearch adds the generated tsvector column and its GIN index,
+// AutoMigrate can not describe generated columns.
+func MigrateSearch(db *gorm.DB) error {
+	if err := db.Exec(`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('` + searchConfig + `', coalesce(description, ''))) STORED`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)`).Error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Words", input: "Buy mil", want: "buy:* & mil:*"},
+		{name: "Operators Dropped", input: "milk & !bread | (eggs:*)", want: "milk:* & bread:* & eggs:*"},
+		{name: "Cyrillic", input: "купить молоко", want: "купить:* & молоко:*"},
+		{name: "Nothing To Search", input: " &! ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, prefixQuery(tt.input))
+		})
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewSearchRepo(gormDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		query   string
+		want    []entity.TaskSearchResult
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status", "rank", "snippet"}).
+					AddRow(3, "Buy milk", 1, "todo", 0.6, "Buy <mark>milk</mark>")
+				mock.ExpectBegin()
+				// сниппет строится по экранированному описанию
+				mock.ExpectQuery(`SELECT tasks\.\*,\s+ts_rank\(search_vector, q\) AS rank,\s+ts_headline\(\$1, replace\(`).
+					WithArgs("simple", "simple", "mil:*", 1, 10).
+					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			query: "mil",
+			want: []entity.TaskSearchResult{{
+				Task:    entity.Task{ID: 3, Description: "Buy milk", UserID: 1, Status: entity.StatusTodo},
+				Rank:    0.6,
+				Snippet: "Buy <mark>milk</mark>",
+			}},
+		},
+		{
+			name:  "Only Operators",
+			mock:  func() {},
+			query: "&|!",
+		},
+		{
+			name: "Query Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT tasks\.\*`).WillReturnError(errors.New("Query Error"))
+				mock.ExpectRollback()
+			},
+			query:   "milk",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			results, err := r.SearchTasks(1, tt.query, 10)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, len(tt.want), len(results))
+				if len(tt.want) > 0 {
+					assert.Equal(t, tt.want, results)
+				}
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
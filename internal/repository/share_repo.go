@@ -0,0 +1,479 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// roleLevelSQL turns a role column into the number from entity.Role.Level.
+const roleLevelSQL = `CASE role WHEN 'owner' THEN 3 WHEN 'editor' THEN 2 ELSE 1 END`
+
+// visibleTasksSQL keeps tasks the user owns or got access to.
+const visibleTasksSQL = "id IN (SELECT task_id FROM task_access_for(?))"
+
+type ShareRepo struct {
+	db *gorm.DB
+}
+
+func NewShareRepo(db *gorm.DB) *ShareRepo {
+	return &ShareRepo{db: db}
+}
+
+func (r *ShareRepo) TaskRole(userID, taskID int) (entity.Role, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return "", err
+	}
+
+	role, err := taskRole(tx, userID, taskID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return role, tx.Commit().Error
+}
+
+func (r *ShareRepo) ProjectRole(userID, projectID int) (entity.Role, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return "", err
+	}
+
+	role, err := projectRole(tx, userID, projectID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return role, tx.Commit().Error
+}
+
+// TaskAudience returns ids of all users who can see the task.
+func (r *ShareRepo) TaskAudience(taskID int) ([]int, error) {
+	var users []int
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Raw("SELECT DISTINCT user_id FROM task_audience(ARRAY[?]::bigint[])", taskID).Scan(&users).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return users, tx.Commit().Error
+}
+
+// TasksAudience is TaskAudience of several tasks at once.
+func (r *ShareRepo) TasksAudience(taskIDs []int) ([]int, error) {
+	var users []int
+
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// срез gorm раскрывает в ($1,$2), поэтому массив собирается подзапросом
+	if err := tx.Raw("SELECT DISTINCT user_id FROM task_audience(ARRAY(SELECT id FROM tasks WHERE id IN ?))", taskIDs).
+		Scan(&users).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return users, tx.Commit().Error
+}
+
+// ProjectAudience returns ids of all users who can see the project or any of its tasks.
+func (r *ShareRepo) ProjectAudience(projectID int) ([]int, error) {
+	var users []int
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Raw(`SELECT user_id FROM project_access WHERE project_id = ?
+		UNION
+		SELECT user_id FROM task_audience(ARRAY(SELECT id FROM tasks WHERE project_id = ?))`,
+		projectID, projectID).Scan(&users).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return users, tx.Commit().Error
+}
+
+func (r *ShareRepo) GetTaskShares(userID, taskID int) ([]entity.TaskShare, error) {
+	var shares []entity.TaskShare
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := requireTaskRole(tx, userID, taskID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", taskID).Order("created_at, user_id").Find(&shares).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return shares, tx.Commit().Error
+}
+
+// ShareTask grants the role to another user, sharing again changes the role.
+func (r *ShareRepo) ShareTask(userID, taskID, granteeID int, role entity.Role) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleOwner)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if granteeID == task.UserID {
+		tx.Rollback()
+		return errors.New("task can not be shared with its owner")
+	}
+
+	share := entity.TaskShare{TaskID: task.ID, UserID: granteeID, Role: role}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&share).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UnshareTask takes the access away. The owner can remove anybody,
+// other users can only remove themselves.
+func (r *ShareRepo) UnshareTask(userID, taskID, granteeID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	need := entity.RoleOwner
+	if granteeID == userID {
+		need = entity.RoleViewer
+	}
+	if err := requireTaskRole(tx, userID, taskID, need); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("task_id = ? AND user_id = ?", taskID, granteeID).Delete(&entity.TaskShare{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *ShareRepo) GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error) {
+	var shares []entity.ProjectShare
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := requireProjectRole(tx, userID, projectID, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("project_id = ?", projectID).Order("created_at, user_id").Find(&shares).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return shares, tx.Commit().Error
+}
+
+func (r *ShareRepo) ShareProject(userID, projectID, granteeID int, role entity.Role) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	project, err := findProject(tx, userID, projectID, entity.RoleOwner)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if granteeID == project.UserID {
+		tx.Rollback()
+		return errors.New("project can not be shared with its owner")
+	}
+
+	share := entity.ProjectShare{ProjectID: project.ID, UserID: granteeID, Role: role}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&share).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *ShareRepo) UnshareProject(userID, projectID, granteeID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	need := entity.RoleOwner
+	if granteeID == userID {
+		need = entity.RoleViewer
+	}
+	if err := requireProjectRole(tx, userID, projectID, need); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// SetAssignees держит проект FOR SHARE: назначение, проверенное до отзыва
+	// доступа, будет видно ниже и снимется вместе с остальными
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entity.Project{}, projectID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("project_id = ? AND user_id = ?", projectID, granteeID).Delete(&entity.ProjectShare{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := dropStaleAssignees(tx, "t.project_id = ?", projectID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// taskRole returns the best role the user has for the task: as its owner,
+// through a share of the task or of one of its parents, or through a share
+// of the project. "" means no access.
+func taskRole(tx *gorm.DB, userID, taskID int) (entity.Role, error) {
+	var level int
+
+	err := tx.Raw("SELECT COALESCE(MAX(level), 0) FROM task_audience(ARRAY[?]::bigint[]) WHERE user_id = ?",
+		taskID, userID).Scan(&level).Error
+
+	return entity.RoleByLevel(level), err
+}
+
+// requireTaskRole fails with entity.ErrNotFound when the user can not see
+// the task at all and with entity.ErrForbidden when the role is too low.
+func requireTaskRole(tx *gorm.DB, userID, taskID int, need entity.Role) error {
+	role, err := taskRole(tx, userID, taskID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return gorm.ErrRecordNotFound
+	}
+	if !role.Allows(need) {
+		return entity.ErrForbidden
+	}
+
+	return nil
+}
+
+// findTask loads a task the user has at least the given role for.
+func findTask(tx *gorm.DB, userID, taskID int, need entity.Role) (entity.Task, error) {
+	var task entity.Task
+
+	if err := requireTaskRole(tx, userID, taskID, need); err != nil {
+		return entity.Task{}, err
+	}
+
+	err := tx.First(&task, taskID).Error
+
+	return task, err
+}
+
+func projectRole(tx *gorm.DB, userID, projectID int) (entity.Role, error) {
+	var level int
+
+	err := tx.Raw("SELECT COALESCE(MAX(level), 0) FROM project_access WHERE user_id = ? AND project_id = ?",
+		userID, projectID).Scan(&level).Error
+
+	return entity.RoleByLevel(level), err
+}
+
+func requireProjectRole(tx *gorm.DB, userID, projectID int, need entity.Role) error {
+	role, err := projectRole(tx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return gorm.ErrRecordNotFound
+	}
+	if !role.Allows(need) {
+		return entity.ErrForbidden
+	}
+
+	return nil
+}
+
+func findProject(tx *gorm.DB, userID, projectID int, need entity.Role) (entity.Project, error) {
+	var project entity.Project
+
+	if err := requireProjectRole(tx, userID, projectID, need); err != nil {
+		return entity.Project{}, err
+	}
+
+	err := tx.First(&project, projectID).Error
+
+	return project, err
+}
+
+// MigrateAccess creates the SQL that resolves roles. Access to a task comes
+// from owning it, from a share of the task or of any of its parents, and from
+// owning or sharing its project. Several rows per user are possible, the
+// highest level wins.
+//
+// Tasks are resolved by functions rather than a view: a recursive view is
+// computed for every share before the caller's user_id or task_id is
+// applied. task_access_for starts from what the user owns or got shared and
+// goes down to subtasks, task_audience starts from the tasks and goes up to
+// their parents.
+func MigrateAccess(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE VIEW project_access AS
+			SELECT id AS project_id, user_id, 3 AS level FROM projects
+			UNION ALL
+			SELECT project_id, user_id, ` + roleLevelSQL + ` FROM project_shares`,
+
+		// задачи, которые видит пользователь, и его уровень доступа к ним
+		`CREATE OR REPLACE FUNCTION task_access_for(uid bigint) RETURNS TABLE (task_id bigint, level integer) AS $$
+			WITH RECURSIVE granted (task_id, level) AS (
+				SELECT task_id, level FROM (
+					SELECT id AS task_id, 3 AS level FROM tasks WHERE user_id = uid
+					UNION ALL
+					SELECT task_id, ` + roleLevelSQL + ` FROM task_shares WHERE user_id = uid
+					UNION ALL
+					SELECT t.id, p.level FROM project_access p JOIN tasks t ON t.project_id = p.project_id WHERE p.user_id = uid
+				) direct
+				UNION
+				SELECT t.id, g.level FROM granted g JOIN tasks t ON t.parent_id = g.task_id
+			)
+			SELECT task_id, level FROM granted
+		$$ LANGUAGE sql STABLE`,
+
+		// пользователи, которые видят задачи: владелец, получатели задачи или любого
+		// её родителя и участники проекта задачи или любого её родителя
+		`CREATE OR REPLACE FUNCTION task_audience(ids bigint[]) RETURNS TABLE (task_id bigint, user_id bigint, level integer) AS $$
+			WITH RECURSIVE chain (task_id, ancestor_id) AS (
+				SELECT id, id FROM tasks WHERE id = ANY(ids)
+				UNION
+				SELECT c.task_id, t.parent_id FROM chain c JOIN tasks t ON t.id = c.ancestor_id WHERE t.parent_id IS NOT NULL
+			)
+			SELECT id, user_id, 3 FROM tasks WHERE id = ANY(ids)
+			UNION ALL
+			SELECT c.task_id, s.user_id, ` + roleLevelSQL + ` FROM chain c JOIN task_shares s ON s.task_id = c.ancestor_id
+			UNION ALL
+			SELECT c.task_id, p.user_id, p.level FROM chain c JOIN tasks t ON t.id = c.ancestor_id
+				JOIN project_access p ON p.project_id = t.project_id
+		$$ LANGUAGE sql STABLE`,
+
+		`DROP VIEW IF EXISTS task_access`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestShareTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewShareRepo(gormDB)
+
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	upsert := regexp.QuoteMeta(
+		`INSERT INTO "task_shares" ("task_id","user_id","role") VALUES ($1,$2,$3) ON CONFLICT ("task_id","user_id") DO UPDATE SET "role"="excluded"."role" RETURNING "created_at"`,
+	)
+
+	tests := []struct {
+		name      string
+		mock      func()
+		granteeID int
+		wantErr   bool
+		errIs     error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, 1))
+				mock.ExpectQuery(upsert).WithArgs(5, 2, "editor").
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+				mock.ExpectCommit()
+			},
+			granteeID: 2,
+		},
+		{
+			name: "Editor Can Not Share",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleEditor)
+				mock.ExpectRollback()
+			},
+			granteeID: 2,
+			wantErr:   true,
+			errIs:     entity.ErrForbidden,
+		},
+		{
+			name: "No Access",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, "")
+				mock.ExpectRollback()
+			},
+			granteeID: 2,
+			wantErr:   true,
+			errIs:     gorm.ErrRecordNotFound,
+		},
+		{
+			name: "Shared Owner Can Not Share With Author",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, 3))
+				mock.ExpectRollback()
+			},
+			granteeID: 3,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.ShareTask(1, 5, tt.granteeID, entity.RoleEditor)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errIs != nil {
+					assert.ErrorIs(t, err, tt.errIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnshareTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewShareRepo(gormDB)
+
+	deleteShare := regexp.QuoteMeta(`DELETE FROM "task_shares" WHERE task_id = $1 AND user_id = $2`)
+
+	tests := []struct {
+		name      string
+		mock      func()
+		granteeID int
+		wantErr   bool
+	}{
+		{
+			name: "Viewer Leaves",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectExec(deleteShare).WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			granteeID: 1,
+			wantErr:   false,
+		},
+		{
+			name: "Viewer Can Not Remove Others",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 5, entity.RoleViewer)
+				mock.ExpectRollback()
+			},
+			granteeID: 2,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UnshareTask(1, 5, tt.granteeID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnshareProject(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewShareRepo(gormDB)
+
+	mock.ExpectBegin()
+	expectProjectRole(mock, 1, 3, entity.RoleOwner)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "projects" WHERE "projects"."id" = $1 ORDER BY "projects"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "project_shares" WHERE project_id = $1 AND user_id = $2`)).
+		WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	// бывший участник перестаёт быть исполнителем задач проекта
+	mock.ExpectExec(`DELETE FROM task_assignees a USING tasks t\s+WHERE a.task_id = t.id AND \(t.project_id = \$1\)`).
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.UnshareProject(1, 3, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskAudience(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewShareRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT user_id FROM task_audience(ARRAY[$1]::bigint[])`)).
+		WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	users, err := r.TaskAudience(5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTasksAudience(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewShareRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT user_id FROM task_audience(ARRAY(SELECT id FROM tasks WHERE id IN ($1,$2)))`)).
+		WithArgs(5, 6).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(3))
+	mock.ExpectCommit()
+
+	users, err := r.TasksAudience([]int{5, 6})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectTaskRole mocks the access check done before a task is read or changed.
+func expectTaskRole(mock sqlmock.Sqlmock, userID, taskID int, role entity.Role) {
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT COALESCE(MAX(level), 0) FROM task_audience(ARRAY[$1]::bigint[]) WHERE user_id = $2`,
+	)).WithArgs(taskID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"level"}).AddRow(role.Level()))
+}
+
+func expectProjectRole(mock sqlmock.Sqlmock, userID, projectID int, role entity.Role) {
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT COALESCE(MAX(level), 0) FROM project_access WHERE user_id = $1 AND project_id = $2`,
+	)).WithArgs(userID, projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"level"}).AddRow(role.Level()))
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// currentXIDSQL is the id of the running transaction, it only grows.
+const currentXIDSQL = "pg_current_xact_id()::text::bigint"
+
+// GetChanges returns what changed for the user since the token, everything
+// for a zero token. Changes and the new token come from one snapshot.
+func (r *TaskRepo) GetChanges(userID int, since entity.SyncToken) (entity.TaskChanges, error) {
+	var changes entity.TaskChanges
+
+	tx := r.db.Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return entity.TaskChanges{}, err
+	}
+
+	// транзакции старше xmin завершены, их изменения в этот снимок уже попали
+	var xmin int64
+	if err := tx.Raw("SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&xmin).Error; err != nil {
+		tx.Rollback()
+		return entity.TaskChanges{}, err
+	}
+	changes.Token = entity.SyncToken(xmin)
+
+	query := tx.Select(withCommentCountSQL).Where(visibleTasksSQL, userID)
+	if since > 0 {
+		// изменения до горизонта уже стёрты, дельту от такого токена не собрать
+		var horizon int64
+		if err := tx.Raw("SELECT xid FROM sync_horizon").Scan(&horizon).Error; err != nil {
+			tx.Rollback()
+			return entity.TaskChanges{}, err
+		}
+		if int64(since) <= horizon {
+			tx.Rollback()
+			return entity.TaskChanges{}, entity.ErrSyncTokenExpired
+		}
+
+		query = query.Where("id IN (SELECT task_id FROM task_changes WHERE user_id = ? AND xid >= ?)", userID, since)
+
+		// gone - задачи, которых пользователь больше не видит: в корзине, стёрты или больше не расшарены
+		var logged []struct {
+			TaskID  int
+			Created bool
+			Gone    bool
+		}
+		err := tx.Raw(`SELECT task_id, bool_or(created) AS created,
+				NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = c.task_id AND t.deleted_at IS NULL
+					AND t.id IN (SELECT task_id FROM task_access_for(?))) AS gone
+			FROM task_changes c WHERE user_id = ? AND xid >= ?
+			GROUP BY task_id ORDER BY task_id`, userID, userID, since).Scan(&logged).Error
+		if err != nil {
+			tx.Rollback()
+			return entity.TaskChanges{}, err
+		}
+
+		for _, l := range logged {
+			switch {
+			case l.Gone:
+				changes.Deleted = append(changes.Deleted, l.TaskID)
+			case l.Created:
+				changes.Created = append(changes.Created, l.TaskID)
+			}
+		}
+	}
+
+	if err := query.Preload("Tags").Order("id").Find(&changes.Tasks).Error; err != nil {
+		tx.Rollback()
+		return entity.TaskChanges{}, err
+	}
+
+	return changes, tx.Commit().Error
+}
+
+// CurrentSyncToken is the token of a client that has seen everything
+// committed so far, GetChanges from it returns only what comes next.
+func (r *TaskRepo) CurrentSyncToken() (entity.SyncToken, error) {
+	var xmin int64
+	err := r.db.Raw("SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&xmin).Error
+
+	return entity.SyncToken(xmin), err
+}
+
+// PruneChanges removes the log rows written before the time and the CalDAV
+// removals of the same transactions. Tokens that need them are expired from
+// now on. It returns how many log rows were removed.
+func (r *TaskRepo) PruneChanges(before time.Time) (int64, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	var pruned struct {
+		Count   int64
+		Horizon int64
+	}
+	err := tx.Raw(`WITH pruned AS (DELETE FROM task_changes WHERE logged_at < ? RETURNING xid)
+		SELECT count(*) AS count, COALESCE(MAX(xid), 0) AS horizon FROM pruned`, before).Scan(&pruned).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if pruned.Count > 0 {
+		if err := tx.Exec("UPDATE sync_horizon SET xid = GREATEST(xid, ?)", pruned.Horizon).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := tx.Exec("DELETE FROM calendar_object_removals WHERE xid <= ?", pruned.Horizon).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return pruned.Count, tx.Commit().Error
+}
+
+// MigrateChanges creates the change log behind GET /api/sync and GET
+// /api/events and the triggers that fill it. A row says the task changed for
+// the user: its fields or tags, or the user got or lost access to it. The row
+// does not say what happened beyond whether the task was created, GetChanges
+// reads the current state. Triggers see every write, including bulk ones and
+// the ones made outside the app.
+//
+// PruneChanges removes old rows and moves sync_horizon past them, a token at
+// or before the horizon can no longer be answered with a delta.
+func MigrateChanges(db *gorm.DB) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS task_changes (
+			id bigserial PRIMARY KEY,
+			user_id bigint NOT NULL,
+			task_id bigint NOT NULL,
+			xid bigint NOT NULL,
+			created boolean NOT NULL DEFAULT false,
+			logged_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_changes_user_xid ON task_changes (user_id, xid)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_changes_logged_at ON task_changes (logged_at)`,
+
+		// самый новый стёртый xid, одна строка
+		`CREATE TABLE IF NOT EXISTS sync_horizon (xid bigint NOT NULL)`,
+		`INSERT INTO sync_horizon (xid) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM sync_horizon)`,
+
+		// задачи вместе со всеми подзадачами, доступ к задаче переходит на её подзадачи
+		`CREATE OR REPLACE FUNCTION task_subtree(roots bigint[]) RETURNS SETOF bigint AS $$
+			WITH RECURSIVE sub (id) AS (
+				SELECT unnest(roots)
+				UNION
+				SELECT t.id FROM tasks t JOIN sub ON t.parent_id = sub.id
+			)
+			SELECT id FROM sub
+		$$ LANGUAGE sql STABLE`,
+
+		// записывает изменение задач для всех, кто видит их сейчас
+		`CREATE OR REPLACE FUNCTION log_task_changes(ids bigint[]) RETURNS void AS $$
+			INSERT INTO task_changes (user_id, task_id, xid)
+			SELECT DISTINCT user_id, task_id, ` + currentXIDSQL + ` FROM task_audience(ids)
+		$$ LANGUAGE sql`,
+
+		`CREATE OR REPLACE FUNCTION log_changed_tasks() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'INSERT' THEN
+				INSERT INTO task_changes (user_id, task_id, xid, created)
+				SELECT DISTINCT user_id, task_id, ` + currentXIDSQL + `, true
+				FROM task_audience(ARRAY(SELECT id FROM changed));
+			ELSE
+				PERFORM log_task_changes(ARRAY(SELECT id FROM changed));
+			END IF;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_changes_insert AFTER INSERT ON tasks
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION log_changed_tasks()`,
+		`CREATE OR REPLACE TRIGGER tasks_changes_update AFTER UPDATE ON tasks
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION log_changed_tasks()`,
+
+		// перенос в другой проект или под другого родителя меняет доступ ко всему поддереву:
+		// до переноса пишем тем, кто его теряет, после - тем, кто получает
+		`CREATE OR REPLACE FUNCTION log_moved_task() RETURNS trigger AS $$
+		BEGIN
+			PERFORM log_task_changes(ARRAY(SELECT task_subtree(ARRAY[OLD.id])));
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_changes_move_from BEFORE UPDATE OF project_id, parent_id ON tasks
+			FOR EACH ROW WHEN (OLD.project_id IS DISTINCT FROM NEW.project_id OR OLD.parent_id IS DISTINCT FROM NEW.parent_id)
+			EXECUTE FUNCTION log_moved_task()`,
+		`CREATE OR REPLACE TRIGGER tasks_changes_move_to AFTER UPDATE OF project_id, parent_id ON tasks
+			FOR EACH ROW WHEN (OLD.project_id IS DISTINCT FROM NEW.project_id OR OLD.parent_id IS DISTINCT FROM NEW.parent_id)
+			EXECUTE FUNCTION log_moved_task()`,
+
+		// после удаления строки доступ уже не вычислить
+		`CREATE OR REPLACE FUNCTION log_purged_task() RETURNS trigger AS $$
+		BEGIN
+			PERFORM log_task_changes(ARRAY[OLD.id]);
+			RETURN OLD;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_changes_purge BEFORE DELETE ON tasks
+			FOR EACH ROW EXECUTE FUNCTION log_purged_task()`,
+
+		`CREATE OR REPLACE FUNCTION log_tagged_tasks() RETURNS trigger AS $$
+		BEGIN
+			PERFORM log_task_changes(ARRAY(SELECT task_id FROM changed));
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER task_tags_changes_insert AFTER INSERT ON task_tags
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION log_tagged_tasks()`,
+		`CREATE OR REPLACE TRIGGER task_tags_changes_delete AFTER DELETE ON task_tags
+			REFERENCING OLD TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION log_tagged_tasks()`,
+
+		`CREATE OR REPLACE FUNCTION log_renamed_tags() RETURNS trigger AS $$
+		BEGIN
+			PERFORM log_task_changes(ARRAY(SELECT task_id FROM task_tags WHERE tag_id IN (SELECT id FROM changed)));
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tags_changes_update AFTER UPDATE ON tags
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION log_renamed_tags()`,
+
+		// получивший или потерявший доступ пользователь может не видеть задачи сейчас,
+		// поэтому пишем именно ему, а не текущим читателям
+		`CREATE OR REPLACE FUNCTION log_share_change() RETURNS trigger AS $$
+		DECLARE
+			share record;
+			roots bigint[];
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				share := OLD;
+			ELSE
+				share := NEW;
+			END IF;
+
+			IF TG_TABLE_NAME = 'task_shares' THEN
+				roots := ARRAY[share.task_id];
+			ELSE
+				roots := ARRAY(SELECT id FROM tasks WHERE project_id = share.project_id);
+			END IF;
+
+			INSERT INTO task_changes (user_id, task_id, xid)
+			SELECT share.user_id, id, ` + currentXIDSQL + ` FROM task_subtree(roots) AS id;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER task_shares_changes AFTER INSERT OR UPDATE OR DELETE ON task_shares
+			FOR EACH ROW EXECUTE FUNCTION log_share_change()`,
+		`CREATE OR REPLACE TRIGGER project_shares_changes AFTER INSERT OR UPDATE OR DELETE ON project_shares
+			FOR EACH ROW EXECUTE FUNCTION log_share_change()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
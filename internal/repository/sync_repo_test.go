@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChanges(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	selectXmin := regexp.QuoteMeta(`SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`)
+	selectHorizon := regexp.QuoteMeta(`SELECT xid FROM sync_horizon`)
+	selectLogged := regexp.QuoteMeta(`SELECT task_id, bool_or(created) AS created,`)
+	selectAll := regexp.QuoteMeta(`FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND "tasks"."deleted_at" IS NULL ORDER BY id`)
+	selectChanged := regexp.QuoteMeta(`FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND (id IN (SELECT task_id FROM task_changes WHERE user_id = $2 AND xid >= $3)) AND "tasks"."deleted_at" IS NULL ORDER BY id`)
+	selectTags := regexp.QuoteMeta(`SELECT * FROM "task_tags"`)
+
+	tests := []struct {
+		name        string
+		since       entity.SyncToken
+		mock        func()
+		wantIDs     []int
+		wantCreated []int
+		wantDeleted []int
+		wantErr     error
+	}{
+		{
+			name:  "Full",
+			since: 0,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectXmin).WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(120))
+				mock.ExpectQuery(selectAll).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "A", 1).AddRow(2, "B", 1))
+				mock.ExpectQuery(selectTags).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int{1, 2},
+		},
+		{
+			name:  "Delta",
+			since: 100,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectXmin).WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(120))
+				mock.ExpectQuery(selectHorizon).WillReturnRows(sqlmock.NewRows([]string{"xid"}).AddRow(50))
+				// 7 создана и сразу ушла в корзину - для клиента она удалена
+				mock.ExpectQuery(selectLogged).WithArgs(1, 1, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"task_id", "created", "gone"}).
+						AddRow(2, true, false).AddRow(3, false, true).AddRow(5, false, false).AddRow(7, true, true))
+				mock.ExpectQuery(selectChanged).WithArgs(1, 1, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(2, "B", 1))
+				mock.ExpectQuery(selectTags).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectCommit()
+			},
+			wantIDs:     []int{2},
+			wantCreated: []int{2},
+			wantDeleted: []int{3, 7},
+		},
+		{
+			name:  "Expired",
+			since: 100,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectXmin).WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(120))
+				mock.ExpectQuery(selectHorizon).WillReturnRows(sqlmock.NewRows([]string{"xid"}).AddRow(100))
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrSyncTokenExpired,
+		},
+		{
+			name:  "DB Error",
+			since: 100,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectXmin).WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(120))
+				mock.ExpectQuery(selectHorizon).WillReturnRows(sqlmock.NewRows([]string{"xid"}).AddRow(50))
+				mock.ExpectQuery(selectLogged).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := r.GetChanges(1, tt.since)
+
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				var ids []int
+				for _, task := range got.Tasks {
+					ids = append(ids, task.ID)
+				}
+				assert.Equal(t, tt.wantIDs, ids)
+				assert.Equal(t, tt.wantCreated, got.Created)
+				assert.Equal(t, tt.wantDeleted, got.Deleted)
+				assert.Equal(t, entity.SyncToken(120), got.Token)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCurrentSyncToken(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`)).
+		WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(120))
+
+	token, err := r.CurrentSyncToken()
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.SyncToken(120), token)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneChanges(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	before := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	deleteChanges := regexp.QuoteMeta(`WITH pruned AS (DELETE FROM task_changes WHERE logged_at < $1 RETURNING xid)`)
+	updateHorizon := regexp.QuoteMeta(`UPDATE sync_horizon SET xid = GREATEST(xid, $1)`)
+	deleteRemovals := regexp.QuoteMeta(`DELETE FROM calendar_object_removals WHERE xid <= $1`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "Pruned",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteChanges).WithArgs(before).
+					WillReturnRows(sqlmock.NewRows([]string{"count", "horizon"}).AddRow(3, 90))
+				mock.ExpectExec(updateHorizon).WithArgs(90).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteRemovals).WithArgs(90).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			want: 3,
+		},
+		{
+			name: "Nothing To Prune",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteChanges).WithArgs(before).
+					WillReturnRows(sqlmock.NewRows([]string{"count", "horizon"}).AddRow(0, 0))
+				mock.ExpectCommit()
+			},
+			want: 0,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteChanges).WithArgs(before).
+					WillReturnRows(sqlmock.NewRows([]string{"count", "horizon"}).AddRow(3, 90))
+				mock.ExpectExec(updateHorizon).WithArgs(90).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := r.PruneChanges(before)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
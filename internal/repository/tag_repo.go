@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+type TagRepo struct {
+	db *gorm.DB
+}
+
+func NewTagRepo(db *gorm.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+func (r *TagRepo) CreateTag(userID int, tag entity.Tag) (int, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	tag.UserID = userID
+
+	if err := tx.Create(&tag).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return tag.ID, tx.Commit().Error
+}
+
+func (r *TagRepo) GetAllTags(userID int) ([]entity.Tag, error) {
+	var tags []entity.Tag
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Order("name").Find(&tags).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tags, tx.Commit().Error
+}
+
+func (r *TagRepo) GetTagByID(userID, tagID int) (entity.Tag, error) {
+	var tag entity.Tag
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return entity.Tag{}, err
+	}
+
+	if err := tx.Where("user_id = ? AND id = ?", userID, tagID).First(&tag).Error; err != nil {
+		tx.Rollback()
+		return entity.Tag{}, err
+	}
+
+	return tag, tx.Commit().Error
+}
+
+// GetTagTaskIDs returns ids of the tasks the user's tag is attached to.
+func (r *TagRepo) GetTagTaskIDs(userID, tagID int) ([]int, error) {
+	var ids []int
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	err := tx.Raw("SELECT task_id FROM task_tags WHERE tag_id IN (SELECT id FROM tags WHERE user_id = ? AND id = ?)", userID, tagID).
+		Scan(&ids).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return ids, tx.Commit().Error
+}
+
+func (r *TagRepo) UpdateTag(userID, tagID int, name string) error {
+	var tag entity.Tag
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ? AND id = ?", userID, tagID).First(&tag).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tag.Name = name
+
+	if err := tx.Save(&tag).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *TagRepo) DeleteTag(userID, tagID int) error {
+	var tag entity.Tag
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ? AND id = ?", userID, tagID).First(&tag).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// сначала связи с задачами, иначе не даст внешний ключ
+	if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(&tag).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// AttachTag links a task the user can edit with user's tag, attaching twice is not an error.
+func (r *TagRepo) AttachTag(userID, taskID, tagID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if _, err := findTask(tx, userID, taskID, entity.RoleEditor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ? AND id = ?", userID, tagID).First(&entity.Tag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", taskID, tagID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *TagRepo) DetachTag(userID, taskID, tagID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if _, err := findTask(tx, userID, taskID, entity.RoleEditor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?", taskID, tagID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
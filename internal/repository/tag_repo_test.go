@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateTag(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTagRepo(gormDB)
+
+	tests := []struct {
+		name     string
+		mock     func()
+		inputTag entity.Tag
+		wantID   int
+		wantErr  bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(5)
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO \"tags\"").WithArgs("work", 1).WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			inputTag: entity.Tag{Name: "work"},
+			wantID:   5,
+			wantErr:  false,
+		},
+		{
+			name: "Duplicate Name",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO \"tags\"").WithArgs("work", 1).WillReturnError(errors.New("duplicate key"))
+				mock.ExpectRollback()
+			},
+			inputTag: entity.Tag{Name: "work"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			id, err := r.CreateTag(1, tt.inputTag)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, id)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAllTags(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTagRepo(gormDB)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(6, "home", 1).AddRow(5, "work", 1)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE user_id = $1 ORDER BY name`)).
+		WithArgs(1).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	got, err := r.GetAllTags(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Tag{{ID: 6, Name: "home", UserID: 1}, {ID: 5, Name: "work", UserID: 1}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTagTaskIDs(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTagRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT task_id FROM task_tags WHERE tag_id IN (SELECT id FROM tags WHERE user_id = $1 AND id = $2)`)).
+		WithArgs(1, 5).WillReturnRows(sqlmock.NewRows([]string{"task_id"}).AddRow(2).AddRow(7))
+	mock.ExpectCommit()
+
+	got, err := r.GetTagTaskIDs(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 7}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTag(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTagRepo(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE user_id = $1 AND id = $2 ORDER BY "tags"."id" LIMIT $3`)).
+		WithArgs(1, 5, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(5, "work", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM task_tags WHERE tag_id = $1`)).
+		WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tags" WHERE "tags"."id" = $1`)).
+		WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.DeleteTag(1, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachTag(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTagRepo(gormDB)
+
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	selectTag := regexp.QuoteMeta(`SELECT * FROM "tags" WHERE user_id = $1 AND id = $2 ORDER BY "tags"."id" LIMIT $3`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 2, entity.RoleEditor)
+				mock.ExpectQuery(selectTask).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(2, 1))
+				mock.ExpectQuery(selectTag).WithArgs(1, 5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`)).
+					WithArgs(2, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Foreign Tag",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 2, entity.RoleEditor)
+				mock.ExpectQuery(selectTask).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(2, 1))
+				mock.ExpectQuery(selectTag).WithArgs(1, 5, 1).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Shared For Viewing",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 2, entity.RoleViewer)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.AttachTag(1, 2, 5)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
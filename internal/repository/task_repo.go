@@ -1,8 +1,20 @@
 package repository
 
 import (
+	"errors"
+	"math"
+	"time"
+
 	"github.com/AronditFire/todo-app/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// positionStep is the gap between neighbours after an append or renumbering,
+// minPositionGap is the smallest gap a move may split before renumbering.
+const (
+	positionStep   = 1.0
+	minPositionGap = 1e-9
 )
 
 type TaskRepo struct {
@@ -25,18 +37,56 @@ func (r *TaskRepo) CreateTask(userID int, task entity.Task) (int, error) {
 		return 0, err
 	}
 
+	id, err := createTask(tx, userID, task)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit().Error
+}
+
+// createTask is CreateTask inside the caller's transaction.
+func createTask(tx *gorm.DB, userID int, task entity.Task) (int, error) {
 	task.UserID = userID
 
+	if task.ProjectID != nil {
+		if err := checkProject(tx, userID, *task.ProjectID); err != nil {
+			return 0, err
+		}
+	}
+
+	if task.ParentID != nil {
+		parent, err := findTask(tx, userID, *task.ParentID, entity.RoleEditor)
+		if err != nil {
+			return 0, err
+		}
+		// всё дерево подзадач принадлежит одному пользователю, даже если подзадачу добавил соавтор
+		task.UserID = parent.UserID
+	}
+
+	// новая задача встаёт в конец ручного списка
+	if task.Position == 0 {
+		var maxPosition float64
+		if err := tx.Model(&entity.Task{}).Where("user_id = ?", task.UserID).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+			return 0, err
+		}
+		task.Position = maxPosition + positionStep
+	}
+
 	if err := tx.Create(&task).Error; err != nil {
-		tx.Rollback()
 		return 0, err
 	}
 
-	return task.ID, tx.Commit().Error
+	if err := recordHistory(tx, userID, entity.ActionCreated, taskChange{after: &task}); err != nil {
+		return 0, err
+	}
 
+	return task.ID, nil
 }
 
-func (r *TaskRepo) GetAllTask(userID int) ([]entity.Task, error) {
+func (r *TaskRepo) GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error) {
 	var tasks []entity.Task
 
 	tx := r.db.Begin()
@@ -50,7 +100,42 @@ func (r *TaskRepo) GetAllTask(userID int) ([]entity.Task, error) {
 		return nil, err
 	}
 
-	if err := tx.Where("user_id = ?", userID).Find(&tasks).Error; err != nil {
+	query := tx.Select(withCommentCountSQL).Where(visibleTasksSQL, userID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.DueFrom.IsZero() {
+		query = query.Where("due_at >= ?", filter.DueFrom)
+	}
+	if !filter.DueTo.IsZero() {
+		query = query.Where("due_at < ?", filter.DueTo)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.ParentID != 0 {
+		query = query.Where("parent_id = ?", filter.ParentID)
+	}
+	if filter.OnlyOpen {
+		query = query.Where("status NOT IN ?", []entity.TaskStatus{entity.StatusDone, entity.StatusCancelled})
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.TagMatch == entity.TagMatchAll {
+			query = query.Where("id IN (SELECT task_id FROM task_tags WHERE tag_id IN ? GROUP BY task_id HAVING COUNT(DISTINCT tag_id) = ?)",
+				filter.TagIDs, len(filter.TagIDs))
+		} else {
+			query = query.Where("id IN (SELECT task_id FROM task_tags WHERE tag_id IN ?)", filter.TagIDs)
+		}
+	}
+
+	if filter.After != nil {
+		query = afterCursor(query, *filter.After)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if err := query.Preload("Tags").Order(orderClause(filter.Order, filter.Desc)).Find(&tasks).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -72,7 +157,12 @@ func (r *TaskRepo) GetTaskByID(userID, id int) (entity.Task, error) {
 		return entity.Task{}, err
 	}
 
-	if err := tx.Where("user_id = ? AND id = ?", userID, id).First(&task).Error; err != nil {
+	if err := requireTaskRole(tx, userID, id, entity.RoleViewer); err != nil {
+		tx.Rollback()
+		return entity.Task{}, err
+	}
+
+	if err := tx.Select(withCommentCountSQL).Preload("Tags").First(&task, id).Error; err != nil {
 		tx.Rollback()
 		return entity.Task{}, err
 	}
@@ -80,9 +170,9 @@ func (r *TaskRepo) GetTaskByID(userID, id int) (entity.Task, error) {
 	return task, tx.Commit().Error
 }
 
-func (r *TaskRepo) UpdateTask(userID, taskId int, desc string) error {
-	var task entity.Task
-
+// UpdateTask changes the description. A non zero version must be the
+// current one, otherwise entity.ErrVersionMismatch is returned.
+func (r *TaskRepo) UpdateTask(userID, taskId int, desc string, version int) error {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -94,11 +184,13 @@ func (r *TaskRepo) UpdateTask(userID, taskId int, desc string) error {
 		return err
 	}
 
-	if err := tx.Where("user_id = ? AND id = ?", userID, taskId).First(&task).Error; err != nil {
+	task, err := findTaskVersion(tx, userID, taskId, entity.RoleEditor, version)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	before := task
 	task.Description = desc
 
 	if err := tx.Save(&task).Error; err != nil {
@@ -106,11 +198,189 @@ func (r *TaskRepo) UpdateTask(userID, taskId int, desc string) error {
 		return err
 	}
 
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
-func (r *TaskRepo) DeleteTask(userID, taskID int) error {
-	var task entity.Task
+// PatchTask locks the task and lets apply change it, everything is written in one
+// transaction. The row is locked even without a version: apply validates the patch
+// against the fields it does not touch. New project and parent are checked the
+// same way as in UpdateTaskProject and UpdateTaskParent, failures are reported
+// as entity.FieldErrors.
+func (r *TaskRepo) PatchTask(userID, taskID, version int, apply func(task *entity.Task) error) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := patchTask(tx, userID, taskID, version, apply); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// patchTask is PatchTask inside the caller's transaction.
+func patchTask(tx *gorm.DB, userID, taskID, version int, apply func(task *entity.Task) error) error {
+	task, err := lockTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		return err
+	}
+	if version != 0 && task.Version != version {
+		return entity.ErrVersionMismatch
+	}
+
+	before := task
+	if err := apply(&task); err != nil {
+		return err
+	}
+
+	if task.ProjectID != nil && !sameID(before.ProjectID, task.ProjectID) {
+		if err := checkProject(tx, userID, *task.ProjectID); err != nil {
+			return referenceError("project_id", err)
+		}
+	}
+	movedProject := !sameID(before.ProjectID, task.ProjectID)
+	if task.ParentID != nil && !sameID(before.ParentID, task.ParentID) {
+		if err := checkParent(tx, userID, task, *task.ParentID); err != nil {
+			return referenceError("parent_id", err)
+		}
+	}
+
+	if err := tx.Save(&task).Error; err != nil {
+		return err
+	}
+
+	if movedProject {
+		if err := dropStaleAssignees(tx, "t.id = ?", task.ID); err != nil {
+			return err
+		}
+	}
+
+	return recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task})
+}
+
+func (r *TaskRepo) UpdateTaskStatus(userID, taskID int, status entity.TaskStatus, completedAt *time.Time) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	task.Status = status
+	task.CompletedAt = completedAt
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *TaskRepo) UpdateTaskDue(userID, taskID int, dueAt, remindAt *time.Time) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	task.DueAt = dueAt
+	task.RemindAt = remindAt
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *TaskRepo) UpdateTaskPriority(userID, taskID, priority int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	task.Priority = priority
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// MoveTask puts the task right before (or after) the anchor task. Only the moved
+// row is written: it gets a position in the middle of the gap next to the anchor.
+// Rows are renumbered only when there is no gap left to split.
+func (r *TaskRepo) MoveTask(userID, taskID, anchorID int, after bool) error {
+	var anchor entity.Task
+
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -122,15 +392,588 @@ func (r *TaskRepo) DeleteTask(userID, taskID int) error {
 		return err
 	}
 
-	if err := tx.Where("user_id = ? AND id = ?", userID, taskID).First(&task).Error; err != nil {
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// ручной порядок у каждого владельца свой, якорь должен быть из того же списка
+	if err := tx.Where("user_id = ? AND id = ?", task.UserID, anchorID).First(&anchor).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	position, ok, err := positionNextTo(tx, task.UserID, task.ID, anchor, after)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if !ok {
+		if err := renumberPositions(tx, task.UserID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.First(&anchor, anchor.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if position, _, err = positionNextTo(tx, task.UserID, task.ID, anchor, after); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	before := task
+	if err := tx.Model(&task).Update("position", position).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+	task.Position = position
 
-	if err := tx.Delete(&task).Error; err != nil {
+	// соседи, сдвинутые перенумерацией, в историю не попадают: их порядок не менялся
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	return tx.Commit().Error
 }
+
+func (r *TaskRepo) UpdateTaskProject(userID, taskID int, projectID *int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	if projectID != nil {
+		if err := checkProject(tx, userID, *projectID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	task.ProjectID = projectID
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := dropStaleAssignees(tx, "t.id = ?", task.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateTaskParent moves the task under another task, nil makes it top level.
+// A task can not become a subtask of itself or of its own descendants,
+// the new parent must belong to the same owner.
+func (r *TaskRepo) UpdateTaskParent(userID, taskID int, parentID *int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	if parentID != nil {
+		if err := checkParent(tx, userID, task, *parentID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	task.ParentID = parentID
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// completeSubtasks marks all open subtasks of any depth of the task as done,
+// the task itself is left to completeOccurrence.
+func completeSubtasks(tx *gorm.DB, userID int, task entity.Task, completedAt time.Time) error {
+	ids, err := subtreeIDs(tx, task.UserID, task.ID)
+	if err != nil {
+		return err
+	}
+
+	// отменённые подзадачи так и остаются отменёнными
+	var open []entity.Task
+	if err := tx.Where("id IN ? AND id <> ? AND status NOT IN ?", ids, task.ID, []entity.TaskStatus{entity.StatusDone, entity.StatusCancelled}).
+		Order("id").Find(&open).Error; err != nil {
+		return err
+	}
+	if len(open) == 0 {
+		return nil
+	}
+
+	openIDs := make([]int, len(open))
+	changes := make([]taskChange, len(open))
+	for i := range open {
+		openIDs[i] = open[i].ID
+
+		done := open[i]
+		done.Status = entity.StatusDone
+		done.CompletedAt = &completedAt
+		changes[i] = taskChange{before: &open[i], after: &done}
+	}
+
+	if err := tx.Model(&entity.Task{}).Where("id IN ?", openIDs).
+		Updates(map[string]any{"status": entity.StatusDone, "completed_at": completedAt}).Error; err != nil {
+		return err
+	}
+
+	return recordHistory(tx, userID, entity.ActionUpdated, changes...)
+}
+
+func (r *TaskRepo) UpdateTaskRecurrence(userID, taskID int, rule string) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	task, err := findTask(tx, userID, taskID, entity.RoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before := task
+	task.Recurrence = rule
+
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// completeOccurrence marks the locked task as done and creates the next
+// occurrence with the same tags, next may be nil. The rule moves to the new
+// task, so completing the old one again does not produce duplicates. Both are
+// recorded in the history on behalf of userID.
+func completeOccurrence(tx *gorm.DB, userID int, task entity.Task, completedAt time.Time, next *entity.Task) (int, error) {
+	before := task
+	task.Status = entity.StatusDone
+	task.CompletedAt = &completedAt
+	task.Recurrence = ""
+
+	if err := tx.Save(&task).Error; err != nil {
+		return 0, err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionUpdated, taskChange{before: &before, after: &task}); err != nil {
+		return 0, err
+	}
+
+	if next == nil {
+		return 0, nil
+	}
+
+	var maxPosition float64
+	if err := tx.Model(&entity.Task{}).Where("user_id = ?", task.UserID).
+		Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+		return 0, err
+	}
+
+	next.UserID = task.UserID
+	next.Position = maxPosition + positionStep
+
+	if err := tx.Create(next).Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT ?, tag_id FROM task_tags WHERE task_id = ?",
+		next.ID, task.ID).Error; err != nil {
+		return 0, err
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionCreated, taskChange{after: next}); err != nil {
+		return 0, err
+	}
+
+	return next.ID, nil
+}
+
+// DeleteTask moves the task together with all its subtasks to the trash.
+// The whole subtree gets the same deleted_at, that is how RestoreTask finds it.
+// A non zero version is checked like in UpdateTask.
+func (r *TaskRepo) DeleteTask(userID, taskID, version int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := deleteTask(tx, userID, taskID, version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// deleteTask is DeleteTask inside the caller's transaction.
+func deleteTask(tx *gorm.DB, userID, taskID, version int) error {
+	task, err := findTaskVersion(tx, userID, taskID, entity.RoleOwner, version)
+	if err != nil {
+		return err
+	}
+
+	ids, err := subtreeIDs(tx, task.UserID, task.ID)
+	if err != nil {
+		return err
+	}
+
+	var deleted []entity.Task
+	if err := tx.Where("id IN ?", ids).Order("id").Find(&deleted).Error; err != nil {
+		return err
+	}
+
+	// связи с тегами остаются, чтобы восстановленная задача вернулась с тегами
+	if err := tx.Where("id IN ?", ids).Delete(&entity.Task{}).Error; err != nil {
+		return err
+	}
+
+	changes := make([]taskChange, len(deleted))
+	for i := range deleted {
+		changes[i] = taskChange{before: &deleted[i]}
+	}
+
+	return recordHistory(tx, userID, entity.ActionDeleted, changes...)
+}
+
+// findTaskVersion is findTask for a request with If-Match. The row stays locked
+// until the end of the transaction, so nobody can change it between the check
+// and the write. Version 0 means no precondition.
+func findTaskVersion(tx *gorm.DB, userID, taskID int, need entity.Role, version int) (entity.Task, error) {
+	task, err := lockTask(tx, userID, taskID, need)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	if version != 0 && task.Version != version {
+		return entity.Task{}, entity.ErrVersionMismatch
+	}
+
+	return task, nil
+}
+
+// lockTask is findTask that also locks the row until the end of the transaction.
+func lockTask(tx *gorm.DB, userID, taskID int, need entity.Role) (entity.Task, error) {
+	if err := requireTaskRole(tx, userID, taskID, need); err != nil {
+		return entity.Task{}, err
+	}
+
+	var task entity.Task
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, taskID).Error; err != nil {
+		return entity.Task{}, err
+	}
+
+	return task, nil
+}
+
+// errParentCycle is returned when the new parent is the task itself or one of its subtasks.
+var errParentCycle = errors.New("task can not be moved under its own subtask")
+
+// checkParent makes sure the task can be moved under parentID: the user may
+// edit the parent, it belongs to the same owner and is not a descendant of the task.
+func checkParent(tx *gorm.DB, userID int, task entity.Task, parentID int) error {
+	if err := requireTaskRole(tx, userID, parentID, entity.RoleEditor); err != nil {
+		return err
+	}
+
+	// поднимаемся от нового родителя к корню, по дороге не должно встретиться самой задачи
+	for next := &parentID; next != nil; {
+		if *next == task.ID {
+			return errParentCycle
+		}
+
+		var ancestor entity.Task
+		if err := tx.Where("user_id = ? AND id = ?", task.UserID, *next).First(&ancestor).Error; err != nil {
+			return err
+		}
+		next = ancestor.ParentID
+	}
+
+	return nil
+}
+
+// referenceError reports a failed check of a referenced project or parent
+// on the field of the patch, database failures are returned as they are.
+func referenceError(field string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return entity.FieldErrors{field: "not found"}
+	case errors.Is(err, entity.ErrForbidden):
+		return entity.FieldErrors{field: "not editable by you"}
+	case errors.Is(err, errProjectArchived):
+		return entity.FieldErrors{field: "project is archived"}
+	case errors.Is(err, errParentCycle):
+		return entity.FieldErrors{field: "can not be a subtask of the task"}
+	}
+	return err
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// MigrateVersion makes every update of a task row bump its version, whatever
+// query changed it, so a version seen by a client really means "unchanged".
+// What a task is served with besides its row bumps it too: its tags and
+// their names, the number of its comments and the status of its subtasks.
+func MigrateVersion(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION bump_task_version() RETURNS trigger AS $$
+		BEGIN
+			NEW.version := OLD.version + 1;
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_version BEFORE UPDATE ON tasks
+			FOR EACH ROW EXECUTE FUNCTION bump_task_version()`,
+
+		// пустое обновление: версию поднимает tasks_version, журнал изменений пишут его триггеры
+		`CREATE OR REPLACE FUNCTION touch_tasks(ids bigint[]) RETURNS void AS $$
+			UPDATE tasks SET version = version WHERE id = ANY(ids) AND deleted_at IS NULL
+		$$ LANGUAGE sql`,
+
+		`CREATE OR REPLACE FUNCTION touch_changed_tasks() RETURNS trigger AS $$
+		BEGIN
+			PERFORM touch_tasks(ARRAY(SELECT DISTINCT task_id FROM changed));
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER task_tags_version_insert AFTER INSERT ON task_tags
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION touch_changed_tasks()`,
+		`CREATE OR REPLACE TRIGGER task_tags_version_delete AFTER DELETE ON task_tags
+			REFERENCING OLD TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION touch_changed_tasks()`,
+		`CREATE OR REPLACE TRIGGER comments_version_insert AFTER INSERT ON comments
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION touch_changed_tasks()`,
+		`CREATE OR REPLACE TRIGGER comments_version_delete AFTER DELETE ON comments
+			REFERENCING OLD TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION touch_changed_tasks()`,
+
+		`CREATE OR REPLACE FUNCTION touch_renamed_tags() RETURNS trigger AS $$
+		BEGIN
+			PERFORM touch_tasks(ARRAY(SELECT DISTINCT task_id FROM task_tags WHERE tag_id IN (SELECT id FROM changed)));
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tags_version_update AFTER UPDATE ON tags
+			REFERENCING NEW TABLE AS changed FOR EACH STATEMENT EXECUTE FUNCTION touch_renamed_tags()`,
+
+		// прогресс родителя меняется, когда подзадача появляется, уходит или меняет статус;
+		// обновление одной версии родителя этот триггер не будит
+		`CREATE OR REPLACE FUNCTION touch_parent_task() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'INSERT' THEN
+				PERFORM touch_tasks(ARRAY[NEW.parent_id]);
+			ELSIF TG_OP = 'DELETE' THEN
+				PERFORM touch_tasks(ARRAY[OLD.parent_id]);
+			ELSE
+				PERFORM touch_tasks(ARRAY[OLD.parent_id, NEW.parent_id]);
+			END IF;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER tasks_parent_version_insert AFTER INSERT ON tasks
+			FOR EACH ROW WHEN (NEW.parent_id IS NOT NULL) EXECUTE FUNCTION touch_parent_task()`,
+		`CREATE OR REPLACE TRIGGER tasks_parent_version_delete AFTER DELETE ON tasks
+			FOR EACH ROW WHEN (OLD.parent_id IS NOT NULL) EXECUTE FUNCTION touch_parent_task()`,
+		`CREATE OR REPLACE TRIGGER tasks_parent_version_update AFTER UPDATE ON tasks
+			FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.parent_id IS DISTINCT FROM NEW.parent_id
+				OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+			EXECUTE FUNCTION touch_parent_task()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orderClause(order entity.TaskOrder, desc bool) string {
+	dir := ""
+	if desc {
+		dir = " DESC"
+	}
+
+	switch order.OrDefault() {
+	case entity.OrderPriority:
+		return "priority" + dir + ", position" + dir + ", id" + dir
+	case entity.OrderID:
+		return "id" + dir
+	case entity.OrderCreatedAt:
+		return "created_at" + dir + ", id" + dir
+	case entity.OrderDueAt:
+		// задачи без срока в конце при любом направлении
+		return "due_at IS NULL, due_at" + dir + ", id" + dir
+	default:
+		return "position" + dir + ", id" + dir
+	}
+}
+
+// afterCursor keeps only tasks that go after the cursor in its sort order.
+// The id is the last sort key everywhere, so the order is total and
+// pages neither overlap nor skip tasks.
+func afterCursor(query *gorm.DB, c entity.TaskCursor) *gorm.DB {
+	op := ">"
+	if c.Desc {
+		op = "<"
+	}
+
+	switch c.Order {
+	case entity.OrderPriority:
+		return query.Where("(priority, position, id) "+op+" (?, ?, ?)", c.Priority, c.Position, c.ID)
+	case entity.OrderID:
+		return query.Where("id "+op+" ?", c.ID)
+	case entity.OrderCreatedAt:
+		return query.Where("(created_at, id) "+op+" (?, ?)", c.Time, c.ID)
+	case entity.OrderDueAt:
+		if c.Time == nil {
+			return query.Where("due_at IS NULL AND id "+op+" ?", c.ID)
+		}
+		return query.Where("(due_at IS NOT NULL AND (due_at, id) "+op+" (?, ?)) OR due_at IS NULL", c.Time, c.ID)
+	default:
+		return query.Where("(position, id) "+op+" (?, ?)", c.Position, c.ID)
+	}
+}
+
+// positionNextTo returns a free position between the anchor and its neighbour.
+// ok is false when the anchor shares its position with another row or the gap
+// is too small, then positions have to be renumbered first.
+func positionNextTo(tx *gorm.DB, userID, taskID int, anchor entity.Task, after bool) (float64, bool, error) {
+	var ties int64
+	if err := tx.Model(&entity.Task{}).
+		Where("user_id = ? AND id NOT IN ? AND position = ?", userID, []int{taskID, anchor.ID}, anchor.Position).
+		Count(&ties).Error; err != nil {
+		return 0, false, err
+	}
+	if ties > 0 {
+		return 0, false, nil
+	}
+
+	var neighbours []entity.Task
+	query := tx.Where("user_id = ? AND id <> ?", userID, taskID)
+	if after {
+		query = query.Where("position > ?", anchor.Position).Order("position ASC")
+	} else {
+		query = query.Where("position < ?", anchor.Position).Order("position DESC")
+	}
+	if err := query.Limit(1).Find(&neighbours).Error; err != nil {
+		return 0, false, err
+	}
+
+	if len(neighbours) == 0 {
+		if after {
+			return anchor.Position + positionStep, true, nil
+		}
+		return anchor.Position - positionStep, true, nil
+	}
+
+	neighbour := neighbours[0].Position
+	if math.Abs(anchor.Position-neighbour) < minPositionGap {
+		return 0, false, nil
+	}
+
+	return (anchor.Position + neighbour) / 2, true, nil
+}
+
+// renumberPositions spreads user's tasks evenly keeping the current order.
+func renumberPositions(tx *gorm.DB, userID int) error {
+	var ids []int
+	if err := tx.Model(&entity.Task{}).Where("user_id = ?", userID).
+		Order("position, id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if err := tx.Model(&entity.Task{}).Where("id = ?", id).
+			Update("position", float64(i+1)*positionStep).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subtreeIDs returns ids of the task and all of its subtasks of any depth,
+// subtasks that are already in the trash are skipped.
+func subtreeIDs(tx *gorm.DB, userID, taskID int) ([]int, error) {
+	var ids []int
+
+	err := tx.Raw(`WITH RECURSIVE subtree AS (
+		SELECT id FROM tasks WHERE user_id = ? AND id = ?
+		UNION
+		SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.user_id = ? AND t.deleted_at IS NULL
+	) SELECT id FROM subtree`, userID, taskID, userID).Scan(&ids).Error
+
+	return ids, err
+}
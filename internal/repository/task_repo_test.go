@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/AronditFire/todo-app/entity"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -29,7 +31,11 @@ func TestCreateTask(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectBegin()
-				mock.ExpectQuery("INSERT INTO \"tasks\"").WithArgs("Test Task", 1).WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks" WHERE user_id = $1 AND "tasks"."deleted_at" IS NULL`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2.5))
+				mock.ExpectQuery("INSERT INTO \"tasks\"").WithArgs("Test Task", 1, "todo", nil, nil, nil, 4, 3.5, nil, nil, "", 1, nil).WillReturnRows(rows)
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").WithArgs(1, 1, "created", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
 			inputUserID: 1,
@@ -52,7 +58,9 @@ func TestCreateTask(t *testing.T) {
 			name: "InsertError",
 			mock: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery("INSERT INTO \"tasks\"").WithArgs("Test Task", 1).WillReturnError(errors.New("error"))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks" WHERE user_id = $1 AND "tasks"."deleted_at" IS NULL`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectQuery("INSERT INTO \"tasks\"").WithArgs("Test Task", 1, "todo", nil, nil, nil, 4, 1.0, nil, nil, "", 1, nil).WillReturnError(errors.New("error"))
 				mock.ExpectRollback()
 			},
 			inputUserID: 1,
@@ -94,6 +102,8 @@ func TestTask_CreateTask_PanicRecovery(t *testing.T) {
 
 	// Мокируем транзакцию и ожидаем откат
 	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks" WHERE user_id = $1 AND "tasks"."deleted_at" IS NULL`)).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
 	mock.ExpectRollback()
 
 	// Вызываем — внутри должен произойти panic, но благодаря defer+recover
@@ -115,11 +125,14 @@ func TestGellAllTasks(t *testing.T) {
 	defer sqlDB.Close()
 
 	r := NewTaskRepo(gormDB)
+	dueFrom := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	dueTo := dueFrom.AddDate(0, 0, 1)
 
 	tests := []struct {
 		name        string
 		mock        func()
 		inputUserID int
+		inputFilter entity.TaskFilter
 		wantTasks   []entity.Task
 		wantErr     bool
 	}{
@@ -132,8 +145,14 @@ func TestGellAllTasks(t *testing.T) {
 				// GORM при Find генерирует примерно такой запрос:
 				// SELECT * FROM "tasks" WHERE user_id = $1 ORDER BY "tasks"."id"
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1`),
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND "tasks"."deleted_at" IS NULL ORDER BY position, id`),
 				).WithArgs(1).WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" IN ($1,$2)`),
+				).WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}).AddRow(1, 5))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tags" WHERE "tags"."id" = $1`),
+				).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(5, "work", 1))
 				mock.ExpectCommit()
 			},
 			inputUserID: 1,
@@ -142,11 +161,95 @@ func TestGellAllTasks(t *testing.T) {
 					ID:          1,
 					Description: "Test Task 1",
 					UserID:      1,
+					Tags:        []entity.Tag{{ID: 5, Name: "work", UserID: 1}},
+				},
+				{
+					ID:          2,
+					Description: "Test Task 2",
+					UserID:      1,
+					Tags:        []entity.Tag{},
 				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Status Filter",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).
+					AddRow(2, "Test Task 2", 1, "done")
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND status = $2 AND "tasks"."deleted_at" IS NULL ORDER BY priority, position, id`),
+				).WithArgs(1, "done").WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`),
+				).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectCommit()
+			},
+			inputUserID: 1,
+			inputFilter: entity.TaskFilter{Status: entity.StatusDone, Order: entity.OrderPriority},
+			wantTasks: []entity.Task{
 				{
 					ID:          2,
 					Description: "Test Task 2",
 					UserID:      1,
+					Status:      entity.StatusDone,
+					Tags:        []entity.Tag{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Open Tasks Due In Range",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).
+					AddRow(3, "Test Task 3", 1, "todo")
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND due_at >= $2 AND due_at < $3 AND status NOT IN ($4,$5) AND "tasks"."deleted_at" IS NULL ORDER BY position, id`),
+				).WithArgs(1, dueFrom, dueTo, "done", "cancelled").WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`),
+				).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+				mock.ExpectCommit()
+			},
+			inputUserID: 1,
+			inputFilter: entity.TaskFilter{DueFrom: dueFrom, DueTo: dueTo, OnlyOpen: true},
+			wantTasks: []entity.Task{
+				{
+					ID:          3,
+					Description: "Test Task 3",
+					UserID:      1,
+					Status:      entity.StatusTodo,
+					Tags:        []entity.Tag{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "All Of Tags",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(4, "Test Task 4", 1)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND id IN (SELECT task_id FROM task_tags WHERE tag_id IN ($2,$3) GROUP BY task_id HAVING COUNT(DISTINCT tag_id) = $4) AND "tasks"."deleted_at" IS NULL ORDER BY position, id`),
+				).WithArgs(1, 5, 6, 2).WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`),
+				).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}).AddRow(4, 5).AddRow(4, 6))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tags" WHERE "tags"."id" IN ($1,$2)`),
+				).WithArgs(5, 6).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(5, "work", 1).AddRow(6, "home", 1))
+				mock.ExpectCommit()
+			},
+			inputUserID: 1,
+			inputFilter: entity.TaskFilter{TagIDs: []int{5, 6}, TagMatch: entity.TagMatchAll},
+			wantTasks: []entity.Task{
+				{
+					ID:          4,
+					Description: "Test Task 4",
+					UserID:      1,
+					Tags:        []entity.Tag{{ID: 5, Name: "work", UserID: 1}, {ID: 6, Name: "home", UserID: 1}},
 				},
 			},
 			wantErr: false,
@@ -165,7 +268,7 @@ func TestGellAllTasks(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1`)).
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND "tasks"."deleted_at" IS NULL ORDER BY position, id`)).
 					WithArgs(1).WillReturnError(errors.New("Select Error"))
 				mock.ExpectRollback()
 			},
@@ -178,7 +281,7 @@ func TestGellAllTasks(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			got, err := r.GetAllTask(tt.inputUserID)
+			got, err := r.GetAllTask(tt.inputUserID, tt.inputFilter)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -212,7 +315,7 @@ func TestTask_GetAllTasks_PanicRecovery(t *testing.T) {
 	// Вызываем — внутри должен произойти panic, но благодаря defer+recover
 	// функция вернёт nil и не «упадёт» в тесте.
 	assert.NotPanics(t, func() {
-		got, err := r.GetAllTask(1)
+		got, err := r.GetAllTask(1, entity.TaskFilter{})
 		assert.Equal(t, []entity.Task(nil), got)
 		assert.NoError(t, err)
 	})
@@ -243,11 +346,17 @@ func TestGetTaskByID(t *testing.T) {
 					AddRow(1, "test", 1)
 				mock.ExpectBegin()
 
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`,
+				)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
 				mock.ExpectCommit()
 			},
 			inputUserID: 1,
@@ -256,6 +365,7 @@ func TestGetTaskByID(t *testing.T) {
 				ID:          1,
 				Description: "test",
 				UserID:      1,
+				Tags:        []entity.Tag{},
 			},
 			wantErr: false,
 		},
@@ -273,10 +383,11 @@ func TestGetTaskByID(t *testing.T) {
 			name: "Select Error",
 			mock: func() {
 				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnError(errors.New("Select Error"))
 				mock.ExpectRollback()
 			},
@@ -318,6 +429,7 @@ func TestTask_GetTaskByID_PanicRecovery(t *testing.T) {
 
 	// Мокируем транзакцию и ожидаем откат
 	mock.ExpectBegin()
+	expectTaskRole(mock, 1, 1, entity.RoleOwner)
 	mock.ExpectRollback()
 
 	// Вызываем — внутри должен произойти panic, но благодаря defer+recover
@@ -338,12 +450,13 @@ func TestUpdateTask(t *testing.T) {
 
 	r := NewTaskRepo(gormDB)
 	tests := []struct {
-		name        string
-		mock        func()
-		inputUserID int
-		inputTaskID int
-		inputDesc   string
-		wantErr     bool
+		name         string
+		mock         func()
+		inputUserID  int
+		inputTaskID  int
+		inputDesc    string
+		inputVersion int
+		wantErr      bool
 	}{
 		{
 			name: "Success",
@@ -351,16 +464,21 @@ func TestUpdateTask(t *testing.T) {
 				// Expect single transaction lifecycle
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1)
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnRows(rows)
 				mock.ExpectExec(regexp.QuoteMeta(
-					`UPDATE "tasks" SET "description"=$1,"user_id"=$2 WHERE "id" = $3`,
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
 				)).
-					WithArgs("Updated Task", 1, 1).
+					WithArgs("Updated Task", 1, "", nil, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				// в истории только изменившееся поле
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(1, 1, "updated", []byte(`{"description":"Test Task"}`), []byte(`{"description":"Updated Task"}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
 			inputUserID: 1,
@@ -382,10 +500,11 @@ func TestUpdateTask(t *testing.T) {
 			name: "Select Error",
 			mock: func() {
 				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnError(errors.New("Select Error"))
 				mock.ExpectRollback()
 			},
@@ -399,15 +518,16 @@ func TestUpdateTask(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1)
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnRows(rows)
 				mock.ExpectExec(regexp.QuoteMeta(
-					`UPDATE "tasks" SET "description"=$1,"user_id"=$2 WHERE "id" = $3`,
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
 				)).
-					WithArgs("Updated Task", 1, 1).
+					WithArgs("Updated Task", 1, "", nil, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
 					WillReturnError(errors.New("Update Error"))
 				mock.ExpectRollback()
 			},
@@ -416,13 +536,54 @@ func TestUpdateTask(t *testing.T) {
 			inputDesc:   "Updated Task",
 			wantErr:     true,
 		},
+		{
+			name: "If-Match Current Version",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`,
+				)).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "version"}).AddRow(1, "Test Task", 1, 3))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "description"=$1`)).
+					WithArgs("Updated Task", 1, "", nil, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 3, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			inputUserID:  1,
+			inputTaskID:  1,
+			inputDesc:    "Updated Task",
+			inputVersion: 3,
+			wantErr:      false,
+		},
+		{
+			name: "If-Match Old Version",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`,
+				)).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "version"}).AddRow(1, "Changed", 1, 4))
+				mock.ExpectRollback()
+			},
+			inputUserID:  1,
+			inputTaskID:  1,
+			inputDesc:    "Updated Task",
+			inputVersion: 3,
+			wantErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := r.UpdateTask(tt.inputUserID, tt.inputTaskID, tt.inputDesc)
+			err := r.UpdateTask(tt.inputUserID, tt.inputTaskID, tt.inputDesc, tt.inputVersion)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -449,12 +610,13 @@ func TestTask_UpdateTask_PanicRecovery(t *testing.T) {
 
 	// Мокируем транзакцию и ожидаем откат
 	mock.ExpectBegin()
+	expectTaskRole(mock, 1, 1, entity.RoleOwner)
 	mock.ExpectRollback()
 
 	// Вызываем — внутри должен произойти panic, но благодаря defer+recover
 	// функция вернёт nil и не «упадёт» в тесте.
 	assert.NotPanics(t, func() {
-		err := r.UpdateTask(1, 1, "Updated Task")
+		err := r.UpdateTask(1, 1, "Updated Task", 0)
 		assert.NoError(t, err)
 	})
 
@@ -462,6 +624,286 @@ func TestTask_UpdateTask_PanicRecovery(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateTaskStatus(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+	completedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		mock             func()
+		inputUserID      int
+		inputTaskID      int
+		inputStatus      entity.TaskStatus
+		inputCompletedAt *time.Time
+		wantErr          bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).AddRow(1, "Test Task", 1, "todo")
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
+				)).
+					WithArgs(1, 1).
+					WillReturnRows(rows)
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+				)).
+					WithArgs("Test Task", 1, "done", completedAt, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(1, 1, "updated", []byte(`{"completed_at":null,"status":"todo"}`), []byte(`{"completed_at":"2025-05-01T12:00:00Z","status":"done"}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			inputUserID:      1,
+			inputTaskID:      1,
+			inputStatus:      entity.StatusDone,
+			inputCompletedAt: &completedAt,
+			wantErr:          false,
+		},
+		{
+			name: "Select Error",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
+				)).
+					WithArgs(1, 1).
+					WillReturnError(errors.New("Select Error"))
+				mock.ExpectRollback()
+			},
+			inputUserID: 1,
+			inputTaskID: 1,
+			inputStatus: entity.StatusTodo,
+			wantErr:     true,
+		},
+		{
+			name: "Update Error",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).AddRow(1, "Test Task", 1, "done")
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
+				)).
+					WithArgs(1, 1).
+					WillReturnRows(rows)
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+				)).
+					WithArgs("Test Task", 1, "todo", nil, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
+					WillReturnError(errors.New("Update Error"))
+				mock.ExpectRollback()
+			},
+			inputUserID: 1,
+			inputTaskID: 1,
+			inputStatus: entity.StatusTodo,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateTaskStatus(tt.inputUserID, tt.inputTaskID, tt.inputStatus, tt.inputCompletedAt)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateTaskDue(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+	dueAt := time.Date(2025, 5, 1, 15, 0, 0, 0, time.UTC)
+	remindAt := dueAt.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		mock          func()
+		inputDueAt    *time.Time
+		inputRemindAt *time.Time
+		wantErr       bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).AddRow(1, "Test Task", 1, "todo")
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
+				)).
+					WithArgs(1, 1).
+					WillReturnRows(rows)
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+				)).
+					WithArgs("Test Task", 1, "todo", nil, dueAt, remindAt, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(1, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			inputDueAt:    &dueAt,
+			inputRemindAt: &remindAt,
+			wantErr:       false,
+		},
+		{
+			name: "Select Error",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
+				)).
+					WithArgs(1, 1).
+					WillReturnError(errors.New("Select Error"))
+				mock.ExpectRollback()
+			},
+			inputDueAt: &dueAt,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateTaskDue(1, 1, tt.inputDueAt, tt.inputRemindAt)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	selectAnchor := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (user_id = $1 AND id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`)
+	countTies := regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE (user_id = $1 AND id NOT IN ($2,$3) AND position = $4) AND "tasks"."deleted_at" IS NULL`)
+	selectPrev := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (user_id = $1 AND id <> $2) AND position < $3 AND "tasks"."deleted_at" IS NULL ORDER BY position DESC LIMIT $4`)
+	updatePosition := regexp.QuoteMeta(`UPDATE "tasks" SET "position"=$1 WHERE "tasks"."deleted_at" IS NULL AND "id" = $2`)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Between Neighbours",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(3, 1, 3.0))
+				mock.ExpectQuery(selectAnchor).WithArgs(1, 2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(2, 1, 2.0))
+				mock.ExpectQuery(countTies).WithArgs(1, 3, 2, 2.0).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(selectPrev).WithArgs(1, 3, 2.0, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(1, 1, 1.0))
+				mock.ExpectExec(updatePosition).WithArgs(1.5, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(3, 1, "updated", []byte(`{"position":3}`), []byte(`{"position":1.5}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Renumber On Ties",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(3, 1, 0.0))
+				mock.ExpectQuery(selectAnchor).WithArgs(1, 2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(2, 1, 0.0))
+				mock.ExpectQuery(countTies).WithArgs(1, 3, 2, 0.0).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tasks" WHERE user_id = $1 AND "tasks"."deleted_at" IS NULL ORDER BY position, id`)).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+				for i, id := range []int{1, 2, 3} {
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "position"=$1 WHERE id = $2 AND "tasks"."deleted_at" IS NULL`)).
+						WithArgs(float64(i+1), id).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL AND "tasks"."id" = $2 ORDER BY "tasks"."id" LIMIT $3`)).
+					WithArgs(2, 2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(2, 1, 2.0))
+				mock.ExpectQuery(countTies).WithArgs(1, 3, 2, 2.0).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(selectPrev).WithArgs(1, 3, 2.0, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(1, 1, 1.0))
+				mock.ExpectExec(updatePosition).WithArgs(1.5, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(3, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Anchor Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 3, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "position"}).AddRow(3, 1, 3.0))
+				mock.ExpectQuery(selectAnchor).WithArgs(1, 2, 1).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.MoveTask(1, 3, 2, false)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestDeleteTask(t *testing.T) {
 	sqlDB, gormDB, mock := DbMock(t)
 	defer sqlDB.Close()
@@ -480,16 +922,29 @@ func TestDeleteTask(t *testing.T) {
 				// Expect single transaction lifecycle
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1)
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnRows(rows)
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(1, 1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE id IN ($1,$2) AND "tasks"."deleted_at" IS NULL ORDER BY id`,
+				)).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1).AddRow(2, "Subtask", 1))
 				mock.ExpectExec(regexp.QuoteMeta(
-					`DELETE FROM "tasks" WHERE "tasks"."id" = $1`,
+					`UPDATE "tasks" SET "deleted_at"=$1 WHERE id IN ($2,$3) AND "tasks"."deleted_at" IS NULL`,
 				)).
-					WithArgs(1).
+					WithArgs(sqlmock.AnyArg(), 1, 2).
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				// одна запись на каждую задачу поддерева
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(1, 1, "deleted", sqlmock.AnyArg(), 2, 1, "deleted", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
 				mock.ExpectCommit()
 			},
 			inputUserID: 1,
@@ -509,10 +964,11 @@ func TestDeleteTask(t *testing.T) {
 			name: "Select Error",
 			mock: func() {
 				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnError(errors.New("Select Error"))
 				mock.ExpectRollback()
 			},
@@ -525,15 +981,24 @@ func TestDeleteTask(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1)
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
 				mock.ExpectQuery(regexp.QuoteMeta(
-					`SELECT * FROM "tasks" WHERE user_id = $1 AND id = $2 ORDER BY "tasks"."id" LIMIT $3`,
+					`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`,
 				)).
-					WithArgs(1, 1, 1).
+					WithArgs(1, 1).
 					WillReturnRows(rows)
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(1, 1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT * FROM "tasks" WHERE id IN ($1,$2) AND "tasks"."deleted_at" IS NULL ORDER BY id`,
+				)).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}).AddRow(1, "Test Task", 1).AddRow(2, "Subtask", 1))
 				mock.ExpectExec(regexp.QuoteMeta(
-					`DELETE FROM "tasks" WHERE "tasks"."id" = $1`,
+					`UPDATE "tasks" SET "deleted_at"=$1 WHERE id IN ($2,$3) AND "tasks"."deleted_at" IS NULL`,
 				)).
-					WithArgs(1).
+					WithArgs(sqlmock.AnyArg(), 1, 2).
 					WillReturnError(errors.New("Delete Error"))
 				mock.ExpectRollback()
 			},
@@ -547,7 +1012,7 @@ func TestDeleteTask(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := r.DeleteTask(tt.inputUserID, tt.inputTaskID)
+			err := r.DeleteTask(tt.inputUserID, tt.inputTaskID, 0)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -574,15 +1039,510 @@ func TestTask_DeleteTask_PanicRecovery(t *testing.T) {
 
 	// Мокируем транзакцию и ожидаем откат
 	mock.ExpectBegin()
+	expectTaskRole(mock, 1, 1, entity.RoleOwner)
 	mock.ExpectRollback()
 
 	// Вызываем — внутри должен произойти panic, но благодаря defer+recover
 	// функция вернёт nil и не «упадёт» в тесте.
 	assert.NotPanics(t, func() {
-		err := r.DeleteTask(1, 1)
+		err := r.DeleteTask(1, 1, 0)
 		assert.NoError(t, err)
 	})
 
 	// Проверяем, что Rollback действительно вызывался
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdateTaskParent(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	selectTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2`)
+	selectAncestor := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (user_id = $1 AND id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`)
+	taskRow := func(id int, parentID any) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "description", "user_id", "parent_id"}).AddRow(id, "Test Task", 1, parentID)
+	}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		parentID int
+		wantErr  bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, entity.RoleOwner)
+				mock.ExpectQuery(selectAncestor).WithArgs(1, 2, 1).WillReturnRows(taskRow(2, 3))
+				mock.ExpectQuery(selectAncestor).WithArgs(1, 3, 1).WillReturnRows(taskRow(3, nil))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+				)).
+					WithArgs("Test Task", 1, "", nil, nil, nil, 0, 0.0, nil, 2, "", sqlmock.AnyArg(), 0, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(1, 1, "updated", []byte(`{"parent_id":null}`), []byte(`{"parent_id":2}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			parentID: 2,
+			wantErr:  false,
+		},
+		{
+			name: "Cycle",
+			mock: func() {
+				// 2 уже лежит под 1, значит 1 не может стать подзадачей 2
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, entity.RoleOwner)
+				mock.ExpectQuery(selectAncestor).WithArgs(1, 2, 1).WillReturnRows(taskRow(2, 1))
+				mock.ExpectRollback()
+			},
+			parentID: 2,
+			wantErr:  true,
+		},
+		{
+			name: "Parent Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, "")
+				mock.ExpectRollback()
+			},
+			parentID: 2,
+			wantErr:  true,
+		},
+		{
+			name: "Parent Only Viewed",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleOwner)
+				mock.ExpectQuery(selectTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, entity.RoleViewer)
+				mock.ExpectRollback()
+			},
+			parentID: 2,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateTaskParent(1, 1, &tt.parentID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+
+	lockTask := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`)
+	selectAncestor := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (user_id = $1 AND id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`)
+	taskRow := func(id int, parentID any) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "description", "user_id", "parent_id", "version"}).AddRow(id, "Test Task", 1, parentID, 3)
+	}
+	applyErr := errors.New("Apply Error")
+
+	tests := []struct {
+		name    string
+		mock    func()
+		version int
+		apply   func(task *entity.Task) error
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(lockTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, entity.RoleOwner)
+				mock.ExpectQuery(selectAncestor).WithArgs(1, 2, 1).WillReturnRows(taskRow(2, nil))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+				)).
+					WithArgs("Swim", 1, "", nil, nil, nil, 1, 0.0, nil, 2, "", sqlmock.AnyArg(), 3, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			version: 3,
+			apply: func(task *entity.Task) error {
+				parentID := 2
+				task.Description = "Swim"
+				task.Priority = 1
+				task.ParentID = &parentID
+				return nil
+			},
+		},
+		{
+			name: "Old Version",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(lockTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				mock.ExpectRollback()
+			},
+			version: 2,
+			apply:   func(task *entity.Task) error { return nil },
+			wantErr: entity.ErrVersionMismatch,
+		},
+		{
+			name: "Invalid Patch",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(lockTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				mock.ExpectRollback()
+			},
+			apply:   func(task *entity.Task) error { return applyErr },
+			wantErr: applyErr,
+		},
+		{
+			name: "Parent Is Subtask",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(lockTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectTaskRole(mock, 1, 2, entity.RoleOwner)
+				mock.ExpectQuery(selectAncestor).WithArgs(1, 2, 1).WillReturnRows(taskRow(2, 1))
+				mock.ExpectRollback()
+			},
+			apply: func(task *entity.Task) error {
+				parentID := 2
+				task.ParentID = &parentID
+				return nil
+			},
+			wantErr: entity.FieldErrors{"parent_id": "can not be a subtask of the task"},
+		},
+		{
+			name: "Project Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 1, entity.RoleEditor)
+				mock.ExpectQuery(lockTask).WithArgs(1, 1).WillReturnRows(taskRow(1, nil))
+				expectProjectRole(mock, 1, 5, "")
+				mock.ExpectRollback()
+			},
+			apply: func(task *entity.Task) error {
+				projectID := 5
+				task.ProjectID = &projectID
+				return nil
+			},
+			wantErr: entity.FieldErrors{"project_id": "not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.PatchTask(1, 1, tt.version, tt.apply)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCompleteSubtasks(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+	completedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectTaskRole(mock, 1, 1, entity.RoleOwner)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).AddRow(1, "Test Task", 1, "todo"))
+	mock.ExpectQuery("WITH RECURSIVE subtree").
+		WithArgs(1, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	// подзадача 3 уже отменена и не меняется
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "tasks" WHERE (id IN ($1,$2,$3) AND id <> $4 AND status NOT IN ($5,$6)) AND "tasks"."deleted_at" IS NULL ORDER BY id`,
+	)).
+		WithArgs(1, 2, 3, 1, "done", "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "status"}).AddRow(2, "Subtask", 1, "in_progress"))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "tasks" SET "completed_at"=$1,"status"=$2 WHERE id IN ($3) AND "tasks"."deleted_at" IS NULL`,
+	)).
+		WithArgs(completedAt, "done", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO \"task_histories\"").
+		WithArgs(2, 1, "updated", []byte(`{"completed_at":null,"status":"in_progress"}`), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	// сама задача выполняется в той же транзакции
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET`)).
+		WithArgs("Test Task", 1, "done", completedAt, nil, nil, 0, 0.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO \"task_histories\"").
+		WithArgs(1, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	results, err := r.Batch(1, []TaskOp{{Op: entity.BatchComplete, TaskID: 1, CompletedAt: completedAt, Subtasks: true}}, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []TaskOpResult{{}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteOccurrence(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+	completedAt := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	nextDue := time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC)
+
+	lockTaskRow := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE "tasks"."id" = $1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $2 FOR UPDATE`)
+	updateTask := regexp.QuoteMeta(
+		`UPDATE "tasks" SET "description"=$1,"user_id"=$2,"status"=$3,"completed_at"=$4,"due_at"=$5,"remind_at"=$6,"priority"=$7,"position"=$8,"project_id"=$9,"parent_id"=$10,"recurrence"=$11,"created_at"=$12,"version"=$13,"deleted_at"=$14 WHERE "tasks"."deleted_at" IS NULL AND "id" = $15`,
+	)
+	taskRow := func(recurrence string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "description", "user_id", "status", "priority", "position", "recurrence"}).
+			AddRow(10, "Gym", 1, "todo", 2, 1.0, recurrence)
+	}
+
+	tests := []struct {
+		name       string
+		mock       func()
+		next       *entity.Task
+		nextErr    error
+		wantNextID int
+		wantErr    bool
+	}{
+		{
+			name: "Next Created With Tags",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 10, entity.RoleOwner)
+				mock.ExpectQuery(lockTaskRow).WithArgs(10, 1).WillReturnRows(taskRow("FREQ=WEEKLY;COUNT=3"))
+				mock.ExpectExec(updateTask).
+					WithArgs("Gym", 1, "done", completedAt, nil, nil, 2, 1.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(10, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(position), 0) FROM "tasks" WHERE user_id = $1 AND "tasks"."deleted_at" IS NULL`)).
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(5.0))
+				mock.ExpectQuery("INSERT INTO \"tasks\"").
+					WithArgs("Gym", 1, "todo", nil, nextDue, nil, 2, 6.0, nil, nil, "FREQ=WEEKLY;COUNT=2", 1, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO task_tags (task_id, tag_id) SELECT $1, tag_id FROM task_tags WHERE task_id = $2`)).
+					WithArgs(11, 10).WillReturnResult(sqlmock.NewResult(0, 2))
+				// следующее повторение записывается как новая задача
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(11, 1, "created", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			next: &entity.Task{
+				Description: "Gym",
+				Status:      entity.StatusTodo,
+				DueAt:       &nextDue,
+				Priority:    2,
+				Recurrence:  "FREQ=WEEKLY;COUNT=2",
+			},
+			wantNextID: 11,
+		},
+		{
+			name: "Series Is Over",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 10, entity.RoleOwner)
+				mock.ExpectQuery(lockTaskRow).WithArgs(10, 1).WillReturnRows(taskRow("FREQ=WEEKLY;COUNT=1"))
+				mock.ExpectExec(updateTask).
+					WithArgs("Gym", 1, "done", completedAt, nil, nil, 2, 1.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(10, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			// одновременное выполнение уже забрало правило, второго повторения нет
+			name: "Completed Concurrently",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 10, entity.RoleOwner)
+				mock.ExpectQuery(lockTaskRow).WithArgs(10, 1).WillReturnRows(taskRow(""))
+				mock.ExpectExec(updateTask).
+					WithArgs("Gym", 1, "done", completedAt, nil, nil, 2, 1.0, nil, nil, "", sqlmock.AnyArg(), 0, nil, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO \"task_histories\"").
+					WithArgs(10, 1, "updated", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			nextErr: errors.New("next must not be called without a rule"),
+		},
+		{
+			name: "Broken Rule",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 10, entity.RoleOwner)
+				mock.ExpectQuery(lockTaskRow).WithArgs(10, 1).WillReturnRows(taskRow("FREQ=SOMETIMES"))
+				mock.ExpectRollback()
+			},
+			nextErr: errors.New("unsupported frequency SOMETIMES"),
+			wantErr: true,
+		},
+		{
+			name: "Save Error",
+			mock: func() {
+				mock.ExpectBegin()
+				expectTaskRole(mock, 1, 10, entity.RoleOwner)
+				mock.ExpectQuery(lockTaskRow).WithArgs(10, 1).WillReturnRows(taskRow("FREQ=WEEKLY;COUNT=1"))
+				mock.ExpectExec(updateTask).WillReturnError(errors.New("Update Error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			op := TaskOp{Op: entity.BatchComplete, TaskID: 10, CompletedAt: completedAt, Next: func(task entity.Task) (*entity.Task, error) {
+				return tt.next, tt.nextErr
+			}}
+			results, err := r.Batch(1, []TaskOp{op}, true)
+
+			assert.NoError(t, err)
+			if tt.wantErr {
+				assert.Error(t, results[0].Err)
+			} else {
+				assert.NoError(t, results[0].Err)
+				assert.Equal(t, tt.wantNextID, results[0].ID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAllTask_Page(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTaskRepo(gormDB)
+	dueAt := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter entity.TaskFilter
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name: "Priority After Cursor",
+			filter: entity.TaskFilter{
+				Order: entity.OrderPriority,
+				Limit: 3,
+				After: &entity.TaskCursor{Order: entity.OrderPriority, ID: 7, Priority: 2, Position: 1.5},
+			},
+			query: `SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND (priority, position, id) > ($2, $3, $4) AND "tasks"."deleted_at" IS NULL ORDER BY priority, position, id LIMIT $5`,
+			args:  []driver.Value{1, 2, 1.5, 7, 3},
+		},
+		{
+			name: "Newest First",
+			filter: entity.TaskFilter{
+				Order: entity.OrderCreatedAt,
+				Desc:  true,
+				Limit: 3,
+			},
+			query: `SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND "tasks"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $2`,
+			args:  []driver.Value{1, 3},
+		},
+		{
+			name: "Due Date After Cursor",
+			filter: entity.TaskFilter{
+				Order: entity.OrderDueAt,
+				Limit: 3,
+				After: &entity.TaskCursor{Order: entity.OrderDueAt, ID: 7, Time: &dueAt},
+			},
+			query: `SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND ((due_at IS NOT NULL AND (due_at, id) > ($2, $3)) OR due_at IS NULL) AND "tasks"."deleted_at" IS NULL ORDER BY due_at IS NULL, due_at, id LIMIT $4`,
+			args:  []driver.Value{1, dueAt, 7, 3},
+		},
+		{
+			name: "Without Due Date After Cursor",
+			filter: entity.TaskFilter{
+				Order: entity.OrderDueAt,
+				Limit: 3,
+				After: &entity.TaskCursor{Order: entity.OrderDueAt, ID: 7},
+			},
+			query: `SELECT tasks.*, (SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count FROM "tasks" WHERE id IN (SELECT task_id FROM task_access_for($1)) AND (due_at IS NULL AND id > $2) AND "tasks"."deleted_at" IS NULL ORDER BY due_at IS NULL, due_at, id LIMIT $3`,
+			args:  []driver.Value{1, 7, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id"}))
+			mock.ExpectCommit()
+
+			_, err := r.GetAllTask(1, tt.filter)
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrateVersion(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	// версия поднимается не только правкой строки, но и всем, с чем задача отдаётся
+	for _, fragment := range []string{
+		"FUNCTION bump_task_version",
+		"TRIGGER tasks_version BEFORE UPDATE ON tasks",
+		"FUNCTION touch_tasks",
+		"FUNCTION touch_changed_tasks",
+		"TRIGGER task_tags_version_insert AFTER INSERT ON task_tags",
+		"TRIGGER task_tags_version_delete AFTER DELETE ON task_tags",
+		"TRIGGER comments_version_insert AFTER INSERT ON comments",
+		"TRIGGER comments_version_delete AFTER DELETE ON comments",
+		"FUNCTION touch_renamed_tags",
+		"TRIGGER tags_version_update AFTER UPDATE ON tags",
+		"FUNCTION touch_parent_task",
+		"TRIGGER tasks_parent_version_insert AFTER INSERT ON tasks",
+		"TRIGGER tasks_parent_version_delete AFTER DELETE ON tasks",
+		"TRIGGER tasks_parent_version_update AFTER UPDATE ON tasks",
+	} {
+		mock.ExpectExec(regexp.QuoteMeta(fragment)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	assert.NoError(t, MigrateVersion(gormDB))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"gorm.io/gorm"
+)
+
+// trashSQL keeps trashed tasks the user may delete, i.e. has the owner role
+// for, the same check as DeleteTask.
+const trashSQL = "deleted_at IS NOT NULL AND id IN (SELECT task_id FROM task_access_for(?) WHERE level >= 3)"
+
+type TrashRepo struct {
+	db *gorm.DB
+}
+
+func NewTrashRepo(db *gorm.DB) *TrashRepo {
+	return &TrashRepo{db: db}
+}
+
+// GetTrash returns deleted tasks the user owns or has the owner role for,
+// recently deleted first.
+func (r *TrashRepo) GetTrash(userID int) ([]entity.Task, error) {
+	var tasks []entity.Task
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Unscoped().Preload("Tags").Where(trashSQL, userID).
+		Order("deleted_at DESC, id").Find(&tasks).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tasks, tx.Commit().Error
+}
+
+// RestoreTask brings the task back together with the subtasks deleted along with it.
+// If the parent task is still in the trash the task becomes top level,
+// if its owner no longer sees its project the task goes to the inbox.
+func (r *TrashRepo) RestoreTask(userID, taskID int) error {
+	var task entity.Task
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where(trashSQL, userID).First(&task, taskID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	ids, err := trashedSubtreeIDs(tx, task)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if task.ParentID != nil {
+		var parents int64
+		if err := tx.Model(&entity.Task{}).Where("id = ?", *task.ParentID).
+			Count(&parents).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if parents == 0 {
+			if err := tx.Unscoped().Model(&task).Update("parent_id", nil).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if err := tx.Unscoped().Model(&entity.Task{}).
+		Where("id IN ? AND project_id IS NOT NULL AND project_id NOT IN (SELECT project_id FROM project_access WHERE user_id = ?)", ids, task.UserID).
+		Update("project_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := dropStaleAssignees(tx, "t.id IN ?", ids); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Unscoped().Model(&entity.Task{}).Where("id IN ?", ids).Update("deleted_at", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var restored []entity.Task
+	if err := tx.Where("id IN ?", ids).Order("id").Find(&restored).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	changes := make([]taskChange, len(restored))
+	for i := range restored {
+		changes[i] = taskChange{after: &restored[i]}
+	}
+
+	if err := recordHistory(tx, userID, entity.ActionRestored, changes...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgeTask deletes the trashed task and the subtasks deleted along with it for good.
+func (r *TrashRepo) PurgeTask(userID, taskID int) error {
+	var task entity.Task
+
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where(trashSQL, userID).First(&task, taskID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	ids, err := trashedSubtreeIDs(tx, task)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := purgeWhere(tx, "id IN ?", ids); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// EmptyTrash deletes all tasks of GetTrash for good.
+func (r *TrashRepo) EmptyTrash(userID int) error {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if _, err := purgeWhere(tx, trashSQL, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgeExpired deletes tasks of all users that were trashed before the given time.
+// Returns how many tasks were deleted.
+func (r *TrashRepo) PurgeExpired(before time.Time) (int64, error) {
+	tx := r.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	purged, err := purgeWhere(tx, "deleted_at < ?", before)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return purged, tx.Commit().Error
+}
+
+// purgeWhere deletes matching tasks and everything attached to them for good,
+// returns how many tasks were deleted. Blobs of attachments are queued for
+// deletion from the storage.
+func purgeWhere(tx *gorm.DB, query string, args ...any) (int64, error) {
+	if err := queuePurgedBlobs(tx, query, args...); err != nil {
+		return 0, err
+	}
+
+	for _, link := range []string{"task_tags", "task_assignees", "task_shares", "comments", "attachments"} {
+		if err := tx.Exec("DELETE FROM "+link+" WHERE task_id IN (SELECT id FROM tasks WHERE "+query+")", args...).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	res := tx.Unscoped().Where(query, args...).Delete(&entity.Task{})
+	return res.RowsAffected, res.Error
+}
+
+// trashedSubtreeIDs returns ids of the trashed task and of the subtasks that
+// went to the trash in the same DeleteTask call, i.e. with the same deleted_at.
+func trashedSubtreeIDs(tx *gorm.DB, task entity.Task) ([]int, error) {
+	var ids []int
+
+	// подзадачи принадлежат владельцу родителя
+	err := tx.Raw(`WITH RECURSIVE subtree AS (
+		SELECT id FROM tasks WHERE user_id = ? AND id = ?
+		UNION
+		SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.user_id = ? AND t.deleted_at = ?
+	) SELECT id FROM subtree`, task.UserID, task.ID, task.UserID, task.DeletedAt.Time).Scan(&ids).Error
+
+	return ids, err
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrash(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTrashRepo(gormDB)
+
+	deletedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "tasks" WHERE deleted_at IS NOT NULL AND id IN (SELECT task_id FROM task_access_for($1) WHERE level >= 3) ORDER BY deleted_at DESC, id`,
+	)).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "user_id", "deleted_at"}).
+			AddRow(1, "Old Task", 1, deletedAt))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "task_tags" WHERE "task_tags"."task_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+	mock.ExpectCommit()
+
+	tasks, err := r.GetTrash(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.True(t, tasks[0].DeletedAt.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTrashRepo(gormDB)
+
+	deletedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	selectTrashed := regexp.QuoteMeta(
+		`SELECT * FROM "tasks" WHERE (deleted_at IS NOT NULL AND id IN (SELECT task_id FROM task_access_for($1) WHERE level >= 3)) AND "tasks"."id" = $2 ORDER BY "tasks"."id" LIMIT $3`,
+	)
+	detachProject := regexp.QuoteMeta(
+		`UPDATE "tasks" SET "project_id"=$1 WHERE id IN ($2,$3) AND project_id IS NOT NULL AND project_id NOT IN (SELECT project_id FROM project_access WHERE user_id = $4)`,
+	)
+	dropAssignees := `DELETE FROM task_assignees a USING tasks t\s+WHERE a.task_id = t.id AND \(t.id IN \(\$1,\$2\)\)`
+	restore := regexp.QuoteMeta(`UPDATE "tasks" SET "deleted_at"=$1 WHERE id IN ($2,$3)`)
+	// восстановленное поддерево записывается в историю целиком
+	expectRestored := func(userID int) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE id IN ($1,$2) AND "tasks"."deleted_at" IS NULL ORDER BY id`)).WithArgs(5, 6).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, userID).AddRow(6, userID))
+		mock.ExpectQuery(`INSERT INTO "task_histories"`).
+			WithArgs(5, 1, "restored", sqlmock.AnyArg(), 6, 1, "restored", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Subtree Restored",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTrashed).WithArgs(1, 5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(5, 1, deletedAt))
+				mock.ExpectQuery("WITH RECURSIVE subtree").WithArgs(1, 5, 1, deletedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+				mock.ExpectExec(detachProject).WithArgs(nil, 5, 6, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(dropAssignees).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(restore).WithArgs(nil, 5, 6).WillReturnResult(sqlmock.NewResult(0, 2))
+				expectRestored(1)
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Parent Still In Trash",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTrashed).WithArgs(1, 5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "parent_id", "deleted_at"}).AddRow(5, 1, 2, deletedAt))
+				mock.ExpectQuery("WITH RECURSIVE subtree").WithArgs(1, 5, 1, deletedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+				mock.ExpectQuery(regexp.QuoteMeta(
+					`SELECT count(*) FROM "tasks" WHERE id = $1 AND "tasks"."deleted_at" IS NULL`,
+				)).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "parent_id"=$1 WHERE "id" = $2`)).
+					WithArgs(nil, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(detachProject).WithArgs(nil, 5, 6, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(dropAssignees).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(restore).WithArgs(nil, 5, 6).WillReturnResult(sqlmock.NewResult(0, 2))
+				expectRestored(1)
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			// задачу владельца 2 удалил и восстанавливает получатель с ролью owner
+			name: "Shared With Owner Role",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTrashed).WithArgs(1, 5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(5, 2, deletedAt))
+				mock.ExpectQuery("WITH RECURSIVE subtree").WithArgs(2, 5, 2, deletedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+				mock.ExpectExec(detachProject).WithArgs(nil, 5, 6, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(dropAssignees).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(restore).WithArgs(nil, 5, 6).WillReturnResult(sqlmock.NewResult(0, 2))
+				expectRestored(2)
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Not In Trash",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectTrashed).WithArgs(1, 5, 1).WillReturnError(errors.New("record not found"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RestoreTask(1, 5)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeTask(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTrashRepo(gormDB)
+
+	deletedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "tasks" WHERE (deleted_at IS NOT NULL AND id IN (SELECT task_id FROM task_access_for($1) WHERE level >= 3)) AND "tasks"."id" = $2 ORDER BY "tasks"."id" LIMIT $3`,
+	)).WithArgs(1, 5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(5, 1, deletedAt))
+	mock.ExpectQuery("WITH RECURSIVE subtree").WithArgs(1, 5, 1, deletedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+	mock.ExpectExec(`INSERT INTO purged_blobs \(storage_key\)\s+SELECT storage_key FROM attachments WHERE task_id IN \(SELECT id FROM tasks WHERE id IN \(\$1,\$2\)\)\s+ON CONFLICT DO NOTHING`).
+		WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM task_tags WHERE task_id IN (SELECT id FROM tasks WHERE id IN ($1,$2))`,
+	)).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM task_assignees WHERE task_id IN (SELECT id FROM tasks WHERE id IN ($1,$2))`,
+	)).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM task_shares WHERE task_id IN (SELECT id FROM tasks WHERE id IN ($1,$2))`,
+	)).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM comments WHERE task_id IN (SELECT id FROM tasks WHERE id IN ($1,$2))`,
+	)).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM attachments WHERE task_id IN (SELECT id FROM tasks WHERE id IN ($1,$2))`,
+	)).WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tasks" WHERE id IN ($1,$2)`)).
+		WithArgs(5, 6).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.PurgeTask(1, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeExpired(t *testing.T) {
+	sqlDB, gormDB, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	r := NewTrashRepo(gormDB)
+
+	before := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mock       func()
+		wantPurged int64
+		wantErr    bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO purged_blobs .* WHERE deleted_at < \$1\)`).
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_tags WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_assignees WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_shares WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM comments WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM attachments WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tasks" WHERE deleted_at < $1`)).
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			wantPurged: 3,
+			wantErr:    false,
+		},
+		{
+			name: "Delete Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO purged_blobs .* WHERE deleted_at < \$1\)`).
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_tags WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_assignees WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM task_shares WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM comments WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM attachments WHERE task_id IN (SELECT id FROM tasks WHERE deleted_at < $1)`,
+				)).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tasks" WHERE deleted_at < $1`)).
+					WithArgs(before).WillReturnError(errors.New("Delete Error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			purged, err := r.PurgeExpired(before)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantPurged, purged)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
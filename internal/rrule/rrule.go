@@ -0,0 +1,469 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// recurring tasks: DAILY, WEEKLY, MONTHLY and YEARLY frequencies with
+// INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the search so that a rule which never matches
+// (e.g. FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31 started in April) can not hang the caller.
+const maxPeriods = 100000
+
+// WeekdayNum is a BYDAY entry: MO, 2TU, -1FR. N is the number of the weekday
+// inside the month, 0 means every such weekday.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// Parse parses a rule like "FREQ=WEEKLY;BYDAY=MO,WE", an optional "RRULE:" prefix is allowed.
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("empty rule")
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || key == "" || value == "" {
+			return Rule{}, fmt.Errorf("malformed rule part %q", part)
+		}
+		if seen[key] {
+			return Rule{}, fmt.Errorf("duplicate rule part %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported frequency %s", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(value)
+		case "COUNT":
+			r.Count, err = positive(value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			// недели всегда начинаются с понедельника
+			if value != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("unsupported rule part %s", key)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return Rule{}, err
+	}
+
+	return r, nil
+}
+
+func (r Rule) validate() error {
+	if r.Freq == "" {
+		return errors.New("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return errors.New("COUNT and UNTIL can not be used together")
+	}
+
+	hasOrdinal := false
+	for _, wd := range r.ByDay {
+		if wd.N != 0 {
+			hasOrdinal = true
+		}
+	}
+
+	switch r.Freq {
+	case Daily, Weekly:
+		if hasOrdinal {
+			return fmt.Errorf("numbered BYDAY is not allowed with FREQ=%s", r.Freq)
+		}
+		if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+			return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+		}
+	case Monthly:
+		if len(r.ByDay) > 0 && len(r.ByMonthDay) > 0 {
+			return errors.New("BYDAY and BYMONTHDAY together are not supported")
+		}
+	case Yearly:
+		if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+			return errors.New("BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY")
+		}
+	}
+
+	return nil
+}
+
+// String returns the canonical form of the rule, Parse(r.String()) gives r back.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			day := weekdayNames[wd.Day]
+			if wd.N != 0 {
+				day = strconv.Itoa(wd.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after the given moment.
+// Occurrences keep the wall clock time of dtstart and are counted from it.
+func (r Rule) Next(dtstart, after time.Time) (time.Time, bool) {
+	next := r.Occurrences(dtstart, after, 1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+
+	return next[0], true
+}
+
+// Advance returns the first occurrence strictly after the given moment together
+// with the rule that continues the series from that occurrence: COUNT is
+// reduced by the occurrences that were passed, so the series keeps its length
+// when every next occurrence becomes the new dtstart.
+func (r Rule) Advance(dtstart, after time.Time) (time.Time, Rule, bool) {
+	var next time.Time
+	passed := 0
+
+	r.each(dtstart, func(t time.Time) bool {
+		if t.After(after) {
+			next = t
+			return false
+		}
+		passed++
+		return true
+	})
+
+	if next.IsZero() {
+		return time.Time{}, Rule{}, false
+	}
+
+	rest := r
+	if rest.Count > 0 {
+		rest.Count -= passed
+	}
+
+	return next, rest, true
+}
+
+// Occurrences returns up to n occurrences strictly after the given moment.
+func (r Rule) Occurrences(dtstart, after time.Time, n int) []time.Time {
+	var res []time.Time
+	if n <= 0 {
+		return res
+	}
+
+	r.each(dtstart, func(t time.Time) bool {
+		if t.After(after) {
+			res = append(res, t)
+		}
+		return len(res) < n
+	})
+
+	return res
+}
+
+// each calls fn for every occurrence in order until fn returns false or
+// the rule runs out by COUNT or UNTIL.
+func (r Rule) each(dtstart time.Time, fn func(time.Time) bool) {
+	count := 0
+	for k := 0; k < maxPeriods; k++ {
+		for _, t := range r.period(dtstart, k) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+
+			count++
+			if !fn(t) || (r.Count > 0 && count >= r.Count) {
+				return
+			}
+		}
+	}
+}
+
+// period returns sorted candidates of the k-th period (day, week, month or year) of the rule.
+func (r Rule) period(dtstart time.Time, k int) []time.Time {
+	step := k * r.Interval
+	hour, min, sec := dtstart.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, min, sec, 0, dtstart.Location())
+	}
+
+	var res []time.Time
+	switch r.Freq {
+	case Daily:
+		day := dtstart.AddDate(0, 0, step)
+		if r.matchesDay(day) {
+			res = append(res, day)
+		}
+	case Weekly:
+		monday := dtstart.AddDate(0, 0, -daysFromMonday(dtstart.Weekday())+7*step)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Day: dtstart.Weekday()}}
+		}
+		for _, wd := range days {
+			d := monday.AddDate(0, 0, daysFromMonday(wd.Day))
+			res = append(res, at(d.Year(), d.Month(), d.Day()))
+		}
+	case Monthly:
+		first := at(dtstart.Year(), dtstart.Month(), 1).AddDate(0, step, 0)
+		y, m := first.Year(), first.Month()
+		daysIn := daysInMonth(y, m)
+
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, d := range r.ByMonthDay {
+				if d < 0 {
+					d = daysIn + d + 1
+				}
+				if d >= 1 && d <= daysIn {
+					res = append(res, at(y, m, d))
+				}
+			}
+		case len(r.ByDay) > 0:
+			for _, wd := range r.ByDay {
+				for _, d := range monthWeekdays(y, m, wd) {
+					res = append(res, at(y, m, d))
+				}
+			}
+		default:
+			// как и в RFC 5545, месяцы без такого числа пропускаются
+			if dtstart.Day() <= daysIn {
+				res = append(res, at(y, m, dtstart.Day()))
+			}
+		}
+	case Yearly:
+		t := at(dtstart.Year()+step, dtstart.Month(), dtstart.Day())
+		if t.Day() == dtstart.Day() { // 29 февраля только в високосные годы
+			res = append(res, t)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+	return dedupe(res)
+}
+
+func (r Rule) matchesDay(t time.Time) bool {
+	if len(r.ByDay) > 0 {
+		found := false
+		for _, wd := range r.ByDay {
+			if wd.Day == t.Weekday() {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		daysIn := daysInMonth(t.Year(), t.Month())
+		for _, d := range r.ByMonthDay {
+			if d == t.Day() || daysIn+d+1 == t.Day() {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// monthWeekdays returns days of the month matching a BYDAY entry.
+func monthWeekdays(y int, m time.Month, wd WeekdayNum) []int {
+	daysIn := daysInMonth(y, m)
+	firstWeekday := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	first := 1 + (int(wd.Day)-int(firstWeekday)+7)%7
+
+	var days []int
+	for d := first; d <= daysIn; d += 7 {
+		days = append(days, d)
+	}
+
+	switch {
+	case wd.N == 0:
+		return days
+	case wd.N > 0 && wd.N <= len(days):
+		return []int{days[wd.N-1]}
+	case wd.N < 0 && -wd.N <= len(days):
+		return []int{days[len(days)+wd.N]}
+	}
+
+	return nil
+}
+
+func daysInMonth(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysFromMonday(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+func dedupe(ts []time.Time) []time.Time {
+	if len(ts) < 2 {
+		return ts
+	}
+
+	res := ts[:1]
+	for _, t := range ts[1:] {
+		if !t.Equal(res[len(res)-1]) {
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+func positive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// UNTIL в виде даты включает весь этот день
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var res []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+
+		wd := WeekdayNum{Day: day}
+		if num := item[:len(item)-2]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY %q", item)
+			}
+			wd.N = n
+		}
+		res = append(res, wd)
+	}
+
+	return res, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var res []int
+	for _, item := range strings.Split(value, ",") {
+		d, err := strconv.Atoi(item)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %q", item)
+		}
+		res = append(res, d)
+	}
+
+	return res, nil
+}
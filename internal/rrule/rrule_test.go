@@ -0,0 +1,163 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		expectedError bool
+	}{
+		{name: "Daily", input: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "Prefix And Lower Case", input: "RRULE:freq=weekly;byday=mo,we;interval=2", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{name: "Monthly Last Friday", input: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", want: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{name: "Until Date", input: "FREQ=DAILY;UNTIL=20250510", want: "FREQ=DAILY;UNTIL=20250510T235959Z"},
+		{name: "No Freq", input: "INTERVAL=2", expectedError: true},
+		{name: "Unknown Freq", input: "FREQ=HOURLY", expectedError: true},
+		{name: "Count With Until", input: "FREQ=DAILY;COUNT=2;UNTIL=20250510", expectedError: true},
+		{name: "Numbered Weekday In Weekly", input: "FREQ=WEEKLY;BYDAY=2MO", expectedError: true},
+		{name: "Zero Interval", input: "FREQ=DAILY;INTERVAL=0", expectedError: true},
+		{name: "Bad Month Day", input: "FREQ=MONTHLY;BYMONTHDAY=32", expectedError: true},
+		{name: "Duplicate Part", input: "FREQ=DAILY;FREQ=WEEKLY", expectedError: true},
+		{name: "Unsupported Part", input: "FREQ=DAILY;BYHOUR=9", expectedError: true},
+		{name: "Empty", input: " ", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.input)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, r.String())
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	// 1 мая 2025 - четверг
+	dtstart := time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 9, 30, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time // dtstart, если не задан
+		after time.Time
+		n     int
+		want  []time.Time
+	}{
+		{
+			name:  "Every Other Day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			after: dtstart,
+			n:     3,
+			want:  []time.Time{day(5, 3), day(5, 5), day(5, 7)},
+		},
+		{
+			name:  "Weekdays Only",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			after: dtstart,
+			n:     3,
+			want:  []time.Time{day(5, 2), day(5, 5), day(5, 6)},
+		},
+		{
+			name:  "Weekly On Monday And Thursday",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TH",
+			after: dtstart.Add(-time.Minute),
+			n:     4,
+			want:  []time.Time{day(5, 1), day(5, 5), day(5, 8), day(5, 12)},
+		},
+		{
+			name:  "Monthly On 31st Skips Short Months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			after: dtstart,
+			n:     3,
+			want:  []time.Time{day(5, 31), day(7, 31), day(8, 31)},
+		},
+		{
+			name:  "Monthly On Last Day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after: dtstart,
+			n:     2,
+			want:  []time.Time{day(5, 31), day(6, 30)},
+		},
+		{
+			name:  "Monthly On Second Tuesday",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			after: dtstart,
+			n:     2,
+			want:  []time.Time{day(5, 13), day(6, 10)},
+		},
+		{
+			name:  "Yearly Skips Missing Leap Day",
+			rule:  "FREQ=YEARLY",
+			start: time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC),
+			after: time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC),
+			n:     1,
+			want:  []time.Time{time.Date(2028, 2, 29, 9, 30, 0, 0, time.UTC)},
+		},
+		{
+			name:  "Count Includes Past Occurrences",
+			rule:  "FREQ=DAILY;COUNT=3",
+			after: day(5, 2),
+			n:     5,
+			want:  []time.Time{day(5, 3)},
+		},
+		{
+			name:  "Until",
+			rule:  "FREQ=WEEKLY;UNTIL=20250515",
+			after: dtstart,
+			n:     5,
+			want:  []time.Time{day(5, 8), day(5, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			assert.NoError(t, err)
+
+			start := tt.start
+			if start.IsZero() {
+				start = dtstart
+			}
+
+			assert.Equal(t, tt.want, r.Occurrences(start, tt.after, tt.n))
+		})
+	}
+}
+
+func TestNext_Exhausted(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;COUNT=1")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC)
+	_, ok := r.Next(dtstart, dtstart)
+
+	assert.False(t, ok)
+}
+
+func TestAdvance(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;COUNT=5")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC)
+	next, rest, ok := r.Advance(dtstart, dtstart.AddDate(0, 0, 2))
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 5, 4, 9, 30, 0, 0, time.UTC), next)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", rest.String())
+	// продолженная серия заканчивается там же, где и исходная
+	assert.Equal(t, r.Occurrences(dtstart, next, 10), rest.Occurrences(next, next, 10))
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+// AssignmentService is not cached: assignees are not part of the task in
+// the "user:%d:tasks" hash and the assigned list is read across projects.
+type AssignmentService struct {
+	repo repository.Assignments
+}
+
+func NewAssignmentService(repo repository.Assignments) *AssignmentService {
+	return &AssignmentService{repo: repo}
+}
+
+func (s *AssignmentService) GetAssignees(userID, taskID int) ([]entity.TaskAssignee, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get task assignees")
+	}
+
+	return s.repo.GetAssignees(userID, taskID)
+}
+
+// SetAssignees replaces assignees of a task. Every assignee must be a member
+// of the task's project, the repository checks it in the same transaction
+// as the write.
+func (s *AssignmentService) SetAssignees(userID, taskID int, userIDs []int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to assign task")
+	}
+
+	if len(userIDs) == 0 {
+		userIDs = nil
+	}
+
+	return s.repo.SetAssignees(userID, taskID, userIDs)
+}
+
+func (s *AssignmentService) GetAssignedTasks(userID int) ([]entity.Task, error) {
+	return s.repo.GetAssignedTasks(userID)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_repository "github.com/AronditFire/todo-app/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAssignees(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskID  int
+		userIDs []int
+		mock    func(repo *mock_repository.MockAssignments)
+		wantErr bool
+	}{
+		{
+			name:    "Members",
+			taskID:  5,
+			userIDs: []int{1, 2},
+			mock: func(repo *mock_repository.MockAssignments) {
+				repo.EXPECT().SetAssignees(1, 5, []int{1, 2}).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Not A Member",
+			taskID:  5,
+			userIDs: []int{1, 4},
+			mock: func(repo *mock_repository.MockAssignments) {
+				repo.EXPECT().SetAssignees(1, 5, []int{1, 4}).
+					Return(entity.FieldErrors{"user_ids": "user 4 is not a member of the project"})
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Unassign Everybody",
+			taskID:  5,
+			userIDs: []int{},
+			mock: func(repo *mock_repository.MockAssignments) {
+				repo.EXPECT().SetAssignees(1, 5, nil).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid Id",
+			taskID:  0,
+			userIDs: []int{1},
+			mock:    func(repo *mock_repository.MockAssignments) {},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockAssignments(ctrl)
+			tt.mock(repo)
+
+			err := NewAssignmentService(repo).SetAssignees(1, tt.taskID, tt.userIDs)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
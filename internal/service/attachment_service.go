@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/AronditFire/todo-app/internal/storage"
+)
+
+const (
+	// MaxAttachmentSize limits one uploaded file.
+	MaxAttachmentSize = 10 << 20
+	// blobCleanupBatch is how many queued blobs CleanupBlobs deletes per query.
+	blobCleanupBatch = 100
+)
+
+// allowedAttachmentTypes are media types detected by http.DetectContentType
+// that may be uploaded. Office documents are detected as zip.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+// AttachmentService is not cached: attachments are not part of the task in
+// the "user:%d:tasks" hash.
+type AttachmentService struct {
+	repo  repository.Attachments
+	store storage.Storage
+}
+
+func NewAttachmentService(repo repository.Attachments, store storage.Storage) *AttachmentService {
+	return &AttachmentService{repo: repo, store: store}
+}
+
+// UploadAttachment puts the file into the storage and then records it, so a
+// row never points to a missing blob.
+func (s *AttachmentService) UploadAttachment(userID, taskID int, file *multipart.FileHeader) (int, error) {
+	if taskID <= 0 {
+		return 0, errors.New("Invalid id while trying to attach file")
+	}
+	if file.Size > MaxAttachmentSize {
+		return 0, entity.ErrFileTooLarge
+	}
+	if file.Size == 0 {
+		return 0, errors.New("File is empty")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	// тип определяем по содержимому, заголовку клиента не верим
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !allowedAttachmentTypes[mediaType] {
+		return 0, entity.ErrFileType
+	}
+
+	key, err := newBlobKey(taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	body := io.MultiReader(bytes.NewReader(head), f)
+	if err := s.store.Put(ctx, key, body, file.Size, contentType); err != nil {
+		return 0, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	id, err := s.repo.CreateAttachment(userID, taskID, entity.Attachment{
+		Name:        attachmentName(file.Filename),
+		ContentType: contentType,
+		Size:        file.Size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("Could not delete blob %s of a failed upload: %v", key, err)
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *AttachmentService) GetAttachments(userID, taskID int) ([]entity.Attachment, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get attachments")
+	}
+
+	return s.repo.GetAttachments(userID, taskID)
+}
+
+// OpenAttachment returns the attachment and its content, the caller closes it.
+func (s *AttachmentService) OpenAttachment(userID, taskID, attachmentID int) (entity.Attachment, io.ReadCloser, error) {
+	if taskID <= 0 || attachmentID <= 0 {
+		return entity.Attachment{}, nil, errors.New("Invalid id while trying to get attachment")
+	}
+
+	attachment, err := s.repo.GetAttachment(userID, taskID, attachmentID)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	}
+
+	body, err := s.store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		return entity.Attachment{}, nil, fmt.Errorf("failed to read file from storage: %w", err)
+	}
+
+	return attachment, body, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(userID, taskID, attachmentID int) error {
+	if taskID <= 0 || attachmentID <= 0 {
+		return errors.New("Invalid id while trying to delete attachment")
+	}
+
+	if err := s.repo.DeleteAttachment(userID, taskID, attachmentID); err != nil {
+		return err
+	}
+
+	cleanupBlobs(s)
+	return nil
+}
+
+// CleanupBlobs deletes queued blobs from the storage, returns how many were deleted.
+// A blob that could not be deleted stays in the queue for the next run.
+func (s *AttachmentService) CleanupBlobs() (int, error) {
+	deleted := 0
+
+	for {
+		blobs, err := s.repo.GetPurgedBlobs(blobCleanupBatch)
+		if err != nil {
+			return deleted, err
+		}
+		if len(blobs) == 0 {
+			return deleted, nil
+		}
+
+		keys := make([]string, 0, len(blobs))
+		var failed error
+		for _, b := range blobs {
+			if err := s.store.Delete(context.Background(), b.StorageKey); err != nil {
+				failed = fmt.Errorf("failed to delete blob %s: %w", b.StorageKey, err)
+				continue
+			}
+			keys = append(keys, b.StorageKey)
+		}
+
+		if err := s.repo.ForgetPurgedBlobs(keys); err != nil {
+			return deleted, err
+		}
+		deleted += len(keys)
+
+		// иначе на каждой итерации будем получать те же самые blob'ы
+		if failed != nil {
+			return deleted, failed
+		}
+	}
+}
+
+// cleanupBlobs runs CleanupBlobs right after a deletion. Errors are only
+// logged, the blobs stay queued and the trash purge job retries them.
+func cleanupBlobs(blobs BlobCleaner) {
+	if _, err := blobs.CleanupBlobs(); err != nil {
+		log.Printf("Could not delete purged blobs: %v", err)
+	}
+}
+
+func newBlobKey(taskID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(random)), nil
+}
+
+// attachmentName keeps only the base name of what the client sent.
+func attachmentName(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	return name
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_repository "github.com/AronditFire/todo-app/internal/repository/mocks"
+	"github.com/AronditFire/todo-app/internal/storage"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileHeader builds the header gin binds from a multipart upload.
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	part.Write(content)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+
+	return req.MultipartForm.File["file"][0]
+}
+
+// blobCount counts files in the local storage directory.
+func blobCount(t *testing.T, dir string) int {
+	count := 0
+	filepath.WalkDir(dir, func(_ string, d os.DirEntry, _ error) error {
+		if d != nil && !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func TestUploadAttachment(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n%fake pdf body")
+
+	tests := []struct {
+		name      string
+		file      []byte
+		filename  string
+		mock      func(repo *mock_repository.MockAttachments)
+		wantErr   error
+		wantBlobs int
+	}{
+		{
+			name:     "Success",
+			file:     pdf,
+			filename: `C:\docs\report.pdf`,
+			mock: func(repo *mock_repository.MockAttachments) {
+				repo.EXPECT().CreateAttachment(1, 5, gomock.Any()).
+					DoAndReturn(func(_, _ int, a entity.Attachment) (int, error) {
+						assert.Equal(t, "report.pdf", a.Name)
+						assert.Equal(t, "application/pdf", a.ContentType)
+						assert.Equal(t, int64(len(pdf)), a.Size)
+						return 7, nil
+					})
+			},
+			wantBlobs: 1,
+		},
+		{
+			name:     "Type Not Allowed",
+			file:     []byte("<html><script>alert(1)</script></html>"),
+			filename: "page.txt",
+			mock:     func(*mock_repository.MockAttachments) {},
+			wantErr:  entity.ErrFileType,
+		},
+		{
+			name:     "Too Large",
+			file:     bytes.Repeat([]byte("a"), MaxAttachmentSize+1),
+			filename: "big.txt",
+			mock:     func(*mock_repository.MockAttachments) {},
+			wantErr:  entity.ErrFileTooLarge,
+		},
+		{
+			name:     "Blob Removed When Not Recorded",
+			file:     pdf,
+			filename: "report.pdf",
+			mock: func(repo *mock_repository.MockAttachments) {
+				repo.EXPECT().CreateAttachment(1, 5, gomock.Any()).Return(0, entity.ErrForbidden)
+			},
+			wantErr: entity.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dir := t.TempDir()
+			store, err := storage.NewLocalStorage(dir)
+			require.NoError(t, err)
+
+			repo := mock_repository.NewMockAttachments(ctrl)
+			tt.mock(repo)
+
+			_, err = NewAttachmentService(repo, store).UploadAttachment(1, 5, fileHeader(t, tt.filename, tt.file))
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantBlobs, blobCount(t, dir))
+		})
+	}
+}
+
+func TestCleanupBlobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks", "5"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "5", "abc"), []byte("x"), 0o640))
+
+	repo := mock_repository.NewMockAttachments(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetPurgedBlobs(blobCleanupBatch).
+			Return([]entity.PurgedBlob{{StorageKey: "tasks/5/abc"}, {StorageKey: "tasks/5/gone"}}, nil),
+		repo.EXPECT().ForgetPurgedBlobs([]string{"tasks/5/abc", "tasks/5/gone"}).Return(nil),
+		repo.EXPECT().GetPurgedBlobs(blobCleanupBatch).Return(nil, nil),
+	)
+
+	deleted, err := NewAttachmentService(repo, store).CleanupBlobs()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, 0, blobCount(t, dir))
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+// maxBatchSize limits the number of operations in one batch.
+const maxBatchSize = 500
+
+// Batch validates the operations and runs them in one transaction. An invalid
+// operation fails before anything is written: an atomic batch is not run at
+// all then, a best effort one runs the rest. Results follow the order of
+// the request.
+func (s *TaskService) Batch(userID int, req entity.BatchRequest) ([]entity.BatchResult, error) {
+	results, _, err := s.batch(userID, req)
+	return results, err
+}
+
+// batch is Batch that also returns the error of every failed operation,
+// errs[i] belongs to results[i].
+func (s *TaskService) batch(userID int, req entity.BatchRequest) ([]entity.BatchResult, []error, error) {
+	if len(req.Operations) == 0 || len(req.Operations) > maxBatchSize {
+		return nil, nil, errors.New("Batch must contain from 1 to 500 operations")
+	}
+	if !req.Mode.IsValid() {
+		return nil, nil, errors.New("Invalid batch mode")
+	}
+	atomic := req.Mode != entity.BatchBestEffort
+
+	now := s.now()
+	results := make([]entity.BatchResult, len(req.Operations))
+	errs := make([]error, len(req.Operations))
+	ops := make([]repository.TaskOp, 0, len(req.Operations))
+	index := make([]int, 0, len(req.Operations)) // ops[i] - это операция results[index[i]]
+	for i, item := range req.Operations {
+		results[i] = entity.BatchResult{Index: i, Op: item.Op, Status: entity.BatchSkipped}
+
+		op, err := s.batchOp(item, now)
+		if err != nil {
+			failResult(&results[i], err)
+			errs[i] = err
+			continue
+		}
+		ops = append(ops, op)
+		index = append(index, i)
+	}
+
+	if len(ops) == 0 || atomic && len(ops) < len(req.Operations) {
+		return results, errs, nil
+	}
+
+	done, err := s.crepo.Batch(userID, ops, atomic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// атомарный пакет откатывается целиком, если упала последняя выполненная операция
+	rolledBack := atomic && len(done) > 0 && done[len(done)-1].Err != nil
+	for i, res := range done {
+		result := &results[index[i]]
+		switch {
+		case res.Err != nil:
+			failResult(result, res.Err)
+			errs[index[i]] = res.Err
+		case !rolledBack:
+			result.Status = entity.BatchOK
+			result.ID = res.ID
+		}
+	}
+
+	return results, errs, nil
+}
+
+// batchOp turns one operation of the request into a repository write,
+// validated the same way as the single task endpoints.
+func (s *TaskService) batchOp(item entity.BatchOperation, now time.Time) (repository.TaskOp, error) {
+	op := repository.TaskOp{Op: item.Op, TaskID: item.ID, Version: item.Version}
+	if item.Op != entity.BatchCreate && item.ID <= 0 {
+		return op, errors.New("Invalid task id")
+	}
+
+	switch item.Op {
+	case entity.BatchCreate:
+		if item.Task == nil {
+			return op, errors.New("Create requires a task")
+		}
+		task, err := s.newTask(*item.Task)
+		if err != nil {
+			return op, err
+		}
+		op.TaskID, op.Task = 0, task
+	case entity.BatchUpdate:
+		patch, err := entity.ParseTaskPatch(item.Patch)
+		if err != nil {
+			return op, err
+		}
+		op.Apply = func(task *entity.Task) error {
+			return s.applyPatch(task, patch)
+		}
+	case entity.BatchDelete:
+		// достаточно id и версии
+	case entity.BatchComplete:
+		op.Next = s.nextOccurrence
+		op.CompletedAt = now
+	default:
+		return op, errors.New("Unknown batch operation")
+	}
+
+	return op, nil
+}
+
+func failResult(result *entity.BatchResult, err error) {
+	result.Status = entity.BatchFailed
+	result.Error = err.Error()
+
+	var fields entity.FieldErrors
+	if errors.As(err, &fields) {
+		result.Error = "Invalid task fields"
+		result.Fields = fields
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	notFound := errors.New("record not found")
+
+	operations := []entity.BatchOperation{
+		{Op: entity.BatchCreate, Task: &entity.Task{Description: "Gym"}},
+		{Op: entity.BatchUpdate, ID: 2, Version: 3, Patch: json.RawMessage(`{"priority":1}`)},
+		{Op: entity.BatchComplete, ID: 3},
+	}
+	invalid := append(append([]entity.BatchOperation{}, operations...),
+		entity.BatchOperation{Op: entity.BatchUpdate, ID: 4, Patch: json.RawMessage(`{"colour":"red"}`)})
+
+	// операции, которые service передаёт дальше, проверяются без функций
+	expectOps := func(crepo *mock_cache.MockTaskList, atomic bool, results []repository.TaskOpResult) {
+		crepo.EXPECT().Batch(1, gomock.Any(), atomic).DoAndReturn(
+			func(_ int, ops []repository.TaskOp, _ bool) ([]repository.TaskOpResult, error) {
+				assert.Len(t, ops, 3)
+				assert.Equal(t, entity.Task{Description: "Gym", Status: entity.StatusTodo, Priority: 4, CreatedAt: now}, ops[0].Task)
+
+				task := entity.Task{ID: 2, Priority: 4}
+				assert.NoError(t, ops[1].Apply(&task))
+				assert.Equal(t, 1, task.Priority)
+				assert.Equal(t, 3, ops[1].Version)
+
+				assert.Equal(t, 3, ops[2].TaskID)
+				assert.Equal(t, now, ops[2].CompletedAt)
+				return results, nil
+			})
+	}
+
+	tests := []struct {
+		name         string
+		req          entity.BatchRequest
+		mockBehavior func(crepo *mock_cache.MockTaskList)
+		want         []entity.BatchStatus
+	}{
+		{
+			name: "Atomic Success",
+			req:  entity.BatchRequest{Operations: operations},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				expectOps(crepo, true, []repository.TaskOpResult{{ID: 10}, {}, {}})
+			},
+			want: []entity.BatchStatus{entity.BatchOK, entity.BatchOK, entity.BatchOK},
+		},
+		{
+			name: "Atomic Rolled Back",
+			req:  entity.BatchRequest{Mode: entity.BatchAtomic, Operations: operations},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				expectOps(crepo, true, []repository.TaskOpResult{{ID: 10}, {Err: notFound}})
+			},
+			want: []entity.BatchStatus{entity.BatchSkipped, entity.BatchFailed, entity.BatchSkipped},
+		},
+		{
+			name:         "Atomic Invalid Operation",
+			req:          entity.BatchRequest{Operations: invalid},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {},
+			want:         []entity.BatchStatus{entity.BatchSkipped, entity.BatchSkipped, entity.BatchSkipped, entity.BatchFailed},
+		},
+		{
+			name: "Best Effort",
+			req:  entity.BatchRequest{Mode: entity.BatchBestEffort, Operations: invalid},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				expectOps(crepo, false, []repository.TaskOpResult{{ID: 10}, {Err: notFound}, {}})
+			},
+			want: []entity.BatchStatus{entity.BatchOK, entity.BatchFailed, entity.BatchOK, entity.BatchFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockTaskList(ctrl)
+			tt.mockBehavior(crepo)
+
+			s := NewTaskService(crepo)
+			s.now = func() time.Time { return now }
+
+			results, err := s.Batch(1, tt.req)
+
+			assert.NoError(t, err)
+			statuses := make([]entity.BatchStatus, len(results))
+			for i, res := range results {
+				statuses[i] = res.Status
+				assert.Equal(t, i, res.Index)
+			}
+			assert.Equal(t, tt.want, statuses)
+		})
+	}
+
+	s := NewTaskService(nil)
+	results, err := s.Batch(1, entity.BatchRequest{Mode: entity.BatchBestEffort, Operations: invalid[3:]})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.BatchResult{{
+		Index:  0,
+		Op:     entity.BatchUpdate,
+		Status: entity.BatchFailed,
+		Error:  "Invalid task fields",
+		Fields: entity.FieldErrors{"colour": "unknown field"},
+	}}, results)
+
+	_, err = s.Batch(1, entity.BatchRequest{})
+	assert.EqualError(t, err, "Batch must contain from 1 to 500 operations")
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/exporter"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+// CalDAVService keeps what CalDAV sync needs besides the tasks themselves:
+// app passwords for HTTP Basic and the resource names clients chose. The
+// tasks go through TaskList like any other client's.
+type CalDAVService struct {
+	repo repository.CalDAV
+}
+
+func NewCalDAVService(repo repository.CalDAV) *CalDAVService {
+	return &CalDAVService{repo: repo}
+}
+
+// CreateAppPassword issues a random password for one CalDAV client. It is
+// returned only here, the database keeps its hash.
+func (s *CalDAVService) CreateAppPassword(userID int, name string) (entity.AppPasswordResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return entity.AppPasswordResponse{}, errors.New("Invalid app password name")
+	}
+
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return entity.AppPasswordResponse{}, err
+	}
+	password := base64.RawURLEncoding.EncodeToString(random)
+
+	id, err := s.repo.CreateAppPassword(entity.AppPassword{UserID: userID, Name: name, Hash: hashAppPassword(password)})
+	if err != nil {
+		return entity.AppPasswordResponse{}, err
+	}
+
+	return entity.AppPasswordResponse{ID: id, Name: name, Password: password}, nil
+}
+
+func (s *CalDAVService) GetAppPasswords(userID int) ([]entity.AppPassword, error) {
+	return s.repo.GetAppPasswords(userID)
+}
+
+func (s *CalDAVService) DeleteAppPassword(userID, passwordID int) error {
+	if passwordID <= 0 {
+		return errors.New("Invalid app password id")
+	}
+	return s.repo.DeleteAppPassword(userID, passwordID)
+}
+
+// CheckAppPassword returns the user signing in with HTTP Basic. App passwords
+// are random and long, so a fast hash is enough, unlike account passwords:
+// clients send them with every request.
+func (s *CalDAVService) CheckAppPassword(username, password string) (int, error) {
+	if username == "" || password == "" {
+		return 0, entity.ErrUnauthorized
+	}
+	return s.repo.GetAppPasswordUser(username, hashAppPassword(password))
+}
+
+// GetCalendarObjects maps task ids to the resources clients created.
+func (s *CalDAVService) GetCalendarObjects(userID int) (map[int]entity.CalendarObject, error) {
+	objects, err := s.repo.GetCalendarObjects(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[int]entity.CalendarObject, len(objects))
+	for _, object := range objects {
+		byTask[object.TaskID] = object
+	}
+	return byTask, nil
+}
+
+// FindCalendarObject finds the task behind a resource name of the user's
+// calendar, nil when no task visible to the user has the name.
+func (s *CalDAVService) FindCalendarObject(userID int, name string) (*entity.CalendarObject, error) {
+	taskID, _ := defaultObjectID(name, func(id int) string { return DefaultCalendarObject(userID, id).Name })
+
+	object, err := s.repo.FindCalendarObject(userID, name, taskID)
+	if err != nil || object == nil {
+		return nil, err
+	}
+	if object.Name == "" {
+		*object = DefaultCalendarObject(userID, object.TaskID)
+	}
+	return object, nil
+}
+
+// CalendarNameReserved tells whether the name has the form of a default
+// name, like "task-5.ics". A client may not create an object with it: once
+// the task with that id is visible it is served under the name as well.
+func (s *CalDAVService) CalendarNameReserved(name string) bool {
+	_, ok := defaultObjectID(name, func(id int) string { return DefaultCalendarObject(0, id).Name })
+	return ok
+}
+
+// CalendarUIDTaken tells whether a task of the user's calendar already has
+// the UID, a client may not create another one with it. UIDs of the default
+// form are always taken, like default names.
+func (s *CalDAVService) CalendarUIDTaken(userID int, uid string) (bool, error) {
+	if _, ok := defaultObjectID(uid, exporter.TaskUID); ok {
+		return true, nil
+	}
+	return s.repo.HasCalendarUID(userID, uid)
+}
+
+// GetRemovedCalendarObjects returns the names clients gave tasks that went
+// to the trash since the token.
+func (s *CalDAVService) GetRemovedCalendarObjects(userID int, since entity.SyncToken) ([]entity.CalendarObject, error) {
+	if since <= 0 {
+		return nil, nil
+	}
+	return s.repo.GetRemovedCalendarObjects(userID, since)
+}
+
+// DefaultCalendarObject is how a task no client of the user named is served.
+func DefaultCalendarObject(userID, taskID int) entity.CalendarObject {
+	return entity.CalendarObject{
+		TaskID: taskID,
+		UserID: userID,
+		Name:   fmt.Sprintf("task-%d.ics", taskID),
+		UID:    exporter.TaskUID(taskID),
+	}
+}
+
+// defaultObjectID returns the task id a name or UID was made from by format,
+// like "task-5.ics" from 5.
+func defaultObjectID(s string, format func(taskID int) string) (int, bool) {
+	digits, ok := strings.CutPrefix(s, "task-")
+	if !ok {
+		return 0, false
+	}
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+
+	id, err := strconv.Atoi(digits)
+	if err != nil || id <= 0 || format(id) != s {
+		return 0, false
+	}
+	return id, true
+}
+
+func hashAppPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_repository "github.com/AronditFire/todo-app/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalDAVService_CreateAppPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		mockBehavior func(repo *mock_repository.MockCalDAV, stored *entity.AppPassword)
+		wantErr      bool
+	}{
+		{
+			name:  "OK",
+			input: "  iPhone ",
+			mockBehavior: func(repo *mock_repository.MockCalDAV, stored *entity.AppPassword) {
+				repo.EXPECT().CreateAppPassword(gomock.Any()).DoAndReturn(func(p entity.AppPassword) (int, error) {
+					*stored = p
+					return 3, nil
+				})
+			},
+		},
+		{
+			name:         "Blank Name",
+			input:        "   ",
+			mockBehavior: func(*mock_repository.MockCalDAV, *entity.AppPassword) {},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockCalDAV(c)
+			var stored entity.AppPassword
+			tt.mockBehavior(repo, &stored)
+
+			s := NewCalDAVService(repo)
+			resp, err := s.CreateAppPassword(1, tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 3, resp.ID)
+			assert.Equal(t, "iPhone", resp.Name)
+			assert.Len(t, resp.Password, 32)
+			// хранится только хеш выданного пароля
+			assert.Equal(t, entity.AppPassword{UserID: 1, Name: "iPhone", Hash: hashAppPassword(resp.Password)}, stored)
+		})
+	}
+}
+
+func TestCalDAVService_CheckAppPassword(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockCalDAV(c)
+	repo.EXPECT().GetAppPasswordUser("alice", hashAppPassword("secret")).Return(7, nil)
+
+	s := NewCalDAVService(repo)
+
+	id, err := s.CheckAppPassword("alice", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	_, err = s.CheckAppPassword("alice", "")
+	assert.ErrorIs(t, err, entity.ErrUnauthorized)
+}
+
+func TestCalDAVService_FindCalendarObject(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockCalDAV(c)
+	repo.EXPECT().FindCalendarObject(1, "task-5.ics", 5).Return(&entity.CalendarObject{TaskID: 5, UserID: 1}, nil)
+	repo.EXPECT().FindCalendarObject(1, "task-05.ics", 0).Return(nil, nil)
+	repo.EXPECT().FindCalendarObject(1, "abc.ics", 0).Return(&entity.CalendarObject{TaskID: 9, UserID: 1, Name: "abc.ics", UID: "abc"}, nil)
+
+	s := NewCalDAVService(repo)
+
+	object, err := s.FindCalendarObject(1, "task-5.ics")
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.CalendarObject{TaskID: 5, UserID: 1, Name: "task-5.ics", UID: "task-5@todo-app"}, object)
+
+	// у задачи одно имя, вариант с ведущим нулём - чужое имя
+	object, err = s.FindCalendarObject(1, "task-05.ics")
+	assert.NoError(t, err)
+	assert.Nil(t, object)
+
+	object, err = s.FindCalendarObject(1, "abc.ics")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", object.UID)
+}
+
+func TestCalDAVService_CalendarNameReserved(t *testing.T) {
+	s := NewCalDAVService(nil)
+
+	assert.True(t, s.CalendarNameReserved("task-5.ics"))
+	assert.False(t, s.CalendarNameReserved("task-05.ics"))
+	assert.False(t, s.CalendarNameReserved("task-5.ics.bak"))
+	assert.False(t, s.CalendarNameReserved("abc.ics"))
+}
+
+func TestCalDAVService_CalendarUIDTaken(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockCalDAV(c)
+	repo.EXPECT().HasCalendarUID(1, "abc").Return(true, nil)
+
+	s := NewCalDAVService(repo)
+
+	// задача 5 может появиться позже, её UID занят заранее
+	taken, err := s.CalendarUIDTaken(1, "task-5@todo-app")
+	assert.NoError(t, err)
+	assert.True(t, taken)
+
+	taken, err = s.CalendarUIDTaken(1, "abc")
+	assert.NoError(t, err)
+	assert.True(t, taken)
+}
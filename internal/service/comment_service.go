@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+// maxCommentLength limits the text of one comment in characters.
+const maxCommentLength = 5000
+
+type CommentService struct {
+	crepo cache.Comments
+}
+
+func NewCommentService(crepo cache.Comments) *CommentService {
+	return &CommentService{crepo: crepo}
+}
+
+// GetCommentPage returns comments of the task after the cursor, oldest first.
+func (s *CommentService) GetCommentPage(userID, taskID int, after *entity.CommentCursor, limit int) (entity.CommentPage, error) {
+	if taskID <= 0 {
+		return entity.CommentPage{}, errors.New("Invalid id while trying to get comments")
+	}
+	if limit <= 0 || limit > maxPageSize {
+		return entity.CommentPage{}, errors.New("Invalid page size")
+	}
+
+	afterID := 0
+	if after != nil {
+		afterID = after.ID
+	}
+
+	// один лишний комментарий показывает, есть ли следующая страница
+	comments, err := s.crepo.GetComments(userID, taskID, afterID, limit+1)
+	if err != nil {
+		return entity.CommentPage{}, err
+	}
+
+	page := entity.CommentPage{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		page.NextCursor = entity.CommentCursor{ID: comments[limit-1].ID}.Encode()
+	}
+
+	return page, nil
+}
+
+func (s *CommentService) CreateComment(userID, taskID int, body string) (int, error) {
+	if taskID <= 0 {
+		return 0, errors.New("Invalid id while trying to create comment")
+	}
+
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.crepo.CreateComment(userID, taskID, body)
+}
+
+func (s *CommentService) UpdateComment(userID, taskID, commentID int, body string) error {
+	if taskID <= 0 || commentID <= 0 {
+		return errors.New("Invalid id while trying to update comment")
+	}
+
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return err
+	}
+
+	return s.crepo.UpdateComment(userID, taskID, commentID, body)
+}
+
+func (s *CommentService) DeleteComment(userID, taskID, commentID int) error {
+	if taskID <= 0 || commentID <= 0 {
+		return errors.New("Invalid id while trying to delete comment")
+	}
+
+	return s.crepo.DeleteComment(userID, taskID, commentID)
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("Comment can not be empty")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", errors.New("Comment is too long")
+	}
+
+	return body, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommentPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockComments(ctrl)
+	s := NewCommentService(crepo)
+
+	t.Run("Has Next Page", func(t *testing.T) {
+		crepo.EXPECT().GetComments(1, 5, 10, 3).
+			Return([]entity.Comment{{ID: 11}, {ID: 12}, {ID: 13}}, nil)
+
+		page, err := s.GetCommentPage(1, 5, &entity.CommentCursor{ID: 10}, 2)
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Comments, 2)
+		assert.Equal(t, entity.CommentCursor{ID: 12}.Encode(), page.NextCursor)
+	})
+
+	t.Run("Last Page", func(t *testing.T) {
+		crepo.EXPECT().GetComments(1, 5, 0, 3).Return([]entity.Comment{{ID: 1}}, nil)
+
+		page, err := s.GetCommentPage(1, 5, nil, 2)
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Comments, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Invalid Page Size", func(t *testing.T) {
+		_, err := s.GetCommentPage(1, 5, nil, maxPageSize+1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		mock    func(crepo *mock_cache.MockComments)
+		wantErr bool
+	}{
+		{
+			name: "Trimmed",
+			body: "  looks good \n",
+			mock: func(crepo *mock_cache.MockComments) {
+				crepo.EXPECT().CreateComment(1, 5, "looks good").Return(7, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Empty",
+			body:    "   ",
+			mock:    func(*mock_cache.MockComments) {},
+			wantErr: true,
+		},
+		{
+			name:    "Too Long",
+			body:    strings.Repeat("я", maxCommentLength+1),
+			mock:    func(*mock_cache.MockComments) {},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockComments(ctrl)
+			tt.mock(crepo)
+
+			_, err := NewCommentService(crepo).CreateComment(1, 5, tt.body)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
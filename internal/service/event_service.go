@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+// EventPollInterval is how often a stream reads the change log without a
+// wake-up: a change whose pub/sub message was lost is late by no more than
+// it. Every read is sent, so it is also the heartbeat of the stream.
+const EventPollInterval = 25 * time.Second
+
+// EventService streams task events to a user. Events are read from the same
+// change log as the sync API and carry the sync token after them, so a
+// dropped stream resumes from its last token without missing a change.
+type EventService struct {
+	crepo cache.Events
+	tasks cache.TaskList
+	poll  time.Duration // укорачивается в тестах
+}
+
+func NewEventService(crepo cache.Events, tasks cache.TaskList) *EventService {
+	return &EventService{crepo: crepo, tasks: tasks, poll: EventPollInterval}
+}
+
+// StreamTasks sends the user's task events since the token until ctx is
+// done. A zero token starts from now. Every read of the change log goes to
+// send with the token after it, empty ones too. A version of a task is sent
+// once per stream. StreamTasks stops on the first error of send.
+func (s *EventService) StreamTasks(ctx context.Context, userID int, since entity.SyncToken, send func(events []entity.TaskEvent, token entity.SyncToken) error) error {
+	// подписываемся до первого чтения журнала, чтобы не проспать изменение между ними
+	wake, err := s.crepo.SubscribeTasks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if since == 0 {
+		if since, err = s.tasks.CurrentSyncToken(); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+
+	sent := make(streamedTasks)
+	for {
+		changes, err := s.tasks.GetChanges(userID, since)
+		if err != nil {
+			return err
+		}
+		if err := send(sent.filter(taskEvents(changes)), changes.Token); err != nil {
+			return err
+		}
+		since = changes.Token
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-wake:
+			if !ok {
+				// подписка потеряна - дальше только опрос
+				wake = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamedTasks remembers what a stream has sent about each task. While an
+// older transaction is running the token stays at its xmin and every read
+// returns the same changes again, the stream sends each of them once.
+type streamedTasks map[int]streamedTask
+
+type streamedTask struct {
+	version int
+	deleted bool
+}
+
+// filter drops the events the stream has already sent. A task the client
+// already got comes again as task.updated, never as a second task.created.
+func (s streamedTasks) filter(events []entity.TaskEvent) []entity.TaskEvent {
+	fresh := events[:0]
+	for _, event := range events {
+		last, seen := s[event.TaskID]
+
+		if event.Type == entity.EventTaskDeleted {
+			if seen && last.deleted {
+				continue
+			}
+			s[event.TaskID] = streamedTask{deleted: true}
+			fresh = append(fresh, event)
+			continue
+		}
+
+		if seen && !last.deleted {
+			if last.version == event.Task.Version {
+				continue
+			}
+			event.Type = entity.EventTaskUpdated
+		}
+		s[event.TaskID] = streamedTask{version: event.Task.Version}
+		fresh = append(fresh, event)
+	}
+
+	return fresh
+}
+
+// taskEvents turns changes into events: deleted tasks first, then the rest
+// in the order of the change log read.
+func taskEvents(changes entity.TaskChanges) []entity.TaskEvent {
+	created := make(map[int]bool, len(changes.Created))
+	for _, id := range changes.Created {
+		created[id] = true
+	}
+
+	events := make([]entity.TaskEvent, 0, len(changes.Deleted)+len(changes.Tasks))
+	for _, id := range changes.Deleted {
+		events = append(events, entity.TaskEvent{Type: entity.EventTaskDeleted, TaskID: id})
+	}
+	for i := range changes.Tasks {
+		event := entity.TaskEvent{Type: entity.EventTaskUpdated, TaskID: changes.Tasks[i].ID, Task: &changes.Tasks[i]}
+		if created[event.TaskID] {
+			event.Type = entity.EventTaskCreated
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
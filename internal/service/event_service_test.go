@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wake := make(chan struct{}, 1)
+	events := mock_cache.NewMockEvents(ctrl)
+	events.EXPECT().SubscribeTasks(gomock.Any(), 1).Return(wake, nil)
+
+	tasks := mock_cache.NewMockTaskList(ctrl)
+	tasks.EXPECT().CurrentSyncToken().Return(entity.SyncToken(100), nil)
+	gomock.InOrder(
+		tasks.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{Token: 100}, nil),
+		tasks.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{
+			Tasks:   []entity.Task{{ID: 2}, {ID: 3}},
+			Created: []int{3},
+			Deleted: []int{4},
+			Token:   120,
+		}, nil),
+	)
+
+	s := &EventService{crepo: events, tasks: tasks, poll: time.Hour}
+
+	var batches [][]entity.TaskEvent
+	var tokens []entity.SyncToken
+	err := s.StreamTasks(ctx, 1, 0, func(events []entity.TaskEvent, token entity.SyncToken) error {
+		batches = append(batches, events)
+		tokens = append(tokens, token)
+		if len(batches) == 1 {
+			wake <- struct{}{} // задачи изменились на другой реплике
+		} else {
+			cancel() // клиент ушёл
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.SyncToken{100, 120}, tokens)
+	assert.Empty(t, batches[0])
+	assert.Equal(t, []entity.TaskEvent{
+		{Type: entity.EventTaskDeleted, TaskID: 4},
+		{Type: entity.EventTaskUpdated, TaskID: 2, Task: &entity.Task{ID: 2}},
+		{Type: entity.EventTaskCreated, TaskID: 3, Task: &entity.Task{ID: 3}},
+	}, batches[1])
+}
+
+// An older transaction that stays open holds the token back, every read
+// returns the same changes. They are sent once and a task is created once.
+func TestStreamTasks_OpenOlderTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wake := make(chan struct{}, 1)
+	events := mock_cache.NewMockEvents(ctrl)
+	events.EXPECT().SubscribeTasks(gomock.Any(), 1).Return(wake, nil)
+
+	tasks := mock_cache.NewMockTaskList(ctrl)
+	gomock.InOrder(
+		tasks.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{
+			Tasks:   []entity.Task{{ID: 3, Version: 1}},
+			Created: []int{3},
+			Deleted: []int{4},
+			Token:   100,
+		}, nil),
+		// ничего нового
+		tasks.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{
+			Tasks:   []entity.Task{{ID: 3, Version: 1}},
+			Created: []int{3},
+			Deleted: []int{4},
+			Token:   100,
+		}, nil),
+		// задачу 3 поправили, журнал всё ещё помнит её создание
+		tasks.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{
+			Tasks:   []entity.Task{{ID: 3, Version: 2}},
+			Created: []int{3},
+			Deleted: []int{4},
+			Token:   100,
+		}, nil),
+	)
+
+	s := &EventService{crepo: events, tasks: tasks, poll: time.Hour}
+
+	var batches [][]entity.TaskEvent
+	err := s.StreamTasks(ctx, 1, 100, func(events []entity.TaskEvent, _ entity.SyncToken) error {
+		batches = append(batches, slices.Clone(events))
+		if len(batches) < 3 {
+			wake <- struct{}{}
+		} else {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]entity.TaskEvent{
+		{
+			{Type: entity.EventTaskDeleted, TaskID: 4},
+			{Type: entity.EventTaskCreated, TaskID: 3, Task: &entity.Task{ID: 3, Version: 1}},
+		},
+		{},
+		{
+			{Type: entity.EventTaskUpdated, TaskID: 3, Task: &entity.Task{ID: 3, Version: 2}},
+		},
+	}, batches)
+}
+
+func TestStreamTasks_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := mock_cache.NewMockEvents(ctrl)
+	events.EXPECT().SubscribeTasks(gomock.Any(), 1).Return(make(chan struct{}), nil)
+
+	// с токеном клиента текущий токен не нужен
+	tasks := mock_cache.NewMockTaskList(ctrl)
+	tasks.EXPECT().GetChanges(1, entity.SyncToken(90)).Return(entity.TaskChanges{Token: 100}, nil)
+
+	s := &EventService{crepo: events, tasks: tasks, poll: time.Hour}
+
+	sendErr := errors.New("broken pipe")
+	err := s.StreamTasks(context.Background(), 1, 90, func([]entity.TaskEvent, entity.SyncToken) error {
+		return sendErr
+	})
+
+	assert.ErrorIs(t, err, sendErr)
+}
+
+func TestStreamTasks_SubscribeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := mock_cache.NewMockEvents(ctrl)
+	events.EXPECT().SubscribeTasks(gomock.Any(), 1).Return(nil, errors.New("redis error"))
+
+	s := NewEventService(events, mock_cache.NewMockTaskList(ctrl))
+
+	err := s.StreamTasks(context.Background(), 1, 0, func([]entity.TaskEvent, entity.SyncToken) error {
+		t.Fatal("nothing should be sent")
+		return nil
+	})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/exporter"
+)
+
+// ExportTasks writes every task the user can see to w in the format, ordered
+// by id. Tasks are encoded as they are read, nothing is collected in memory.
+func (s *TaskService) ExportTasks(userID int, format entity.ExportFormat, w io.Writer) error {
+	if !format.IsValid() {
+		return errors.New("Invalid export format")
+	}
+
+	buf := bufio.NewWriter(w)
+	out, err := exporter.New(format, buf)
+	if err != nil {
+		return err
+	}
+
+	if err := s.crepo.ExportTasks(userID, out.Write); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
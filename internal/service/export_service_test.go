@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTasks(t *testing.T) {
+	tasks := []entity.Task{
+		{ID: 1, Description: "Buy milk", Status: entity.StatusTodo, Priority: 2},
+		{ID: 2, Description: "Gym", Status: entity.StatusDone, Priority: 4},
+	}
+	stream := func(crepo *mock_cache.MockTaskList, err error) {
+		crepo.EXPECT().ExportTasks(1, gomock.Any()).DoAndReturn(func(_ int, fn func(entity.Task) error) error {
+			for _, task := range tasks {
+				if err := fn(task); err != nil {
+					return err
+				}
+			}
+			return err
+		})
+	}
+
+	tests := []struct {
+		name         string
+		format       entity.ExportFormat
+		mockBehavior func(crepo *mock_cache.MockTaskList)
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:   "Markdown",
+			format: entity.ExportMarkdown,
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				stream(crepo, nil)
+			},
+			want: "# Tasks\n\n- [ ] Buy milk — P2\n- [x] Gym — P4\n",
+		},
+		{
+			name:   "Repository Error",
+			format: entity.ExportJSON,
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				stream(crepo, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Unknown Format",
+			format:       "xml",
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockTaskList(ctrl)
+			tt.mockBehavior(crepo)
+
+			var buf bytes.Buffer
+			err := NewTaskService(crepo).ExportTasks(1, tt.format, &buf)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+	"github.com/AronditFire/todo-app/internal/exporter"
+)
+
+// feedName is the calendar name calendar apps show for the feed.
+const feedName = "Tasks"
+
+// FeedService serves the read-only iCalendar feed of the user's tasks with
+// due dates. The feed URL carries a secret token instead of a JWT, calendar
+// apps can not send one.
+type FeedService struct {
+	crepo cache.Feeds
+	tasks cache.TaskList
+	now   func() time.Time // подменяется в тестах
+}
+
+func NewFeedService(crepo cache.Feeds, tasks cache.TaskList) *FeedService {
+	return &FeedService{crepo: crepo, tasks: tasks, now: time.Now}
+}
+
+// CreateFeedToken issues a new feed token, the previous one stops working.
+// The token is returned only here, the database keeps its hash.
+func (s *FeedService) CreateFeedToken(userID int) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+
+	if _, err := s.crepo.SaveFeedToken(userID, hashFeedToken(token)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *FeedService) RevokeFeedToken(userID int) error {
+	_, err := s.crepo.DeleteFeedToken(userID)
+	return err
+}
+
+// GetFeed returns the feed of the token's owner. A feed is rendered once and
+// served from the cache until the owner's tasks change. ModifiedAt moves only
+// when the rendered calendar differs from the previous one.
+func (s *FeedService) GetFeed(token string) (entity.Feed, error) {
+	if token == "" {
+		return entity.Feed{}, entity.ErrFeedNotFound
+	}
+
+	userID, err := s.crepo.GetFeedUser(hashFeedToken(token))
+	if err != nil {
+		return entity.Feed{}, err
+	}
+
+	cached, err := s.crepo.GetFeed(userID)
+	if err != nil {
+		return entity.Feed{}, err
+	}
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var body bytes.Buffer
+	w, err := exporter.NewICSWriter(&body, feedName)
+	if err != nil {
+		return entity.Feed{}, err
+	}
+	if err := s.tasks.ExportTasks(userID, w.Write); err != nil {
+		return entity.Feed{}, err
+	}
+	if err := w.Close(); err != nil {
+		return entity.Feed{}, err
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	feed := entity.Feed{
+		ETag:       strconv.Quote(hex.EncodeToString(sum[:16])),
+		ModifiedAt: s.now().UTC().Truncate(time.Second), // Last-Modified точнее секунды не бывает
+		Body:       body.Bytes(),
+	}
+
+	prev, err := s.crepo.GetFeedVersion(userID)
+	if err != nil {
+		return entity.Feed{}, err
+	}
+	if prev != nil && prev.ETag == feed.ETag {
+		feed.ModifiedAt = prev.ModifiedAt
+	}
+
+	return feed, s.crepo.SaveFeed(userID, feed)
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
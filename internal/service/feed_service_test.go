@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/AronditFire/todo-app/internal/exporter"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedService_GetFeed(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 500, time.UTC)
+	due := time.Date(2025, 5, 7, 9, 0, 0, 0, time.UTC)
+	token := "secret"
+	tokenHash := hashFeedToken(token)
+	earlier := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	cached := entity.Feed{ETag: `"abc"`, ModifiedAt: now, Body: []byte("BEGIN:VCALENDAR\r\n")}
+
+	// ETag календаря без задач: его же даёт повторный рендер без изменений
+	var empty bytes.Buffer
+	w, err := exporter.NewICSWriter(&empty, feedName)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	sum := sha256.Sum256(empty.Bytes())
+	emptyETag := strconv.Quote(hex.EncodeToString(sum[:16]))
+
+	tests := []struct {
+		name         string
+		token        string
+		mockBehavior func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList)
+		check        func(t *testing.T, feed entity.Feed)
+		wantErr      error
+	}{
+		{
+			name:  "Cached",
+			token: token,
+			mockBehavior: func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList) {
+				feeds.EXPECT().GetFeedUser(tokenHash).Return(1, nil)
+				feeds.EXPECT().GetFeed(1).Return(&cached, nil)
+			},
+			check: func(t *testing.T, feed entity.Feed) {
+				assert.Equal(t, cached, feed)
+			},
+		},
+		{
+			name:  "Rendered",
+			token: token,
+			mockBehavior: func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList) {
+				feeds.EXPECT().GetFeedUser(tokenHash).Return(1, nil)
+				feeds.EXPECT().GetFeed(1).Return(nil, nil)
+				tasks.EXPECT().ExportTasks(1, gomock.Any()).DoAndReturn(func(_ int, fn func(entity.Task) error) error {
+					if err := fn(entity.Task{ID: 5, Description: "Dentist", DueAt: &due}); err != nil {
+						return err
+					}
+					return fn(entity.Task{ID: 6, Description: "Someday"})
+				})
+				feeds.EXPECT().GetFeedVersion(1).Return(&entity.Feed{ETag: `"old"`, ModifiedAt: earlier}, nil)
+				feeds.EXPECT().SaveFeed(1, gomock.Any()).Return(nil)
+			},
+			check: func(t *testing.T, feed entity.Feed) {
+				body := string(feed.Body)
+				assert.Contains(t, body, "UID:task-5@todo-app\r\n")
+				assert.NotContains(t, body, "Someday")
+				assert.Equal(t, now.Truncate(time.Second), feed.ModifiedAt)
+				assert.True(t, strings.HasPrefix(feed.ETag, `"`) && strings.HasSuffix(feed.ETag, `"`))
+			},
+		},
+		{
+			name:  "Unchanged Keeps ModifiedAt",
+			token: token,
+			mockBehavior: func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList) {
+				feeds.EXPECT().GetFeedUser(tokenHash).Return(1, nil)
+				feeds.EXPECT().GetFeed(1).Return(nil, nil)
+				tasks.EXPECT().ExportTasks(1, gomock.Any()).Return(nil)
+				feeds.EXPECT().GetFeedVersion(1).Return(&entity.Feed{ETag: emptyETag, ModifiedAt: earlier}, nil)
+				feeds.EXPECT().SaveFeed(1, gomock.Any()).Return(nil)
+			},
+			check: func(t *testing.T, feed entity.Feed) {
+				assert.Equal(t, emptyETag, feed.ETag)
+				assert.Equal(t, earlier, feed.ModifiedAt)
+			},
+		},
+		{
+			name:  "Unknown Token",
+			token: token,
+			mockBehavior: func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList) {
+				feeds.EXPECT().GetFeedUser(tokenHash).Return(0, entity.ErrFeedNotFound)
+			},
+			wantErr: entity.ErrFeedNotFound,
+		},
+		{
+			name:         "Empty Token",
+			mockBehavior: func(feeds *mock_cache.MockFeeds, tasks *mock_cache.MockTaskList) {},
+			wantErr:      entity.ErrFeedNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			feeds := mock_cache.NewMockFeeds(ctrl)
+			tasks := mock_cache.NewMockTaskList(ctrl)
+			tt.mockBehavior(feeds, tasks)
+
+			s := NewFeedService(feeds, tasks)
+			s.now = func() time.Time { return now }
+
+			feed, err := s.GetFeed(tt.token)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, feed)
+		})
+	}
+}
+
+func TestFeedService_CreateFeedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	feeds := mock_cache.NewMockFeeds(ctrl)
+	var saved string
+	feeds.EXPECT().SaveFeedToken(1, gomock.Any()).DoAndReturn(func(_ int, hash string) (string, error) {
+		saved = hash
+		return "", nil
+	})
+
+	token, err := NewFeedService(feeds, nil).CreateFeedToken(1)
+	assert.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, hashFeedToken(token), saved)
+	assert.NotContains(t, saved, token)
+
+	feeds.EXPECT().SaveFeedToken(2, gomock.Any()).Return("", errors.New("db error"))
+	_, err = NewFeedService(feeds, nil).CreateFeedToken(2)
+	assert.Error(t, err)
+}
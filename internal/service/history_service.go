@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+// HistoryService reads the audit trail straight from the repository,
+// it is never cached.
+type HistoryService struct {
+	repo repository.History
+}
+
+func NewHistoryService(repo repository.History) *HistoryService {
+	return &HistoryService{repo: repo}
+}
+
+func (s *HistoryService) GetTaskHistory(userID, taskID int) ([]entity.TaskHistory, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get task history")
+	}
+
+	return s.repo.GetTaskHistory(userID, taskID)
+}
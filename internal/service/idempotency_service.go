@@ -0,0 +1,85 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+type IdempotencyService struct {
+	crepo  cache.Idempotency
+	extend time.Duration // как часто продлевается отметка, с запасом меньше cache.PendingTTL
+}
+
+func NewIdempotencyService(crepo cache.Idempotency) *IdempotencyService {
+	return &IdempotencyService{crepo: crepo, extend: cache.PendingTTL / 3}
+}
+
+// ReserveKey returns the stored response when the request is a retry and nil
+// when it has to be executed. The fingerprint identifies the request: a key
+// sent with another request is rejected, as is a retry of a request that is
+// still running.
+func (s *IdempotencyService) ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	stored, err := s.crepo.ReserveKey(scope, key, fingerprint)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	switch {
+	case stored.Fingerprint != fingerprint:
+		return nil, entity.ErrIdempotencyKeyReused
+	case stored.Pending:
+		return nil, entity.ErrIdempotencyInProgress
+	}
+
+	return stored, nil
+}
+
+// HoldKey keeps the pending mark of a reserved key alive while the request
+// runs, however long it takes, so a retry can not run it a second time. The
+// mark of a crashed server still expires. stop may be called more than once,
+// the mark is no longer touched after it returns.
+func (s *IdempotencyService) HoldKey(scope, key string) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(s.extend)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.crepo.ExtendKey(scope, key); err != nil {
+					log.Printf("Could not extend idempotency key: %v", err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// SaveResponse keeps the response for retries. Server errors are not kept:
+// the key is released, so a retry runs the request again.
+func (s *IdempotencyService) SaveResponse(scope, key string, resp entity.IdempotentResponse) error {
+	if resp.Status >= 500 {
+		return s.crepo.ReleaseKey(scope, key)
+	}
+
+	resp.Pending = false
+	return s.crepo.SaveResponse(scope, key, resp)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveKey(t *testing.T) {
+	stored := &entity.IdempotentResponse{Fingerprint: "abc", Status: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+
+	tests := []struct {
+		name          string
+		stored        *entity.IdempotentResponse
+		want          *entity.IdempotentResponse
+		expectedError error
+	}{
+		{name: "New Key"},
+		{name: "Retry", stored: stored, want: stored},
+		{name: "Another Request", stored: &entity.IdempotentResponse{Fingerprint: "def", Status: 201}, expectedError: entity.ErrIdempotencyKeyReused},
+		{name: "Still Running", stored: &entity.IdempotentResponse{Fingerprint: "abc", Pending: true}, expectedError: entity.ErrIdempotencyInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockIdempotency(ctrl)
+			crepo.EXPECT().ReserveKey("user:1", "key", "abc").Return(tt.stored, nil)
+
+			got, err := NewIdempotencyService(crepo).ReserveKey("user:1", "key", "abc")
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSaveResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockIdempotency(ctrl)
+	s := NewIdempotencyService(crepo)
+
+	resp := entity.IdempotentResponse{Fingerprint: "abc", Status: 422}
+	crepo.EXPECT().SaveResponse("user:1", "key", resp).Return(nil)
+	assert.NoError(t, s.SaveResponse("user:1", "key", resp))
+
+	// после ошибки сервера повтор должен выполниться заново
+	crepo.EXPECT().ReleaseKey("user:1", "key").Return(nil)
+	assert.NoError(t, s.SaveResponse("user:1", "key", entity.IdempotentResponse{Fingerprint: "abc", Status: 500}))
+}
+
+func TestHoldKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	extended := make(chan struct{}, 10)
+	crepo := mock_cache.NewMockIdempotency(ctrl)
+	crepo.EXPECT().ExtendKey("user:1", "key").DoAndReturn(func(string, string) error {
+		select {
+		case extended <- struct{}{}:
+		default:
+		}
+		return nil
+	}).MinTimes(2)
+
+	s := NewIdempotencyService(crepo)
+	s.extend = time.Millisecond
+
+	stop := s.HoldKey("user:1", "key")
+	// долгий запрос: отметка продлевается, пока он идёт
+	<-extended
+	<-extended
+	stop()
+	stop()
+
+	// после stop ключ больше не трогается
+	n := len(extended)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, n, len(extended))
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/importer"
+)
+
+const (
+	// MaxImportSize limits an uploaded export.
+	MaxImportSize = 5 << 20
+	// maxImportTasks limits the number of top level tasks of one import.
+	maxImportTasks = 1000
+)
+
+// ImportTasks reads an export of another tool and creates its tasks with
+// their labels and checklists in one transaction. Rows that can not be
+// mapped or do not pass the same checks as POST /api/ are reported and
+// skipped. With dryRun nothing is written and the report is a preview.
+func (s *TaskService) ImportTasks(userID int, format entity.ImportFormat, file *multipart.FileHeader, dryRun bool) (entity.ImportReport, error) {
+	report := entity.ImportReport{DryRun: dryRun, Tasks: []entity.ImportTask{}, Errors: []entity.ImportError{}}
+	if !format.IsValid() {
+		return report, errors.New("Invalid import format")
+	}
+	if file.Size > MaxImportSize {
+		return report, entity.ErrFileTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return report, err
+	}
+	defer f.Close()
+
+	tasks, rowErrors, err := importer.Parse(format, f)
+	if err != nil {
+		return report, fmt.Errorf("%w: %v", entity.ErrImportFile, err)
+	}
+	report.Errors = append(report.Errors, rowErrors...)
+
+	for _, item := range tasks {
+		if err := s.importTask(&item); err != nil {
+			report.Errors = append(report.Errors, entity.ImportError{Row: item.Row, Error: err.Error()})
+			continue
+		}
+		report.Tasks = append(report.Tasks, item)
+	}
+	if len(report.Tasks) > maxImportTasks {
+		return report, fmt.Errorf("%w: more than %d tasks", entity.ErrImportFile, maxImportTasks)
+	}
+
+	if dryRun || len(report.Tasks) == 0 {
+		return report, nil
+	}
+
+	ids, err := s.crepo.ImportTasks(userID, report.Tasks)
+	if err != nil {
+		return report, err
+	}
+	for i, id := range ids {
+		report.Tasks[i].Task.ID = id
+	}
+
+	return report, nil
+}
+
+// importTask validates one imported task like newTask does and cleans up
+// its labels.
+func (s *TaskService) importTask(item *entity.ImportTask) error {
+	task, err := s.newTask(item.Task)
+	if err != nil {
+		return err
+	}
+	item.Task = task
+
+	for i, sub := range item.Subtasks {
+		if sub, err = s.newTask(sub); err != nil {
+			return fmt.Errorf("checklist item %d: %w", i+1, err)
+		}
+		item.Subtasks[i] = sub
+	}
+
+	seen := make(map[string]bool, len(item.Labels))
+	labels := make([]string, 0, len(item.Labels))
+	for _, label := range item.Labels {
+		label = strings.TrimSpace(label)
+		if !validTagName(label) {
+			return fmt.Errorf("Invalid label %q", label)
+		}
+		if !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	item.Labels = labels
+
+	return nil
+}
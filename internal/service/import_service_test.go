@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTasks(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	file := []byte("title,priority,labels,checklist\n" +
+		"Buy milk,2, home ,Milk;Bread\n" +
+		"Gym,9,,\n" +
+		"Pay rent,,home,\n")
+
+	imported := []entity.ImportTask{
+		{
+			Row:    2,
+			Task:   entity.Task{Description: "Buy milk", Status: entity.StatusTodo, Priority: 2, CreatedAt: now},
+			Labels: []string{"home"},
+			Subtasks: []entity.Task{
+				{Description: "Milk", Status: entity.StatusTodo, Priority: 4, CreatedAt: now},
+				{Description: "Bread", Status: entity.StatusTodo, Priority: 4, CreatedAt: now},
+			},
+		},
+		{
+			Row:    4,
+			Task:   entity.Task{Description: "Pay rent", Status: entity.StatusTodo, Priority: 4, CreatedAt: now},
+			Labels: []string{"home"},
+		},
+	}
+	rowErrors := []entity.ImportError{{Row: 3, Error: "Invalid task priority"}}
+
+	tests := []struct {
+		name         string
+		format       entity.ImportFormat
+		file         []byte
+		dryRun       bool
+		mockBehavior func(crepo *mock_cache.MockTaskList)
+		wantIDs      []int
+		wantErr      error
+	}{
+		{
+			name:   "Success",
+			format: entity.ImportCSV,
+			file:   file,
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				crepo.EXPECT().ImportTasks(1, imported).Return([]int{10, 11}, nil)
+			},
+			wantIDs: []int{10, 11},
+		},
+		{
+			name:         "Dry Run",
+			format:       entity.ImportCSV,
+			file:         file,
+			dryRun:       true,
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {},
+			wantIDs:      []int{0, 0},
+		},
+		{
+			name:         "Unreadable File",
+			format:       entity.ImportCSV,
+			file:         []byte("name\nBuy milk\n"),
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {},
+			wantErr:      entity.ErrImportFile,
+		},
+		{
+			name:         "Too Large",
+			format:       entity.ImportTrello,
+			file:         make([]byte, MaxImportSize+1),
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {},
+			wantErr:      entity.ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockTaskList(ctrl)
+			tt.mockBehavior(crepo)
+
+			s := NewTaskService(crepo)
+			s.now = func() time.Time { return now }
+
+			report, err := s.ImportTasks(1, tt.format, fileHeader(t, "export", tt.file), tt.dryRun)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dryRun, report.DryRun)
+			assert.Equal(t, rowErrors, report.Errors)
+
+			ids := make([]int, len(report.Tasks))
+			for i, item := range report.Tasks {
+				ids[i] = item.Task.ID
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
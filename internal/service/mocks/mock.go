@@ -5,7 +5,12 @@
 package mock_service
 
 import (
+	context "context"
+	io "io"
+	multipart "mime/multipart"
+	http "net/http"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/AronditFire/todo-app/entity"
 	service "github.com/AronditFire/todo-app/internal/service"
@@ -35,76 +40,1567 @@ func (m *MockTaskList) EXPECT() *MockTaskListMockRecorder {
 	return m.recorder
 }
 
+// Batch mocks base method.
+func (m *MockTaskList) Batch(userID int, req entity.BatchRequest) ([]entity.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", userID, req)
+	ret0, _ := ret[0].([]entity.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockTaskListMockRecorder) Batch(userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockTaskList)(nil).Batch), userID, req)
+}
+
+// CompleteTask mocks base method.
+func (m *MockTaskList) CompleteTask(userID, taskID int, withSubtasks bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTask", userID, taskID, withSubtasks)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTask indicates an expected call of CompleteTask.
+func (mr *MockTaskListMockRecorder) CompleteTask(userID, taskID, withSubtasks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockTaskList)(nil).CompleteTask), userID, taskID, withSubtasks)
+}
+
+// CreateCalendarTask mocks base method.
+func (m *MockTaskList) CreateCalendarTask(userID int, task entity.Task, object entity.CalendarObject) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendarTask", userID, task, object)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCalendarTask indicates an expected call of CreateCalendarTask.
+func (mr *MockTaskListMockRecorder) CreateCalendarTask(userID, task, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendarTask", reflect.TypeOf((*MockTaskList)(nil).CreateCalendarTask), userID, task, object)
+}
+
 // CreateTask mocks base method.
-func (m *MockTaskList) CreateTask(userID int, task entity.Task) error {
+func (m *MockTaskList) CreateTask(userID int, task entity.Task) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTask", userID, task)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTask indicates an expected call of CreateTask.
+func (mr *MockTaskListMockRecorder) CreateTask(userID, task interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockTaskList)(nil).CreateTask), userID, task)
+}
+
+// DeleteTask mocks base method.
+func (m *MockTaskList) DeleteTask(userID, taskID, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTask", userID, taskID, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTask indicates an expected call of DeleteTask.
+func (mr *MockTaskListMockRecorder) DeleteTask(userID, taskID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskList)(nil).DeleteTask), userID, taskID, version)
+}
+
+// ExportTasks mocks base method.
+func (m *MockTaskList) ExportTasks(userID int, format entity.ExportFormat, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTasks", userID, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportTasks indicates an expected call of ExportTasks.
+func (mr *MockTaskListMockRecorder) ExportTasks(userID, format, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTasks", reflect.TypeOf((*MockTaskList)(nil).ExportTasks), userID, format, w)
+}
+
+// GetAllTask mocks base method.
+func (m *MockTaskList) GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTask", userID, filter)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTask indicates an expected call of GetAllTask.
+func (mr *MockTaskListMockRecorder) GetAllTask(userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTask", reflect.TypeOf((*MockTaskList)(nil).GetAllTask), userID, filter)
+}
+
+// GetOccurrences mocks base method.
+func (m *MockTaskList) GetOccurrences(userID, taskID, count int) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOccurrences", userID, taskID, count)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOccurrences indicates an expected call of GetOccurrences.
+func (mr *MockTaskListMockRecorder) GetOccurrences(userID, taskID, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOccurrences", reflect.TypeOf((*MockTaskList)(nil).GetOccurrences), userID, taskID, count)
+}
+
+// GetOverdueTasks mocks base method.
+func (m *MockTaskList) GetOverdueTasks(userID int) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdueTasks", userID)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdueTasks indicates an expected call of GetOverdueTasks.
+func (mr *MockTaskListMockRecorder) GetOverdueTasks(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueTasks", reflect.TypeOf((*MockTaskList)(nil).GetOverdueTasks), userID)
+}
+
+// GetSubtasks mocks base method.
+func (m *MockTaskList) GetSubtasks(userID, taskID int) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubtasks", userID, taskID)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubtasks indicates an expected call of GetSubtasks.
+func (mr *MockTaskListMockRecorder) GetSubtasks(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubtasks", reflect.TypeOf((*MockTaskList)(nil).GetSubtasks), userID, taskID)
+}
+
+// GetTaskByID mocks base method.
+func (m *MockTaskList) GetTaskByID(userID, id int) (entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskByID", userID, id)
+	ret0, _ := ret[0].(entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskByID indicates an expected call of GetTaskByID.
+func (mr *MockTaskListMockRecorder) GetTaskByID(userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskByID", reflect.TypeOf((*MockTaskList)(nil).GetTaskByID), userID, id)
+}
+
+// GetTaskPage mocks base method.
+func (m *MockTaskList) GetTaskPage(userID int, filter entity.TaskFilter) (entity.TaskPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskPage", userID, filter)
+	ret0, _ := ret[0].(entity.TaskPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskPage indicates an expected call of GetTaskPage.
+func (mr *MockTaskListMockRecorder) GetTaskPage(userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskPage", reflect.TypeOf((*MockTaskList)(nil).GetTaskPage), userID, filter)
+}
+
+// GetTasksDueThisWeek mocks base method.
+func (m *MockTaskList) GetTasksDueThisWeek(userID int, loc *time.Location) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasksDueThisWeek", userID, loc)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTasksDueThisWeek indicates an expected call of GetTasksDueThisWeek.
+func (mr *MockTaskListMockRecorder) GetTasksDueThisWeek(userID, loc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasksDueThisWeek", reflect.TypeOf((*MockTaskList)(nil).GetTasksDueThisWeek), userID, loc)
+}
+
+// GetTasksDueToday mocks base method.
+func (m *MockTaskList) GetTasksDueToday(userID int, loc *time.Location) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasksDueToday", userID, loc)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTasksDueToday indicates an expected call of GetTasksDueToday.
+func (mr *MockTaskListMockRecorder) GetTasksDueToday(userID, loc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasksDueToday", reflect.TypeOf((*MockTaskList)(nil).GetTasksDueToday), userID, loc)
+}
+
+// ImportTasks mocks base method.
+func (m *MockTaskList) ImportTasks(userID int, format entity.ImportFormat, file *multipart.FileHeader, dryRun bool) (entity.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTasks", userID, format, file, dryRun)
+	ret0, _ := ret[0].(entity.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportTasks indicates an expected call of ImportTasks.
+func (mr *MockTaskListMockRecorder) ImportTasks(userID, format, file, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTasks", reflect.TypeOf((*MockTaskList)(nil).ImportTasks), userID, format, file, dryRun)
+}
+
+// MoveTask mocks base method.
+func (m *MockTaskList) MoveTask(userID, taskID int, req entity.TaskMoveRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTask", userID, taskID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveTask indicates an expected call of MoveTask.
+func (mr *MockTaskListMockRecorder) MoveTask(userID, taskID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTask", reflect.TypeOf((*MockTaskList)(nil).MoveTask), userID, taskID, req)
+}
+
+// PatchAndCompleteTask mocks base method.
+func (m *MockTaskList) PatchAndCompleteTask(userID, taskID int, patch entity.TaskPatch, version int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchAndCompleteTask", userID, taskID, patch, version)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchAndCompleteTask indicates an expected call of PatchAndCompleteTask.
+func (mr *MockTaskListMockRecorder) PatchAndCompleteTask(userID, taskID, patch, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchAndCompleteTask", reflect.TypeOf((*MockTaskList)(nil).PatchAndCompleteTask), userID, taskID, patch, version)
+}
+
+// PatchTask mocks base method.
+func (m *MockTaskList) PatchTask(userID, taskID int, patch entity.TaskPatch, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchTask", userID, taskID, patch, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchTask indicates an expected call of PatchTask.
+func (mr *MockTaskListMockRecorder) PatchTask(userID, taskID, patch, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTask", reflect.TypeOf((*MockTaskList)(nil).PatchTask), userID, taskID, patch, version)
+}
+
+// PruneChanges mocks base method.
+func (m *MockTaskList) PruneChanges() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneChanges")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneChanges indicates an expected call of PruneChanges.
+func (mr *MockTaskListMockRecorder) PruneChanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneChanges", reflect.TypeOf((*MockTaskList)(nil).PruneChanges))
+}
+
+// ReopenTask mocks base method.
+func (m *MockTaskList) ReopenTask(userID, taskID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenTask", userID, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReopenTask indicates an expected call of ReopenTask.
+func (mr *MockTaskListMockRecorder) ReopenTask(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenTask", reflect.TypeOf((*MockTaskList)(nil).ReopenTask), userID, taskID)
+}
+
+// Sync mocks base method.
+func (m *MockTaskList) Sync(userID int, since entity.SyncToken) (entity.SyncResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", userID, since)
+	ret0, _ := ret[0].(entity.SyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockTaskListMockRecorder) Sync(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockTaskList)(nil).Sync), userID, since)
+}
+
+// SyncPush mocks base method.
+func (m *MockTaskList) SyncPush(userID int, changes []entity.BatchOperation) ([]entity.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncPush", userID, changes)
+	ret0, _ := ret[0].([]entity.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncPush indicates an expected call of SyncPush.
+func (mr *MockTaskListMockRecorder) SyncPush(userID, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPush", reflect.TypeOf((*MockTaskList)(nil).SyncPush), userID, changes)
+}
+
+// UpdateTask mocks base method.
+func (m *MockTaskList) UpdateTask(userID, taskId int, desc string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTask", userID, taskId, desc, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTask indicates an expected call of UpdateTask.
+func (mr *MockTaskListMockRecorder) UpdateTask(userID, taskId, desc, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskList)(nil).UpdateTask), userID, taskId, desc, version)
+}
+
+// UpdateTaskDue mocks base method.
+func (m *MockTaskList) UpdateTaskDue(userID, taskID int, req entity.TaskDueRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskDue", userID, taskID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskDue indicates an expected call of UpdateTaskDue.
+func (mr *MockTaskListMockRecorder) UpdateTaskDue(userID, taskID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskDue", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskDue), userID, taskID, req)
+}
+
+// UpdateTaskParent mocks base method.
+func (m *MockTaskList) UpdateTaskParent(userID, taskID int, parentID *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskParent", userID, taskID, parentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskParent indicates an expected call of UpdateTaskParent.
+func (mr *MockTaskListMockRecorder) UpdateTaskParent(userID, taskID, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskParent", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskParent), userID, taskID, parentID)
+}
+
+// UpdateTaskPriority mocks base method.
+func (m *MockTaskList) UpdateTaskPriority(userID, taskID, priority int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskPriority", userID, taskID, priority)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskPriority indicates an expected call of UpdateTaskPriority.
+func (mr *MockTaskListMockRecorder) UpdateTaskPriority(userID, taskID, priority interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskPriority", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskPriority), userID, taskID, priority)
+}
+
+// UpdateTaskProject mocks base method.
+func (m *MockTaskList) UpdateTaskProject(userID, taskID int, projectID *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskProject", userID, taskID, projectID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskProject indicates an expected call of UpdateTaskProject.
+func (mr *MockTaskListMockRecorder) UpdateTaskProject(userID, taskID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskProject", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskProject), userID, taskID, projectID)
+}
+
+// UpdateTaskRecurrence mocks base method.
+func (m *MockTaskList) UpdateTaskRecurrence(userID, taskID int, rule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskRecurrence", userID, taskID, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskRecurrence indicates an expected call of UpdateTaskRecurrence.
+func (mr *MockTaskListMockRecorder) UpdateTaskRecurrence(userID, taskID, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskRecurrence", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskRecurrence), userID, taskID, rule)
+}
+
+// UpdateTaskStatus mocks base method.
+func (m *MockTaskList) UpdateTaskStatus(userID, taskID int, status entity.TaskStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskStatus", userID, taskID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskStatus indicates an expected call of UpdateTaskStatus.
+func (mr *MockTaskListMockRecorder) UpdateTaskStatus(userID, taskID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskStatus", reflect.TypeOf((*MockTaskList)(nil).UpdateTaskStatus), userID, taskID, status)
+}
+
+// MockTags is a mock of Tags interface.
+type MockTags struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagsMockRecorder
+}
+
+// MockTagsMockRecorder is the mock recorder for MockTags.
+type MockTagsMockRecorder struct {
+	mock *MockTags
+}
+
+// NewMockTags creates a new mock instance.
+func NewMockTags(ctrl *gomock.Controller) *MockTags {
+	mock := &MockTags{ctrl: ctrl}
+	mock.recorder = &MockTagsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTags) EXPECT() *MockTagsMockRecorder {
+	return m.recorder
+}
+
+// AttachTag mocks base method.
+func (m *MockTags) AttachTag(userID, taskID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTag", userID, taskID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTag indicates an expected call of AttachTag.
+func (mr *MockTagsMockRecorder) AttachTag(userID, taskID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTag", reflect.TypeOf((*MockTags)(nil).AttachTag), userID, taskID, tagID)
+}
+
+// CreateTag mocks base method.
+func (m *MockTags) CreateTag(userID int, tag entity.Tag) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", userID, tag)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTagsMockRecorder) CreateTag(userID, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTags)(nil).CreateTag), userID, tag)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTags) DeleteTag(userID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", userID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagsMockRecorder) DeleteTag(userID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTags)(nil).DeleteTag), userID, tagID)
+}
+
+// DetachTag mocks base method.
+func (m *MockTags) DetachTag(userID, taskID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTag", userID, taskID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachTag indicates an expected call of DetachTag.
+func (mr *MockTagsMockRecorder) DetachTag(userID, taskID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTag", reflect.TypeOf((*MockTags)(nil).DetachTag), userID, taskID, tagID)
+}
+
+// GetAllTags mocks base method.
+func (m *MockTags) GetAllTags(userID int) ([]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTags", userID)
+	ret0, _ := ret[0].([]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTags indicates an expected call of GetAllTags.
+func (mr *MockTagsMockRecorder) GetAllTags(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTags", reflect.TypeOf((*MockTags)(nil).GetAllTags), userID)
+}
+
+// GetTagByID mocks base method.
+func (m *MockTags) GetTagByID(userID, tagID int) (entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByID", userID, tagID)
+	ret0, _ := ret[0].(entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagByID indicates an expected call of GetTagByID.
+func (mr *MockTagsMockRecorder) GetTagByID(userID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByID", reflect.TypeOf((*MockTags)(nil).GetTagByID), userID, tagID)
+}
+
+// UpdateTag mocks base method.
+func (m *MockTags) UpdateTag(userID, tagID int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTag", userID, tagID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTag indicates an expected call of UpdateTag.
+func (mr *MockTagsMockRecorder) UpdateTag(userID, tagID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockTags)(nil).UpdateTag), userID, tagID, name)
+}
+
+// MockProjects is a mock of Projects interface.
+type MockProjects struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectsMockRecorder
+}
+
+// MockProjectsMockRecorder is the mock recorder for MockProjects.
+type MockProjectsMockRecorder struct {
+	mock *MockProjects
+}
+
+// NewMockProjects creates a new mock instance.
+func NewMockProjects(ctrl *gomock.Controller) *MockProjects {
+	mock := &MockProjects{ctrl: ctrl}
+	mock.recorder = &MockProjectsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProjects) EXPECT() *MockProjectsMockRecorder {
+	return m.recorder
+}
+
+// CreateProject mocks base method.
+func (m *MockProjects) CreateProject(userID int, project entity.Project) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProject", userID, project)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProject indicates an expected call of CreateProject.
+func (mr *MockProjectsMockRecorder) CreateProject(userID, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProject", reflect.TypeOf((*MockProjects)(nil).CreateProject), userID, project)
+}
+
+// DeleteProject mocks base method.
+func (m *MockProjects) DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProject", userID, projectID, mode, targetID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProject indicates an expected call of DeleteProject.
+func (mr *MockProjectsMockRecorder) DeleteProject(userID, projectID, mode, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProject", reflect.TypeOf((*MockProjects)(nil).DeleteProject), userID, projectID, mode, targetID)
+}
+
+// GetAllProjects mocks base method.
+func (m *MockProjects) GetAllProjects(userID int, withArchived bool) ([]entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllProjects", userID, withArchived)
+	ret0, _ := ret[0].([]entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllProjects indicates an expected call of GetAllProjects.
+func (mr *MockProjectsMockRecorder) GetAllProjects(userID, withArchived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProjects", reflect.TypeOf((*MockProjects)(nil).GetAllProjects), userID, withArchived)
+}
+
+// GetProjectByID mocks base method.
+func (m *MockProjects) GetProjectByID(userID, projectID int) (entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectByID", userID, projectID)
+	ret0, _ := ret[0].(entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectByID indicates an expected call of GetProjectByID.
+func (mr *MockProjectsMockRecorder) GetProjectByID(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectByID", reflect.TypeOf((*MockProjects)(nil).GetProjectByID), userID, projectID)
+}
+
+// UpdateProject mocks base method.
+func (m *MockProjects) UpdateProject(userID, projectID int, upd entity.ProjectRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProject", userID, projectID, upd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProject indicates an expected call of UpdateProject.
+func (mr *MockProjectsMockRecorder) UpdateProject(userID, projectID, upd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProject", reflect.TypeOf((*MockProjects)(nil).UpdateProject), userID, projectID, upd)
+}
+
+// MockTrash is a mock of Trash interface.
+type MockTrash struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashMockRecorder
+}
+
+// MockTrashMockRecorder is the mock recorder for MockTrash.
+type MockTrashMockRecorder struct {
+	mock *MockTrash
+}
+
+// NewMockTrash creates a new mock instance.
+func NewMockTrash(ctrl *gomock.Controller) *MockTrash {
+	mock := &MockTrash{ctrl: ctrl}
+	mock.recorder = &MockTrashMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrash) EXPECT() *MockTrashMockRecorder {
+	return m.recorder
+}
+
+// EmptyTrash mocks base method.
+func (m *MockTrash) EmptyTrash(userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockTrashMockRecorder) EmptyTrash(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockTrash)(nil).EmptyTrash), userID)
+}
+
+// GetTrash mocks base method.
+func (m *MockTrash) GetTrash(userID int) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", userID)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTrashMockRecorder) GetTrash(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTrash)(nil).GetTrash), userID)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockTrash) PurgeExpired() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockTrashMockRecorder) PurgeExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockTrash)(nil).PurgeExpired))
+}
+
+// PurgeTask mocks base method.
+func (m *MockTrash) PurgeTask(userID, taskID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTask", userID, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTask indicates an expected call of PurgeTask.
+func (mr *MockTrashMockRecorder) PurgeTask(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTask", reflect.TypeOf((*MockTrash)(nil).PurgeTask), userID, taskID)
+}
+
+// RestoreTask mocks base method.
+func (m *MockTrash) RestoreTask(userID, taskID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTask", userID, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTask indicates an expected call of RestoreTask.
+func (mr *MockTrashMockRecorder) RestoreTask(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTask", reflect.TypeOf((*MockTrash)(nil).RestoreTask), userID, taskID)
+}
+
+// MockHistory is a mock of History interface.
+type MockHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryMockRecorder
+}
+
+// MockHistoryMockRecorder is the mock recorder for MockHistory.
+type MockHistoryMockRecorder struct {
+	mock *MockHistory
+}
+
+// NewMockHistory creates a new mock instance.
+func NewMockHistory(ctrl *gomock.Controller) *MockHistory {
+	mock := &MockHistory{ctrl: ctrl}
+	mock.recorder = &MockHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistory) EXPECT() *MockHistoryMockRecorder {
+	return m.recorder
+}
+
+// GetTaskHistory mocks base method.
+func (m *MockHistory) GetTaskHistory(userID, taskID int) ([]entity.TaskHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskHistory", userID, taskID)
+	ret0, _ := ret[0].([]entity.TaskHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskHistory indicates an expected call of GetTaskHistory.
+func (mr *MockHistoryMockRecorder) GetTaskHistory(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskHistory", reflect.TypeOf((*MockHistory)(nil).GetTaskHistory), userID, taskID)
+}
+
+// MockSharing is a mock of Sharing interface.
+type MockSharing struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharingMockRecorder
+}
+
+// MockSharingMockRecorder is the mock recorder for MockSharing.
+type MockSharingMockRecorder struct {
+	mock *MockSharing
+}
+
+// NewMockSharing creates a new mock instance.
+func NewMockSharing(ctrl *gomock.Controller) *MockSharing {
+	mock := &MockSharing{ctrl: ctrl}
+	mock.recorder = &MockSharingMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharing) EXPECT() *MockSharingMockRecorder {
+	return m.recorder
+}
+
+// GetProjectShares mocks base method.
+func (m *MockSharing) GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectShares", userID, projectID)
+	ret0, _ := ret[0].([]entity.ProjectShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectShares indicates an expected call of GetProjectShares.
+func (mr *MockSharingMockRecorder) GetProjectShares(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectShares", reflect.TypeOf((*MockSharing)(nil).GetProjectShares), userID, projectID)
+}
+
+// GetTaskShares mocks base method.
+func (m *MockSharing) GetTaskShares(userID, taskID int) ([]entity.TaskShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskShares", userID, taskID)
+	ret0, _ := ret[0].([]entity.TaskShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskShares indicates an expected call of GetTaskShares.
+func (mr *MockSharingMockRecorder) GetTaskShares(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskShares", reflect.TypeOf((*MockSharing)(nil).GetTaskShares), userID, taskID)
+}
+
+// ProjectRole mocks base method.
+func (m *MockSharing) ProjectRole(userID, projectID int) (entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProjectRole", userID, projectID)
+	ret0, _ := ret[0].(entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProjectRole indicates an expected call of ProjectRole.
+func (mr *MockSharingMockRecorder) ProjectRole(userID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectRole", reflect.TypeOf((*MockSharing)(nil).ProjectRole), userID, projectID)
+}
+
+// ShareProject mocks base method.
+func (m *MockSharing) ShareProject(userID, projectID int, req entity.ShareRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareProject", userID, projectID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShareProject indicates an expected call of ShareProject.
+func (mr *MockSharingMockRecorder) ShareProject(userID, projectID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareProject", reflect.TypeOf((*MockSharing)(nil).ShareProject), userID, projectID, req)
+}
+
+// ShareTask mocks base method.
+func (m *MockSharing) ShareTask(userID, taskID int, req entity.ShareRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareTask", userID, taskID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShareTask indicates an expected call of ShareTask.
+func (mr *MockSharingMockRecorder) ShareTask(userID, taskID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareTask", reflect.TypeOf((*MockSharing)(nil).ShareTask), userID, taskID, req)
+}
+
+// TaskRole mocks base method.
+func (m *MockSharing) TaskRole(userID, taskID int) (entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskRole", userID, taskID)
+	ret0, _ := ret[0].(entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskRole indicates an expected call of TaskRole.
+func (mr *MockSharingMockRecorder) TaskRole(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskRole", reflect.TypeOf((*MockSharing)(nil).TaskRole), userID, taskID)
+}
+
+// UnshareProject mocks base method.
+func (m *MockSharing) UnshareProject(userID, projectID, granteeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnshareProject", userID, projectID, granteeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnshareProject indicates an expected call of UnshareProject.
+func (mr *MockSharingMockRecorder) UnshareProject(userID, projectID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnshareProject", reflect.TypeOf((*MockSharing)(nil).UnshareProject), userID, projectID, granteeID)
+}
+
+// UnshareTask mocks base method.
+func (m *MockSharing) UnshareTask(userID, taskID, granteeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnshareTask", userID, taskID, granteeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnshareTask indicates an expected call of UnshareTask.
+func (mr *MockSharingMockRecorder) UnshareTask(userID, taskID, granteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnshareTask", reflect.TypeOf((*MockSharing)(nil).UnshareTask), userID, taskID, granteeID)
+}
+
+// MockAssignments is a mock of Assignments interface.
+type MockAssignments struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssignmentsMockRecorder
+}
+
+// MockAssignmentsMockRecorder is the mock recorder for MockAssignments.
+type MockAssignmentsMockRecorder struct {
+	mock *MockAssignments
+}
+
+// NewMockAssignments creates a new mock instance.
+func NewMockAssignments(ctrl *gomock.Controller) *MockAssignments {
+	mock := &MockAssignments{ctrl: ctrl}
+	mock.recorder = &MockAssignmentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssignments) EXPECT() *MockAssignmentsMockRecorder {
+	return m.recorder
+}
+
+// GetAssignedTasks mocks base method.
+func (m *MockAssignments) GetAssignedTasks(userID int) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssignedTasks", userID)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssignedTasks indicates an expected call of GetAssignedTasks.
+func (mr *MockAssignmentsMockRecorder) GetAssignedTasks(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssignedTasks", reflect.TypeOf((*MockAssignments)(nil).GetAssignedTasks), userID)
+}
+
+// GetAssignees mocks base method.
+func (m *MockAssignments) GetAssignees(userID, taskID int) ([]entity.TaskAssignee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssignees", userID, taskID)
+	ret0, _ := ret[0].([]entity.TaskAssignee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssignees indicates an expected call of GetAssignees.
+func (mr *MockAssignmentsMockRecorder) GetAssignees(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssignees", reflect.TypeOf((*MockAssignments)(nil).GetAssignees), userID, taskID)
+}
+
+// SetAssignees mocks base method.
+func (m *MockAssignments) SetAssignees(userID, taskID int, userIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAssignees", userID, taskID, userIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAssignees indicates an expected call of SetAssignees.
+func (mr *MockAssignmentsMockRecorder) SetAssignees(userID, taskID, userIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAssignees", reflect.TypeOf((*MockAssignments)(nil).SetAssignees), userID, taskID, userIDs)
+}
+
+// MockComments is a mock of Comments interface.
+type MockComments struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentsMockRecorder
+}
+
+// MockCommentsMockRecorder is the mock recorder for MockComments.
+type MockCommentsMockRecorder struct {
+	mock *MockComments
+}
+
+// NewMockComments creates a new mock instance.
+func NewMockComments(ctrl *gomock.Controller) *MockComments {
+	mock := &MockComments{ctrl: ctrl}
+	mock.recorder = &MockCommentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComments) EXPECT() *MockCommentsMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockComments) CreateComment(userID, taskID int, body string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", userID, taskID, body)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockCommentsMockRecorder) CreateComment(userID, taskID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockComments)(nil).CreateComment), userID, taskID, body)
+}
+
+// DeleteComment mocks base method.
+func (m *MockComments) DeleteComment(userID, taskID, commentID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", userID, taskID, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentsMockRecorder) DeleteComment(userID, taskID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockComments)(nil).DeleteComment), userID, taskID, commentID)
+}
+
+// GetCommentPage mocks base method.
+func (m *MockComments) GetCommentPage(userID, taskID int, after *entity.CommentCursor, limit int) (entity.CommentPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentPage", userID, taskID, after, limit)
+	ret0, _ := ret[0].(entity.CommentPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentPage indicates an expected call of GetCommentPage.
+func (mr *MockCommentsMockRecorder) GetCommentPage(userID, taskID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentPage", reflect.TypeOf((*MockComments)(nil).GetCommentPage), userID, taskID, after, limit)
+}
+
+// UpdateComment mocks base method.
+func (m *MockComments) UpdateComment(userID, taskID, commentID int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", userID, taskID, commentID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentsMockRecorder) UpdateComment(userID, taskID, commentID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockComments)(nil).UpdateComment), userID, taskID, commentID, body)
+}
+
+// MockAttachments is a mock of Attachments interface.
+type MockAttachments struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentsMockRecorder
+}
+
+// MockAttachmentsMockRecorder is the mock recorder for MockAttachments.
+type MockAttachmentsMockRecorder struct {
+	mock *MockAttachments
+}
+
+// NewMockAttachments creates a new mock instance.
+func NewMockAttachments(ctrl *gomock.Controller) *MockAttachments {
+	mock := &MockAttachments{ctrl: ctrl}
+	mock.recorder = &MockAttachmentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachments) EXPECT() *MockAttachmentsMockRecorder {
+	return m.recorder
+}
+
+// CleanupBlobs mocks base method.
+func (m *MockAttachments) CleanupBlobs() (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTask", userID, task)
+	ret := m.ctrl.Call(m, "CleanupBlobs")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanupBlobs indicates an expected call of CleanupBlobs.
+func (mr *MockAttachmentsMockRecorder) CleanupBlobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupBlobs", reflect.TypeOf((*MockAttachments)(nil).CleanupBlobs))
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAttachments) DeleteAttachment(userID, taskID, attachmentID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", userID, taskID, attachmentID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateTask indicates an expected call of CreateTask.
-func (mr *MockTaskListMockRecorder) CreateTask(userID, task interface{}) *gomock.Call {
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAttachmentsMockRecorder) DeleteAttachment(userID, taskID, attachmentID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockTaskList)(nil).CreateTask), userID, task)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAttachments)(nil).DeleteAttachment), userID, taskID, attachmentID)
 }
 
-// DeleteTask mocks base method.
-func (m *MockTaskList) DeleteTask(userID, taskID int) error {
+// GetAttachments mocks base method.
+func (m *MockAttachments) GetAttachments(userID, taskID int) ([]entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachments", userID, taskID)
+	ret0, _ := ret[0].([]entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachments indicates an expected call of GetAttachments.
+func (mr *MockAttachmentsMockRecorder) GetAttachments(userID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachments", reflect.TypeOf((*MockAttachments)(nil).GetAttachments), userID, taskID)
+}
+
+// OpenAttachment mocks base method.
+func (m *MockAttachments) OpenAttachment(userID, taskID, attachmentID int) (entity.Attachment, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAttachment", userID, taskID, attachmentID)
+	ret0, _ := ret[0].(entity.Attachment)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenAttachment indicates an expected call of OpenAttachment.
+func (mr *MockAttachmentsMockRecorder) OpenAttachment(userID, taskID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAttachment", reflect.TypeOf((*MockAttachments)(nil).OpenAttachment), userID, taskID, attachmentID)
+}
+
+// UploadAttachment mocks base method.
+func (m *MockAttachments) UploadAttachment(userID, taskID int, file *multipart.FileHeader) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachment", userID, taskID, file)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachment indicates an expected call of UploadAttachment.
+func (mr *MockAttachmentsMockRecorder) UploadAttachment(userID, taskID, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockAttachments)(nil).UploadAttachment), userID, taskID, file)
+}
+
+// MockBlobCleaner is a mock of BlobCleaner interface.
+type MockBlobCleaner struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobCleanerMockRecorder
+}
+
+// MockBlobCleanerMockRecorder is the mock recorder for MockBlobCleaner.
+type MockBlobCleanerMockRecorder struct {
+	mock *MockBlobCleaner
+}
+
+// NewMockBlobCleaner creates a new mock instance.
+func NewMockBlobCleaner(ctrl *gomock.Controller) *MockBlobCleaner {
+	mock := &MockBlobCleaner{ctrl: ctrl}
+	mock.recorder = &MockBlobCleanerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobCleaner) EXPECT() *MockBlobCleanerMockRecorder {
+	return m.recorder
+}
+
+// CleanupBlobs mocks base method.
+func (m *MockBlobCleaner) CleanupBlobs() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanupBlobs")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanupBlobs indicates an expected call of CleanupBlobs.
+func (mr *MockBlobCleanerMockRecorder) CleanupBlobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupBlobs", reflect.TypeOf((*MockBlobCleaner)(nil).CleanupBlobs))
+}
+
+// MockSearch is a mock of Search interface.
+type MockSearch struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchMockRecorder
+}
+
+// MockSearchMockRecorder is the mock recorder for MockSearch.
+type MockSearchMockRecorder struct {
+	mock *MockSearch
+}
+
+// NewMockSearch creates a new mock instance.
+func NewMockSearch(ctrl *gomock.Controller) *MockSearch {
+	mock := &MockSearch{ctrl: ctrl}
+	mock.recorder = &MockSearchMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearch) EXPECT() *MockSearchMockRecorder {
+	return m.recorder
+}
+
+// SearchTasks mocks base method.
+func (m *MockSearch) SearchTasks(userID int, query string, limit int) ([]entity.TaskSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTasks", userID, query, limit)
+	ret0, _ := ret[0].([]entity.TaskSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTasks indicates an expected call of SearchTasks.
+func (mr *MockSearchMockRecorder) SearchTasks(userID, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTasks", reflect.TypeOf((*MockSearch)(nil).SearchTasks), userID, query, limit)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// HoldKey mocks base method.
+func (m *MockIdempotency) HoldKey(scope, key string) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HoldKey", scope, key)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// HoldKey indicates an expected call of HoldKey.
+func (mr *MockIdempotencyMockRecorder) HoldKey(scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HoldKey", reflect.TypeOf((*MockIdempotency)(nil).HoldKey), scope, key)
+}
+
+// ReserveKey mocks base method.
+func (m *MockIdempotency) ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveKey", scope, key, fingerprint)
+	ret0, _ := ret[0].(*entity.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveKey indicates an expected call of ReserveKey.
+func (mr *MockIdempotencyMockRecorder) ReserveKey(scope, key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveKey", reflect.TypeOf((*MockIdempotency)(nil).ReserveKey), scope, key, fingerprint)
+}
+
+// SaveResponse mocks base method.
+func (m *MockIdempotency) SaveResponse(scope, key string, resp entity.IdempotentResponse) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTask", userID, taskID)
+	ret := m.ctrl.Call(m, "SaveResponse", scope, key, resp)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTask indicates an expected call of DeleteTask.
-func (mr *MockTaskListMockRecorder) DeleteTask(userID, taskID interface{}) *gomock.Call {
+// SaveResponse indicates an expected call of SaveResponse.
+func (mr *MockIdempotencyMockRecorder) SaveResponse(scope, key, resp interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskList)(nil).DeleteTask), userID, taskID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResponse", reflect.TypeOf((*MockIdempotency)(nil).SaveResponse), scope, key, resp)
 }
 
-// GetAllTask mocks base method.
-func (m *MockTaskList) GetAllTask(userID int) ([]entity.Task, error) {
+// MockFeeds is a mock of Feeds interface.
+type MockFeeds struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedsMockRecorder
+}
+
+// MockFeedsMockRecorder is the mock recorder for MockFeeds.
+type MockFeedsMockRecorder struct {
+	mock *MockFeeds
+}
+
+// NewMockFeeds creates a new mock instance.
+func NewMockFeeds(ctrl *gomock.Controller) *MockFeeds {
+	mock := &MockFeeds{ctrl: ctrl}
+	mock.recorder = &MockFeedsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeeds) EXPECT() *MockFeedsMockRecorder {
+	return m.recorder
+}
+
+// CreateFeedToken mocks base method.
+func (m *MockFeeds) CreateFeedToken(userID int) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllTask", userID)
-	ret0, _ := ret[0].([]entity.Task)
+	ret := m.ctrl.Call(m, "CreateFeedToken", userID)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllTask indicates an expected call of GetAllTask.
-func (mr *MockTaskListMockRecorder) GetAllTask(userID interface{}) *gomock.Call {
+// CreateFeedToken indicates an expected call of CreateFeedToken.
+func (mr *MockFeedsMockRecorder) CreateFeedToken(userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTask", reflect.TypeOf((*MockTaskList)(nil).GetAllTask), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeedToken", reflect.TypeOf((*MockFeeds)(nil).CreateFeedToken), userID)
 }
 
-// GetTaskByID mocks base method.
-func (m *MockTaskList) GetTaskByID(userID, id int) (entity.Task, error) {
+// GetFeed mocks base method.
+func (m *MockFeeds) GetFeed(token string) (entity.Feed, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTaskByID", userID, id)
-	ret0, _ := ret[0].(entity.Task)
+	ret := m.ctrl.Call(m, "GetFeed", token)
+	ret0, _ := ret[0].(entity.Feed)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTaskByID indicates an expected call of GetTaskByID.
-func (mr *MockTaskListMockRecorder) GetTaskByID(userID, id interface{}) *gomock.Call {
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockFeedsMockRecorder) GetFeed(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskByID", reflect.TypeOf((*MockTaskList)(nil).GetTaskByID), userID, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockFeeds)(nil).GetFeed), token)
 }
 
-// UpdateTask mocks base method.
-func (m *MockTaskList) UpdateTask(userID, taskId int, desc string) error {
+// RevokeFeedToken mocks base method.
+func (m *MockFeeds) RevokeFeedToken(userID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTask", userID, taskId, desc)
+	ret := m.ctrl.Call(m, "RevokeFeedToken", userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateTask indicates an expected call of UpdateTask.
-func (mr *MockTaskListMockRecorder) UpdateTask(userID, taskId, desc interface{}) *gomock.Call {
+// RevokeFeedToken indicates an expected call of RevokeFeedToken.
+func (mr *MockFeedsMockRecorder) RevokeFeedToken(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFeedToken", reflect.TypeOf((*MockFeeds)(nil).RevokeFeedToken), userID)
+}
+
+// MockEvents is a mock of Events interface.
+type MockEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsMockRecorder
+}
+
+// MockEventsMockRecorder is the mock recorder for MockEvents.
+type MockEventsMockRecorder struct {
+	mock *MockEvents
+}
+
+// NewMockEvents creates a new mock instance.
+func NewMockEvents(ctrl *gomock.Controller) *MockEvents {
+	mock := &MockEvents{ctrl: ctrl}
+	mock.recorder = &MockEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEvents) EXPECT() *MockEventsMockRecorder {
+	return m.recorder
+}
+
+// StreamTasks mocks base method.
+func (m *MockEvents) StreamTasks(ctx context.Context, userID int, since entity.SyncToken, send func([]entity.TaskEvent, entity.SyncToken) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamTasks", ctx, userID, since, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamTasks indicates an expected call of StreamTasks.
+func (mr *MockEventsMockRecorder) StreamTasks(ctx, userID, since, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTasks", reflect.TypeOf((*MockEvents)(nil).StreamTasks), ctx, userID, since, send)
+}
+
+// MockCalDAV is a mock of CalDAV interface.
+type MockCalDAV struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalDAVMockRecorder
+}
+
+// MockCalDAVMockRecorder is the mock recorder for MockCalDAV.
+type MockCalDAVMockRecorder struct {
+	mock *MockCalDAV
+}
+
+// NewMockCalDAV creates a new mock instance.
+func NewMockCalDAV(ctrl *gomock.Controller) *MockCalDAV {
+	mock := &MockCalDAV{ctrl: ctrl}
+	mock.recorder = &MockCalDAVMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCalDAV) EXPECT() *MockCalDAVMockRecorder {
+	return m.recorder
+}
+
+// CalendarNameReserved mocks base method.
+func (m *MockCalDAV) CalendarNameReserved(name string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarNameReserved", name)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CalendarNameReserved indicates an expected call of CalendarNameReserved.
+func (mr *MockCalDAVMockRecorder) CalendarNameReserved(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarNameReserved", reflect.TypeOf((*MockCalDAV)(nil).CalendarNameReserved), name)
+}
+
+// CalendarUIDTaken mocks base method.
+func (m *MockCalDAV) CalendarUIDTaken(userID int, uid string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarUIDTaken", userID, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarUIDTaken indicates an expected call of CalendarUIDTaken.
+func (mr *MockCalDAVMockRecorder) CalendarUIDTaken(userID, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarUIDTaken", reflect.TypeOf((*MockCalDAV)(nil).CalendarUIDTaken), userID, uid)
+}
+
+// CheckAppPassword mocks base method.
+func (m *MockCalDAV) CheckAppPassword(username, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAppPassword", username, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckAppPassword indicates an expected call of CheckAppPassword.
+func (mr *MockCalDAVMockRecorder) CheckAppPassword(username, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskList)(nil).UpdateTask), userID, taskId, desc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAppPassword", reflect.TypeOf((*MockCalDAV)(nil).CheckAppPassword), username, password)
+}
+
+// CreateAppPassword mocks base method.
+func (m *MockCalDAV) CreateAppPassword(userID int, name string) (entity.AppPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAppPassword", userID, name)
+	ret0, _ := ret[0].(entity.AppPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAppPassword indicates an expected call of CreateAppPassword.
+func (mr *MockCalDAVMockRecorder) CreateAppPassword(userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppPassword", reflect.TypeOf((*MockCalDAV)(nil).CreateAppPassword), userID, name)
+}
+
+// DeleteAppPassword mocks base method.
+func (m *MockCalDAV) DeleteAppPassword(userID, passwordID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAppPassword", userID, passwordID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAppPassword indicates an expected call of DeleteAppPassword.
+func (mr *MockCalDAVMockRecorder) DeleteAppPassword(userID, passwordID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAppPassword", reflect.TypeOf((*MockCalDAV)(nil).DeleteAppPassword), userID, passwordID)
+}
+
+// FindCalendarObject mocks base method.
+func (m *MockCalDAV) FindCalendarObject(userID int, name string) (*entity.CalendarObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCalendarObject", userID, name)
+	ret0, _ := ret[0].(*entity.CalendarObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCalendarObject indicates an expected call of FindCalendarObject.
+func (mr *MockCalDAVMockRecorder) FindCalendarObject(userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCalendarObject", reflect.TypeOf((*MockCalDAV)(nil).FindCalendarObject), userID, name)
+}
+
+// GetAppPasswords mocks base method.
+func (m *MockCalDAV) GetAppPasswords(userID int) ([]entity.AppPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppPasswords", userID)
+	ret0, _ := ret[0].([]entity.AppPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppPasswords indicates an expected call of GetAppPasswords.
+func (mr *MockCalDAVMockRecorder) GetAppPasswords(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppPasswords", reflect.TypeOf((*MockCalDAV)(nil).GetAppPasswords), userID)
+}
+
+// GetCalendarObjects mocks base method.
+func (m *MockCalDAV) GetCalendarObjects(userID int) (map[int]entity.CalendarObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendarObjects", userID)
+	ret0, _ := ret[0].(map[int]entity.CalendarObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendarObjects indicates an expected call of GetCalendarObjects.
+func (mr *MockCalDAVMockRecorder) GetCalendarObjects(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendarObjects", reflect.TypeOf((*MockCalDAV)(nil).GetCalendarObjects), userID)
+}
+
+// GetRemovedCalendarObjects mocks base method.
+func (m *MockCalDAV) GetRemovedCalendarObjects(userID int, since entity.SyncToken) ([]entity.CalendarObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRemovedCalendarObjects", userID, since)
+	ret0, _ := ret[0].([]entity.CalendarObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRemovedCalendarObjects indicates an expected call of GetRemovedCalendarObjects.
+func (mr *MockCalDAVMockRecorder) GetRemovedCalendarObjects(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemovedCalendarObjects", reflect.TypeOf((*MockCalDAV)(nil).GetRemovedCalendarObjects), userID, since)
 }
 
 // MockAuthorization is a mock of Authorization interface.
@@ -144,6 +1640,21 @@ func (mr *MockAuthorizationMockRecorder) CreateUser(userReg interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthorization)(nil).CreateUser), userReg)
 }
 
+// GetClientGoogle mocks base method.
+func (m *MockAuthorization) GetClientGoogle(code string) (*http.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientGoogle", code)
+	ret0, _ := ret[0].(*http.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientGoogle indicates an expected call of GetClientGoogle.
+func (mr *MockAuthorizationMockRecorder) GetClientGoogle(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientGoogle", reflect.TypeOf((*MockAuthorization)(nil).GetClientGoogle), code)
+}
+
 // GetUser mocks base method.
 func (m *MockAuthorization) GetUser(username string) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -174,6 +1685,20 @@ func (mr *MockAuthorizationMockRecorder) GetUserByID(id interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockAuthorization)(nil).GetUserByID), id)
 }
 
+// GoogleLogin mocks base method.
+func (m *MockAuthorization) GoogleLogin() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GoogleLogin")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GoogleLogin indicates an expected call of GoogleLogin.
+func (mr *MockAuthorizationMockRecorder) GoogleLogin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoogleLogin", reflect.TypeOf((*MockAuthorization)(nil).GoogleLogin))
+}
+
 // LoginUser mocks base method.
 func (m *MockAuthorization) LoginUser(userLogin entity.UserAuthRequest) (string, string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+var projectColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type ProjectService struct {
+	crepo cache.Projects
+}
+
+func NewProjectService(crepo cache.Projects) *ProjectService {
+	return &ProjectService{crepo: crepo}
+}
+
+func (s *ProjectService) CreateProject(userID int, project entity.Project) (int, error) {
+	project.Name = strings.TrimSpace(project.Name)
+	if err := validProject(project.Name, project.Color); err != nil {
+		return 0, err
+	}
+
+	return s.crepo.CreateProject(userID, project)
+}
+
+func (s *ProjectService) GetAllProjects(userID int, withArchived bool) ([]entity.Project, error) {
+	return s.crepo.GetAllProjects(userID, withArchived)
+}
+
+func (s *ProjectService) GetProjectByID(userID, projectID int) (entity.Project, error) {
+	if projectID <= 0 {
+		return entity.Project{}, errors.New("Invalid id while trying to get project by ID")
+	}
+
+	return s.crepo.GetProjectByID(userID, projectID)
+}
+
+func (s *ProjectService) UpdateProject(userID, projectID int, upd entity.ProjectRequest) error {
+	if projectID <= 0 {
+		return errors.New("Invalid id while trying to update project")
+	}
+
+	upd.Name = strings.TrimSpace(upd.Name)
+	if err := validProject(upd.Name, upd.Color); err != nil {
+		return err
+	}
+
+	return s.crepo.UpdateProject(userID, projectID, upd)
+}
+
+func (s *ProjectService) DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error {
+	if projectID <= 0 {
+		return errors.New("Invalid id while trying to delete project")
+	}
+	if !mode.IsValid() {
+		return errors.New("Invalid project delete mode")
+	}
+	if mode == entity.ProjectDeleteCascade && targetID != 0 {
+		return errors.New("Target project can be set only in move mode")
+	}
+	if targetID < 0 || targetID == projectID {
+		return errors.New("Invalid target project")
+	}
+
+	return s.crepo.DeleteProject(userID, projectID, mode, targetID)
+}
+
+func validProject(name, color string) error {
+	if len(name) == 0 || len(name) > 100 {
+		return errors.New("Invalid project name length")
+	}
+	// пустой цвет допустим, клиент подставит свой
+	if color != "" && !projectColorRe.MatchString(color) {
+		return errors.New("Invalid project color")
+	}
+
+	return nil
+}
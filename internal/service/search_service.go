@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+const maxSearchResults = 100
+
+// SearchService goes straight to the repository: the Redis cache holds
+// task lists by filter and can not answer full-text queries.
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) SearchTasks(userID int, query string, limit int) ([]entity.TaskSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > 200 {
+		return nil, entity.ErrSearchQuery
+	}
+	if limit <= 0 || limit > maxSearchResults {
+		return nil, errors.New("Invalid search limit")
+	}
+
+	return s.repo.SearchTasks(userID, query, limit)
+}
@@ -1,21 +1,152 @@
 package service
 
 import (
+	"context"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/AronditFire/todo-app/entity"
 	"github.com/AronditFire/todo-app/internal/cache"
 	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/AronditFire/todo-app/internal/storage"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type TaskList interface {
 	CreateTask(userID int, task entity.Task) (int, error)
-	GetAllTask(userID int) ([]entity.Task, error)
+	CreateCalendarTask(userID int, task entity.Task, object entity.CalendarObject) (int, error)
+	GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error)
+	GetTaskPage(userID int, filter entity.TaskFilter) (entity.TaskPage, error)
 	GetTaskByID(userID, id int) (entity.Task, error)
-	UpdateTask(userID, taskId int, desc string) error
-	DeleteTask(userID, taskID int) error
+	UpdateTask(userID, taskId int, desc string, version int) error
+	PatchTask(userID, taskID int, patch entity.TaskPatch, version int) error
+	UpdateTaskStatus(userID, taskID int, status entity.TaskStatus) error
+	CompleteTask(userID, taskID int, withSubtasks bool) (int, error)
+	PatchAndCompleteTask(userID, taskID int, patch entity.TaskPatch, version int) (int, error)
+	ReopenTask(userID, taskID int) error
+	UpdateTaskDue(userID, taskID int, req entity.TaskDueRequest) error
+	UpdateTaskPriority(userID, taskID, priority int) error
+	MoveTask(userID, taskID int, req entity.TaskMoveRequest) error
+	UpdateTaskProject(userID, taskID int, projectID *int) error
+	UpdateTaskParent(userID, taskID int, parentID *int) error
+	GetSubtasks(userID, taskID int) ([]entity.Task, error)
+	UpdateTaskRecurrence(userID, taskID int, rule string) error
+	GetOccurrences(userID, taskID, count int) ([]time.Time, error)
+	GetOverdueTasks(userID int) ([]entity.Task, error)
+	GetTasksDueToday(userID int, loc *time.Location) ([]entity.Task, error)
+	GetTasksDueThisWeek(userID int, loc *time.Location) ([]entity.Task, error)
+	DeleteTask(userID, taskID, version int) error
+	Batch(userID int, req entity.BatchRequest) ([]entity.BatchResult, error)
+	ImportTasks(userID int, format entity.ImportFormat, file *multipart.FileHeader, dryRun bool) (entity.ImportReport, error)
+	ExportTasks(userID int, format entity.ExportFormat, w io.Writer) error
+	Sync(userID int, since entity.SyncToken) (entity.SyncResponse, error)
+	SyncPush(userID int, changes []entity.BatchOperation) ([]entity.SyncResult, error)
+	PruneChanges() (int64, error)
+}
+
+type Tags interface {
+	CreateTag(userID int, tag entity.Tag) (int, error)
+	GetAllTags(userID int) ([]entity.Tag, error)
+	GetTagByID(userID, tagID int) (entity.Tag, error)
+	UpdateTag(userID, tagID int, name string) error
+	DeleteTag(userID, tagID int) error
+	AttachTag(userID, taskID, tagID int) error
+	DetachTag(userID, taskID, tagID int) error
+}
+
+type Projects interface {
+	CreateProject(userID int, project entity.Project) (int, error)
+	GetAllProjects(userID int, withArchived bool) ([]entity.Project, error)
+	GetProjectByID(userID, projectID int) (entity.Project, error)
+	UpdateProject(userID, projectID int, upd entity.ProjectRequest) error
+	DeleteProject(userID, projectID int, mode entity.ProjectDeleteMode, targetID int) error
+}
+
+type Trash interface {
+	GetTrash(userID int) ([]entity.Task, error)
+	RestoreTask(userID, taskID int) error
+	PurgeTask(userID, taskID int) error
+	EmptyTrash(userID int) error
+	PurgeExpired() (int64, error)
+}
+
+type History interface {
+	GetTaskHistory(userID, taskID int) ([]entity.TaskHistory, error)
+}
+
+type Sharing interface {
+	TaskRole(userID, taskID int) (entity.Role, error)
+	ProjectRole(userID, projectID int) (entity.Role, error)
+	GetTaskShares(userID, taskID int) ([]entity.TaskShare, error)
+	ShareTask(userID, taskID int, req entity.ShareRequest) error
+	UnshareTask(userID, taskID, granteeID int) error
+	GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error)
+	ShareProject(userID, projectID int, req entity.ShareRequest) error
+	UnshareProject(userID, projectID, granteeID int) error
+}
+
+type Assignments interface {
+	GetAssignees(userID, taskID int) ([]entity.TaskAssignee, error)
+	SetAssignees(userID, taskID int, userIDs []int) error
+	GetAssignedTasks(userID int) ([]entity.Task, error)
+}
+
+type Comments interface {
+	GetCommentPage(userID, taskID int, after *entity.CommentCursor, limit int) (entity.CommentPage, error)
+	CreateComment(userID, taskID int, body string) (int, error)
+	UpdateComment(userID, taskID, commentID int, body string) error
+	DeleteComment(userID, taskID, commentID int) error
+}
+
+type Attachments interface {
+	UploadAttachment(userID, taskID int, file *multipart.FileHeader) (int, error)
+	GetAttachments(userID, taskID int) ([]entity.Attachment, error)
+	OpenAttachment(userID, taskID, attachmentID int) (entity.Attachment, io.ReadCloser, error)
+	DeleteAttachment(userID, taskID, attachmentID int) error
+	BlobCleaner
+}
+
+// BlobCleaner removes blobs of deleted attachments from the storage.
+type BlobCleaner interface {
+	CleanupBlobs() (int, error)
+}
+
+type Search interface {
+	SearchTasks(userID int, query string, limit int) ([]entity.TaskSearchResult, error)
+}
+
+// Idempotency replays responses of POST requests retried with the same Idempotency-Key.
+type Idempotency interface {
+	ReserveKey(scope, key, fingerprint string) (*entity.IdempotentResponse, error)
+	HoldKey(scope, key string) (stop func())
+	SaveResponse(scope, key string, resp entity.IdempotentResponse) error
+}
+
+// Feeds serves the calendar feed of tasks with due dates.
+type Feeds interface {
+	CreateFeedToken(userID int) (string, error)
+	RevokeFeedToken(userID int) error
+	GetFeed(token string) (entity.Feed, error)
+}
+
+type Events interface {
+	StreamTasks(ctx context.Context, userID int, since entity.SyncToken, send func(events []entity.TaskEvent, token entity.SyncToken) error) error
+}
+
+// CalDAV keeps app passwords and resource names of CalDAV clients.
+type CalDAV interface {
+	CreateAppPassword(userID int, name string) (entity.AppPasswordResponse, error)
+	GetAppPasswords(userID int) ([]entity.AppPassword, error)
+	DeleteAppPassword(userID, passwordID int) error
+	CheckAppPassword(username, password string) (int, error)
+	GetCalendarObjects(userID int) (map[int]entity.CalendarObject, error)
+	FindCalendarObject(userID int, name string) (*entity.CalendarObject, error)
+	CalendarNameReserved(name string) bool
+	CalendarUIDTaken(userID int, uid string) (bool, error)
+	GetRemovedCalendarObjects(userID int, since entity.SyncToken) ([]entity.CalendarObject, error)
 }
 
 type Authorization interface {
@@ -37,13 +168,41 @@ type ParsingJSON interface {
 
 type Service struct {
 	TaskList
+	Tags
+	Projects
+	Trash
+	History
+	Sharing
+	Assignments
+	Comments
+	Attachments
+	Search
+	Idempotency
+	Feeds
+	Events
+	CalDAV
 	Authorization
 	ParsingJSON
 }
 
-func NewService(crepo *cache.RedisRepository, repo *repository.Repository, id, secret, rURL string) *Service {
+func NewService(crepo *cache.RedisRepository, repo *repository.Repository, store storage.Storage, id, secret, rURL string, trashRetention time.Duration) *Service {
+	attachments := NewAttachmentService(repo.Attachments, store)
+
 	return &Service{
 		TaskList:      NewTaskService(crepo.TaskList),
+		Tags:          NewTagService(crepo.Tags),
+		Projects:      NewProjectService(crepo.Projects),
+		Trash:         NewTrashService(crepo.Trash, attachments, trashRetention),
+		History:       NewHistoryService(repo.History),
+		Sharing:       NewShareService(crepo.Sharing, repo.Authorization),
+		Assignments:   NewAssignmentService(repo.Assignments),
+		Comments:      NewCommentService(crepo.Comments),
+		Attachments:   attachments,
+		Search:        NewSearchService(repo.Search),
+		Idempotency:   NewIdempotencyService(crepo.Idempotency),
+		Feeds:         NewFeedService(crepo.Feeds, crepo.TaskList),
+		Events:        NewEventService(crepo.Events, crepo.TaskList),
+		CalDAV:        NewCalDAVService(repo.CalDAV),
 		Authorization: NewAuthService(repo.Authorization, id, secret, rURL),
 		ParsingJSON:   NewParseService(repo.ParsingJSON),
 	}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+	"github.com/AronditFire/todo-app/internal/repository"
+)
+
+// ShareService grants access by username, the grantee is looked up
+// in the users table.
+type ShareService struct {
+	crepo cache.Sharing
+	users repository.Authorization
+}
+
+func NewShareService(crepo cache.Sharing, users repository.Authorization) *ShareService {
+	return &ShareService{crepo: crepo, users: users}
+}
+
+// TaskRole is the role of the user for the task, "" when the user can not see it.
+func (s *ShareService) TaskRole(userID, taskID int) (entity.Role, error) {
+	if taskID <= 0 {
+		return "", errors.New("Invalid id while trying to get task role")
+	}
+
+	return s.crepo.TaskRole(userID, taskID)
+}
+
+// ProjectRole is the role of the user for the project, "" when the user can not see it.
+func (s *ShareService) ProjectRole(userID, projectID int) (entity.Role, error) {
+	if projectID <= 0 {
+		return "", errors.New("Invalid id while trying to get project role")
+	}
+
+	return s.crepo.ProjectRole(userID, projectID)
+}
+
+func (s *ShareService) GetTaskShares(userID, taskID int) ([]entity.TaskShare, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get task shares")
+	}
+
+	return s.crepo.GetTaskShares(userID, taskID)
+}
+
+func (s *ShareService) ShareTask(userID, taskID int, req entity.ShareRequest) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to share task")
+	}
+
+	granteeID, err := s.grantee(userID, req)
+	if err != nil {
+		return err
+	}
+
+	return s.crepo.ShareTask(userID, taskID, granteeID, req.Role)
+}
+
+func (s *ShareService) UnshareTask(userID, taskID, granteeID int) error {
+	if taskID <= 0 || granteeID <= 0 {
+		return errors.New("Invalid id while trying to unshare task")
+	}
+
+	return s.crepo.UnshareTask(userID, taskID, granteeID)
+}
+
+func (s *ShareService) GetProjectShares(userID, projectID int) ([]entity.ProjectShare, error) {
+	if projectID <= 0 {
+		return nil, errors.New("Invalid id while trying to get project shares")
+	}
+
+	return s.crepo.GetProjectShares(userID, projectID)
+}
+
+func (s *ShareService) ShareProject(userID, projectID int, req entity.ShareRequest) error {
+	if projectID <= 0 {
+		return errors.New("Invalid id while trying to share project")
+	}
+
+	granteeID, err := s.grantee(userID, req)
+	if err != nil {
+		return err
+	}
+
+	return s.crepo.ShareProject(userID, projectID, granteeID, req.Role)
+}
+
+func (s *ShareService) UnshareProject(userID, projectID, granteeID int) error {
+	if projectID <= 0 || granteeID <= 0 {
+		return errors.New("Invalid id while trying to unshare project")
+	}
+
+	return s.crepo.UnshareProject(userID, projectID, granteeID)
+}
+
+// grantee validates the request and returns id of the user to share with.
+func (s *ShareService) grantee(userID int, req entity.ShareRequest) (int, error) {
+	if !req.Role.IsValid() {
+		return 0, errors.New("Invalid role")
+	}
+
+	user, err := s.users.GetUser(strings.TrimSpace(req.Username))
+	if err != nil {
+		return 0, fmt.Errorf("Could not find user to share with: %w", err)
+	}
+
+	if user.ID == userID {
+		return 0, errors.New("Can not share with yourself")
+	}
+
+	return user.ID, nil
+}
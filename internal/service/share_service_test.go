@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	mock_repository "github.com/AronditFire/todo-app/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     entity.ShareRequest
+		mock    func(crepo *mock_cache.MockSharing, users *mock_repository.MockAuthorization)
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			req:  entity.ShareRequest{Username: " bob ", Role: entity.RoleEditor},
+			mock: func(crepo *mock_cache.MockSharing, users *mock_repository.MockAuthorization) {
+				users.EXPECT().GetUser("bob").Return(entity.User{ID: 2, Username: "bob"}, nil)
+				crepo.EXPECT().ShareTask(1, 5, 2, entity.RoleEditor).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid Role",
+			req:     entity.ShareRequest{Username: "bob", Role: "admin"},
+			mock:    func(*mock_cache.MockSharing, *mock_repository.MockAuthorization) {},
+			wantErr: true,
+		},
+		{
+			name: "Unknown User",
+			req:  entity.ShareRequest{Username: "nobody", Role: entity.RoleViewer},
+			mock: func(crepo *mock_cache.MockSharing, users *mock_repository.MockAuthorization) {
+				users.EXPECT().GetUser("nobody").Return(entity.User{}, errors.New("record not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "With Yourself",
+			req:  entity.ShareRequest{Username: "me", Role: entity.RoleViewer},
+			mock: func(crepo *mock_cache.MockSharing, users *mock_repository.MockAuthorization) {
+				users.EXPECT().GetUser("me").Return(entity.User{ID: 1, Username: "me"}, nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockSharing(ctrl)
+			users := mock_repository.NewMockAuthorization(ctrl)
+			tt.mock(crepo, users)
+
+			err := NewShareService(crepo, users).ShareTask(1, 5, tt.req)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+)
+
+// ChangeRetention is how long the change log keeps a change. A client that
+// has not synced for longer gets 410 and syncs from scratch.
+const ChangeRetention = 30 * 24 * time.Hour
+
+// Sync returns the tasks changed for the user since the token and the token
+// for the next call. A zero token returns every task, like the first sync
+// of a new device.
+func (s *TaskService) Sync(userID int, since entity.SyncToken) (entity.SyncResponse, error) {
+	if since < 0 {
+		return entity.SyncResponse{}, errors.New("Invalid sync token")
+	}
+
+	changes, err := s.crepo.GetChanges(userID, since)
+	if err != nil {
+		return entity.SyncResponse{}, err
+	}
+
+	resp := entity.SyncResponse{
+		Tasks:   changes.Tasks,
+		Created: changes.Created,
+		Deleted: changes.Deleted,
+		Token:   changes.Token.Encode(),
+	}
+	// клиенту проще с пустыми массивами, чем с null
+	if resp.Tasks == nil {
+		resp.Tasks = []entity.Task{}
+	}
+	if resp.Created == nil {
+		resp.Created = []int{}
+	}
+	if resp.Deleted == nil {
+		resp.Deleted = []int{}
+	}
+
+	return resp, nil
+}
+
+// PruneChanges drops changes older than ChangeRetention from the log.
+func (s *TaskService) PruneChanges() (int64, error) {
+	return s.crepo.PruneChanges(s.now().Add(-ChangeRetention))
+}
+
+// RunChangePrune prunes the change log right away and then every interval
+// until ctx is cancelled. Errors are only logged, the next run tries again.
+func RunChangePrune(ctx context.Context, tasks TaskList, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := tasks.PruneChanges()
+		if err != nil {
+			log.Printf("Could not prune change log: %v", err)
+		} else if pruned > 0 {
+			log.Printf("Pruned %d changes from the change log", pruned)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncPush applies the changes a client made offline as a best effort batch:
+// every change that applies is kept. A change made against an older version
+// of a task is a conflict and comes back with the server's copy of the task.
+func (s *TaskService) SyncPush(userID int, changes []entity.BatchOperation) ([]entity.SyncResult, error) {
+	results, errs, err := s.batch(userID, entity.BatchRequest{Mode: entity.BatchBestEffort, Operations: changes})
+	if err != nil {
+		return nil, err
+	}
+
+	synced := make([]entity.SyncResult, len(results))
+	for i, result := range results {
+		synced[i] = entity.SyncResult{BatchResult: result}
+		if !errors.Is(errs[i], entity.ErrVersionMismatch) {
+			continue
+		}
+
+		synced[i].Status = entity.SyncConflict
+		// задача могла уйти в корзину после конфликта, об этом клиент узнает из следующего Sync
+		if task, err := s.GetTaskByID(userID, changes[i].ID); err == nil {
+			synced[i].Task = &task
+		}
+	}
+
+	return synced, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().GetChanges(1, entity.SyncToken(100)).Return(entity.TaskChanges{
+		Tasks:   []entity.Task{{ID: 2}},
+		Created: []int{2},
+		Token:   120,
+	}, nil)
+	crepo.EXPECT().GetChanges(1, entity.SyncToken(120)).Return(entity.TaskChanges{Token: 120}, nil)
+
+	s := NewTaskService(crepo)
+
+	resp, err := s.Sync(1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.SyncResponse{Tasks: []entity.Task{{ID: 2}}, Created: []int{2}, Deleted: []int{}, Token: entity.SyncToken(120).Encode()}, resp)
+
+	// токен возвращается клиенту как есть и принимается обратно
+	next, err := entity.DecodeSyncToken(resp.Token)
+	assert.NoError(t, err)
+	resp, err = s.Sync(1, next)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Task{}, resp.Tasks)
+	assert.Equal(t, []int{}, resp.Created)
+}
+
+func TestPruneChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().PruneChanges(time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)).Return(int64(4), nil)
+
+	s := NewTaskService(crepo)
+	s.now = func() time.Time { return now }
+
+	pruned, err := s.PruneChanges()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), pruned)
+}
+
+func TestSyncPush(t *testing.T) {
+	changes := []entity.BatchOperation{
+		{Op: entity.BatchUpdate, ID: 2, Version: 3, Patch: json.RawMessage(`{"priority":1}`)},
+		{Op: entity.BatchDelete, ID: 3, Version: 1},
+		{Op: entity.BatchComplete, ID: 4, Version: 5},
+		{Op: entity.BatchUpdate, ID: 5, Patch: json.RawMessage(`{"colour":"red"}`)},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().Batch(1, gomock.Len(3), false).Return([]repository.TaskOpResult{
+		{Err: entity.ErrVersionMismatch},
+		{Err: entity.ErrVersionMismatch},
+		{ID: 10},
+	}, nil)
+	crepo.EXPECT().GetTaskByID(1, 2).Return(entity.Task{ID: 2, Version: 4}, nil)
+	crepo.EXPECT().GetAllTask(1, entity.TaskFilter{ParentID: 2}).Return(nil, nil)
+	// задача 3 ушла в корзину после конфликта
+	crepo.EXPECT().GetTaskByID(1, 3).Return(entity.Task{}, errors.New("record not found"))
+
+	s := NewTaskService(crepo)
+
+	results, err := s.SyncPush(1, changes)
+
+	assert.NoError(t, err)
+	want := []entity.BatchStatus{entity.SyncConflict, entity.SyncConflict, entity.BatchOK, entity.BatchFailed}
+	for i, res := range results {
+		assert.Equal(t, want[i], res.Status, "change %d", i)
+	}
+	assert.Equal(t, &entity.Task{ID: 2, Version: 4, Progress: results[0].Task.Progress}, results[0].Task)
+	assert.Nil(t, results[1].Task)
+	assert.Equal(t, 10, results[2].ID)
+	assert.Equal(t, "Invalid task fields", results[3].Error)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+type TagService struct {
+	crepo cache.Tags
+}
+
+func NewTagService(crepo cache.Tags) *TagService {
+	return &TagService{crepo: crepo}
+}
+
+func (s *TagService) CreateTag(userID int, tag entity.Tag) (int, error) {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if !validTagName(tag.Name) {
+		return 0, errors.New("Invalid tag name length")
+	}
+
+	return s.crepo.CreateTag(userID, tag)
+}
+
+func (s *TagService) GetAllTags(userID int) ([]entity.Tag, error) {
+	return s.crepo.GetAllTags(userID)
+}
+
+func (s *TagService) GetTagByID(userID, tagID int) (entity.Tag, error) {
+	if tagID <= 0 {
+		return entity.Tag{}, errors.New("Invalid id while trying to get tag by ID")
+	}
+
+	return s.crepo.GetTagByID(userID, tagID)
+}
+
+func (s *TagService) UpdateTag(userID, tagID int, name string) error {
+	name = strings.TrimSpace(name)
+	if !validTagName(name) {
+		return errors.New("Invalid tag name length")
+	}
+
+	return s.crepo.UpdateTag(userID, tagID, name)
+}
+
+func (s *TagService) DeleteTag(userID, tagID int) error {
+	if tagID <= 0 {
+		return errors.New("Invalid id while trying to delete tag")
+	}
+
+	return s.crepo.DeleteTag(userID, tagID)
+}
+
+func (s *TagService) AttachTag(userID, taskID, tagID int) error {
+	if taskID <= 0 || tagID <= 0 {
+		return errors.New("Invalid task or tag id")
+	}
+
+	return s.crepo.AttachTag(userID, taskID, tagID)
+}
+
+func (s *TagService) DetachTag(userID, taskID, tagID int) error {
+	if taskID <= 0 || tagID <= 0 {
+		return errors.New("Invalid task or tag id")
+	}
+
+	return s.crepo.DetachTag(userID, taskID, tagID)
+}
+
+func validTagName(name string) bool {
+	return len(name) > 0 && len(name) <= 50
+}
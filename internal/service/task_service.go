@@ -2,52 +2,686 @@ package service
 
 import (
 	"errors"
+	"time"
 
 	"github.com/AronditFire/todo-app/entity"
 	"github.com/AronditFire/todo-app/internal/cache"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/AronditFire/todo-app/internal/rrule"
+)
+
+// maxOccurrences limits the recurrence preview, maxPageSize limits GetTaskPage.
+const (
+	maxOccurrences = 50
+	maxPageSize    = 200
 )
 
 type TaskService struct {
 	crepo cache.TaskList
+	now   func() time.Time // подменяется в тестах
 }
 
 func NewTaskService(crepo cache.TaskList) *TaskService {
-	return &TaskService{crepo: crepo}
+	return &TaskService{
+		crepo: crepo,
+		now:   time.Now,
+	}
 }
 
 func (s *TaskService) CreateTask(userID int, task entity.Task) (int, error) {
-	if len(task.Description) > 0 && len(task.Description) < 1000 {
-		return s.crepo.CreateTask(userID, task)
-	} else {
-		return 0, errors.New("Invalid description length!")
+	task, err := s.newTask(task)
+	if err != nil {
+		return 0, err
 	}
+
+	return s.crepo.CreateTask(userID, task)
 }
 
-func (s *TaskService) GetAllTask(userID int) ([]entity.Task, error) {
-	return s.crepo.GetAllTask(userID)
+// CreateCalendarTask creates a task a CalDAV client put under its own name
+// and UID. The task and the name are written in one transaction.
+func (s *TaskService) CreateCalendarTask(userID int, task entity.Task, object entity.CalendarObject) (int, error) {
+	if object.Name == "" || len(object.Name) > 255 || object.UID == "" || len(object.UID) > 255 {
+		return 0, errors.New("Invalid calendar object")
+	}
+
+	task, err := s.newTask(task)
+	if err != nil {
+		return 0, err
+	}
+	object.UserID = userID
+
+	return s.runOps(userID, repository.TaskOp{Op: entity.BatchCreate, Task: task, Object: &object})
+}
+
+// newTask validates a task that is about to be created and fills in the defaults.
+func (s *TaskService) newTask(task entity.Task) (entity.Task, error) {
+	if len(task.Description) == 0 || len(task.Description) >= 1000 {
+		return entity.Task{}, errors.New("Invalid description length!")
+	}
+
+	if task.Status == "" {
+		task.Status = entity.StatusTodo
+	}
+	if !task.Status.IsValid() {
+		return entity.Task{}, errors.New("Invalid task status")
+	}
+
+	task.CompletedAt = nil
+	if task.Status == entity.StatusDone {
+		now := s.now()
+		task.CompletedAt = &now
+	}
+
+	dueAt, remindAt, err := normalizeDue(task.DueAt, task.RemindAt)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	task.DueAt, task.RemindAt = dueAt, remindAt
+
+	if task.Priority == 0 {
+		task.Priority = entity.PriorityLowest
+	}
+	if !validPriority(task.Priority) {
+		return entity.Task{}, errors.New("Invalid task priority")
+	}
+	task.ID = 0              // id выдаёт база
+	task.CreatedAt = s.now() // одно и то же время и в базе, и в кеше
+	task.Position = 0        // позицию выдаёт repository
+	task.Tags = nil          // теги привязываются через /api/:id/tags
+	if task.ProjectID != nil && *task.ProjectID <= 0 {
+		return entity.Task{}, errors.New("Invalid project id")
+	}
+	if task.ParentID != nil && *task.ParentID <= 0 {
+		return entity.Task{}, errors.New("Invalid parent task id")
+	}
+	if task.Recurrence != "" {
+		rule, err := normalizeRecurrence(task.Recurrence, task.DueAt)
+		if err != nil {
+			return entity.Task{}, err
+		}
+		task.Recurrence = rule
+	}
+
+	return task, nil
+}
+
+func (s *TaskService) GetAllTask(userID int, filter entity.TaskFilter) ([]entity.Task, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, errors.New("Invalid task status in filter")
+	}
+	if !filter.Order.IsValid() {
+		return nil, errors.New("Invalid task order")
+	}
+	if !filter.TagMatch.IsValid() {
+		return nil, errors.New("Invalid tag match mode")
+	}
+
+	return s.crepo.GetAllTask(userID, filter)
+}
+
+// GetTaskPage returns up to filter.Limit tasks starting after filter.After
+// and the cursor of the next page.
+func (s *TaskService) GetTaskPage(userID int, filter entity.TaskFilter) (entity.TaskPage, error) {
+	if filter.Limit <= 0 || filter.Limit > maxPageSize {
+		return entity.TaskPage{}, errors.New("Invalid page size")
+	}
+	if filter.After != nil && !filter.After.Matches(filter.Order, filter.Desc) {
+		return entity.TaskPage{}, errors.New("Cursor does not match sort order")
+	}
+
+	// одна лишняя задача показывает, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	tasks, err := s.GetAllTask(userID, filter)
+	if err != nil {
+		return entity.TaskPage{}, err
+	}
+
+	page := entity.TaskPage{Tasks: tasks}
+	if len(tasks) > limit {
+		page.Tasks = tasks[:limit]
+		page.NextCursor = entity.NewTaskCursor(tasks[limit-1], filter.Order, filter.Desc).Encode()
+	}
+
+	return page, nil
 }
 
 func (s *TaskService) GetTaskByID(userID, id int) (entity.Task, error) {
-	if id > 0 {
-		return s.crepo.GetTaskByID(userID, id)
-	} else {
+	if id <= 0 {
 		return entity.Task{}, errors.New("Invalid id while trying to get task by ID")
 	}
+
+	task, err := s.crepo.GetTaskByID(userID, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	subtasks, err := s.GetSubtasks(userID, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	task.Progress = SubtaskProgress(subtasks)
+
+	return task, nil
 }
 
-func (s *TaskService) UpdateTask(userID, taskId int, desc string) error {
+// UpdateTask changes the description, a non zero version must be the current one.
+func (s *TaskService) UpdateTask(userID, taskId int, desc string, version int) error {
 	if (len(desc) > 0) && (len(desc) < 1000) {
-		return s.crepo.UpdateTask(userID, taskId, desc)
+		return s.crepo.UpdateTask(userID, taskId, desc, version)
 	} else {
 		return errors.New("Invalid description length to update!")
 	}
 
 }
 
-func (s *TaskService) DeleteTask(userID, taskID int) error {
+// PatchTask applies a merge patch to the task. The patch is checked against
+// the row locked by the repository, so fields missing from the patch are seen
+// as they are at the moment of writing. All invalid fields are reported at
+// once as entity.FieldErrors.
+func (s *TaskService) PatchTask(userID, taskID int, patch entity.TaskPatch, version int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to patch task")
+	}
+
+	return s.crepo.PatchTask(userID, taskID, version, func(task *entity.Task) error {
+		return s.applyPatch(task, patch)
+	})
+}
+
+// applyPatch changes the task in place, the rules are the same as in the
+// single field updates.
+func (s *TaskService) applyPatch(task *entity.Task, p entity.TaskPatch) error {
+	problems := entity.FieldErrors{}
+
+	if p.Description.Set {
+		switch desc := p.Description.Value; {
+		case desc == nil:
+			problems["description"] = "must not be null"
+		case len(*desc) == 0 || len(*desc) >= 1000:
+			problems["description"] = "must be 1 to 999 bytes long"
+		default:
+			task.Description = *desc
+		}
+	}
+
+	if p.Priority.Set {
+		switch priority := p.Priority.Value; {
+		case priority == nil:
+			problems["priority"] = "must not be null"
+		case !validPriority(*priority):
+			problems["priority"] = "must be from 1 to 4"
+		default:
+			task.Priority = *priority
+		}
+	}
+
+	// существование проекта и родителя проверяет repository
+	if p.ProjectID.Set {
+		if id := p.ProjectID.Value; id != nil && *id <= 0 {
+			problems["project_id"] = "must be a positive id"
+		} else {
+			task.ProjectID = id
+		}
+	}
+	if p.ParentID.Set {
+		if id := p.ParentID.Value; id != nil && (*id <= 0 || *id == task.ID) {
+			problems["parent_id"] = "must be an id of another task"
+		} else {
+			task.ParentID = id
+		}
+	}
+
+	// срок и напоминание проверяются вместе: в ошибке виновато то из них, что есть в патче
+	if p.DueAt.Set || p.RemindAt.Set {
+		if p.DueAt.Set {
+			task.DueAt = p.DueAt.Value
+		}
+		if p.RemindAt.Set {
+			task.RemindAt = p.RemindAt.Value
+		}
+
+		blame := "due_at"
+		if p.RemindAt.Set {
+			blame = "remind_at"
+		}
+
+		dueAt, remindAt, err := normalizeDue(task.DueAt, task.RemindAt)
+		switch {
+		case errors.Is(err, entity.ErrInvalidDue):
+			problems["due_at"] = "must be between years 1970 and 9999"
+		case errors.Is(err, entity.ErrReminderNoDue):
+			problems[blame] = "reminder requires a due date"
+		case errors.Is(err, entity.ErrReminderAfterDue):
+			problems[blame] = "reminder must not be later than due date"
+		default:
+			task.DueAt, task.RemindAt = dueAt, remindAt
+		}
+	}
+
+	if p.Recurrence.Set {
+		rule := ""
+		if p.Recurrence.Value != nil {
+			rule = *p.Recurrence.Value
+		}
+
+		if rule != "" {
+			if task.DueAt == nil {
+				problems["recurrence"] = "recurring task requires a due date"
+			} else if parsed, err := rrule.Parse(rule); err != nil {
+				problems["recurrence"] = "invalid rule: " + err.Error()
+			} else {
+				rule = parsed.String()
+			}
+		}
+		task.Recurrence = rule
+	} else if task.Recurrence != "" && task.DueAt == nil {
+		problems["due_at"] = "recurring task requires a due date"
+	}
+
+	if p.Status.Set {
+		switch status := p.Status.Value; {
+		case status == nil:
+			problems["status"] = "must not be null"
+		case !status.IsValid():
+			problems["status"] = "must be one of todo, in_progress, done, cancelled"
+		case *status == task.Status:
+			// статус тот же, время выполнения не трогаем
+		case *status == entity.StatusDone && task.Recurrence != "":
+			// следующее повторение создаёт только CompleteTask
+			problems["status"] = "recurring task is completed with POST /api/{id}/complete"
+		default:
+			task.Status = *status
+			task.CompletedAt = nil
+			if task.Status == entity.StatusDone {
+				now := s.now()
+				task.CompletedAt = &now
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+func (s *TaskService) UpdateTaskStatus(userID, taskID int, status entity.TaskStatus) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task status")
+	}
+	if !status.IsValid() {
+		return errors.New("Invalid task status")
+	}
+
+	// выполнение повторяющейся задачи порождает следующую
+	if status == entity.StatusDone {
+		_, err := s.CompleteTask(userID, taskID, false)
+		return err
+	}
+
+	return s.crepo.UpdateTaskStatus(userID, taskID, status, nil)
+}
+
+// CompleteTask marks the task as done. For a recurring task the next
+// occurrence is created, its id is returned (0 when there is none). The
+// subtasks and the next occurrence are written in one transaction with the
+// task row locked, so two clients completing the task at once create one
+// next occurrence.
+func (s *TaskService) CompleteTask(userID, taskID int, withSubtasks bool) (int, error) {
+	if taskID <= 0 {
+		return 0, errors.New("Invalid id while trying to complete task")
+	}
+
+	return s.runOps(userID, repository.TaskOp{
+		Op:          entity.BatchComplete,
+		TaskID:      taskID,
+		Next:        s.nextOccurrence,
+		CompletedAt: s.now(),
+		Subtasks:    withSubtasks,
+	})
+}
+
+// PatchAndCompleteTask applies the patch and completes the task like
+// CompleteTask in one transaction, so a patch is never kept without the
+// next occurrence. Returns the id of the next occurrence, 0 when there is none.
+func (s *TaskService) PatchAndCompleteTask(userID, taskID int, patch entity.TaskPatch, version int) (int, error) {
+	if taskID <= 0 {
+		return 0, errors.New("Invalid id while trying to complete task")
+	}
+
+	return s.runOps(userID,
+		repository.TaskOp{Op: entity.BatchUpdate, TaskID: taskID, Version: version, Apply: func(task *entity.Task) error {
+			return s.applyPatch(task, patch)
+		}},
+		repository.TaskOp{Op: entity.BatchComplete, TaskID: taskID, Next: s.nextOccurrence, CompletedAt: s.now()},
+	)
+}
+
+// runOps runs the operations as one atomic batch and returns the error of
+// the one that failed or the id of the last one.
+func (s *TaskService) runOps(userID int, ops ...repository.TaskOp) (int, error) {
+	results, err := s.crepo.Batch(userID, ops, true)
+	if err != nil {
+		return 0, err
+	}
+
+	id := 0
+	for _, res := range results {
+		if res.Err != nil {
+			return 0, res.Err
+		}
+		id = res.ID
+	}
+	return id, nil
+}
+
+func (s *TaskService) ReopenTask(userID, taskID int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to reopen task")
+	}
+
+	task, err := s.crepo.GetTaskByID(userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if !task.Status.IsClosed() {
+		return errors.New("Only done or cancelled task can be reopened")
+	}
+
+	return s.UpdateTaskStatus(userID, taskID, entity.StatusTodo)
+}
+
+func (s *TaskService) UpdateTaskDue(userID, taskID int, req entity.TaskDueRequest) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task due date")
+	}
+
+	dueAt, remindAt, err := normalizeDue(req.DueAt, req.RemindAt)
+	if err != nil {
+		return err
+	}
+
+	if dueAt == nil {
+		task, err := s.crepo.GetTaskByID(userID, taskID)
+		if err != nil {
+			return err
+		}
+		if task.Recurrence != "" {
+			return entity.ErrRecurrenceNoDue
+		}
+	}
+
+	return s.crepo.UpdateTaskDue(userID, taskID, dueAt, remindAt)
+}
+
+func (s *TaskService) UpdateTaskPriority(userID, taskID, priority int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task priority")
+	}
+	if !validPriority(priority) {
+		return errors.New("Invalid task priority")
+	}
+
+	return s.crepo.UpdateTaskPriority(userID, taskID, priority)
+}
+
+func (s *TaskService) MoveTask(userID, taskID int, req entity.TaskMoveRequest) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to move task")
+	}
+	if (req.BeforeID > 0) == (req.AfterID > 0) {
+		return errors.New("Exactly one of before_id and after_id must be set")
+	}
+
+	anchorID, after := req.BeforeID, false
+	if req.AfterID > 0 {
+		anchorID, after = req.AfterID, true
+	}
+	if anchorID == taskID {
+		return errors.New("Task can not be moved relative to itself")
+	}
+
+	return s.crepo.MoveTask(userID, taskID, anchorID, after)
+}
+
+func (s *TaskService) UpdateTaskProject(userID, taskID int, projectID *int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task project")
+	}
+	if projectID != nil && *projectID <= 0 {
+		return errors.New("Invalid project id")
+	}
+
+	return s.crepo.UpdateTaskProject(userID, taskID, projectID)
+}
+
+func (s *TaskService) UpdateTaskParent(userID, taskID int, parentID *int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task parent")
+	}
+	if parentID != nil && (*parentID <= 0 || *parentID == taskID) {
+		return errors.New("Invalid parent task id")
+	}
+
+	return s.crepo.UpdateTaskParent(userID, taskID, parentID)
+}
+
+func (s *TaskService) GetSubtasks(userID, taskID int) ([]entity.Task, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get subtasks")
+	}
+
+	return s.crepo.GetAllTask(userID, entity.TaskFilter{ParentID: taskID})
+}
+
+// UpdateTaskRecurrence sets the RRULE of the task, empty rule stops the recurrence.
+func (s *TaskService) UpdateTaskRecurrence(userID, taskID int, rule string) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to update task recurrence")
+	}
+
+	if rule != "" {
+		task, err := s.crepo.GetTaskByID(userID, taskID)
+		if err != nil {
+			return err
+		}
+
+		rule, err = normalizeRecurrence(rule, task.DueAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.crepo.UpdateTaskRecurrence(userID, taskID, rule)
+}
+
+// GetOccurrences previews up to count occurrences that will follow the current one.
+func (s *TaskService) GetOccurrences(userID, taskID, count int) ([]time.Time, error) {
+	if taskID <= 0 {
+		return nil, errors.New("Invalid id while trying to get task occurrences")
+	}
+	if count <= 0 || count > maxOccurrences {
+		return nil, errors.New("Invalid occurrences count")
+	}
+
+	task, err := s.crepo.GetTaskByID(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == "" || task.DueAt == nil {
+		return nil, errors.New("Task is not recurring")
+	}
+
+	rule, err := rrule.Parse(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.Occurrences(*task.DueAt, s.occurrenceAfter(*task.DueAt), count), nil
+}
+
+func (s *TaskService) GetOverdueTasks(userID int) ([]entity.Task, error) {
+	return s.crepo.GetAllTask(userID, entity.TaskFilter{
+		DueTo:    s.now().UTC(),
+		OnlyOpen: true,
+	})
+}
+
+func (s *TaskService) GetTasksDueToday(userID int, loc *time.Location) ([]entity.Task, error) {
+	from := startOfDay(s.now(), loc)
+	return s.crepo.GetAllTask(userID, entity.TaskFilter{
+		DueFrom: from.UTC(),
+		DueTo:   from.AddDate(0, 0, 1).UTC(),
+	})
+}
+
+func (s *TaskService) GetTasksDueThisWeek(userID int, loc *time.Location) ([]entity.Task, error) {
+	from := startOfWeek(s.now(), loc)
+	return s.crepo.GetAllTask(userID, entity.TaskFilter{
+		DueFrom: from.UTC(),
+		DueTo:   from.AddDate(0, 0, 7).UTC(),
+	})
+}
+
+func (s *TaskService) DeleteTask(userID, taskID, version int) error {
 	if taskID > 0 {
-		return s.crepo.DeleteTask(userID, taskID)
+		return s.crepo.DeleteTask(userID, taskID, version)
 	} else {
 		return errors.New("Invalid id while trying to delete task")
 	}
 }
+
+// nextOccurrence builds the task that follows the given occurrence of a
+// recurring task, nil when the series is over. Occurrences missed while
+// the task was overdue are skipped.
+func (s *TaskService) nextOccurrence(task entity.Task) (*entity.Task, error) {
+	if task.DueAt == nil {
+		return nil, entity.ErrRecurrenceNoDue
+	}
+
+	rule, err := rrule.Parse(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	dueAt, rest, ok := rule.Advance(*task.DueAt, s.occurrenceAfter(*task.DueAt))
+	if !ok {
+		return nil, nil
+	}
+
+	next := &entity.Task{
+		Description: task.Description,
+		Status:      entity.StatusTodo,
+		DueAt:       &dueAt,
+		Priority:    task.Priority,
+		ProjectID:   task.ProjectID,
+		ParentID:    task.ParentID,
+		Recurrence:  rest.String(),
+		CreatedAt:   s.now(),
+	}
+	if task.RemindAt != nil {
+		remindAt := dueAt.Add(task.RemindAt.Sub(*task.DueAt))
+		next.RemindAt = &remindAt
+	}
+
+	return next, nil
+}
+
+// occurrenceAfter is the moment after which the next occurrence is looked for:
+// the current due date or now, whichever is later.
+func (s *TaskService) occurrenceAfter(dueAt time.Time) time.Time {
+	if now := s.now(); now.After(dueAt) {
+		return now
+	}
+
+	return dueAt
+}
+
+// normalizeRecurrence validates the rule against the due date it is counted
+// from and returns its canonical form.
+func normalizeRecurrence(raw string, dueAt *time.Time) (string, error) {
+	rule, err := rrule.Parse(raw)
+	if err != nil {
+		return "", errors.New("Invalid recurrence rule: " + err.Error())
+	}
+	if dueAt == nil {
+		return "", entity.ErrRecurrenceNoDue
+	}
+
+	return rule.String(), nil
+}
+
+// SubtaskProgress returns the share of done subtasks in percent, nil when
+// there are no subtasks. Cancelled subtasks are not counted at all.
+func SubtaskProgress(subtasks []entity.Task) *int {
+	if len(subtasks) == 0 {
+		return nil
+	}
+
+	total, done := 0, 0
+	for _, t := range subtasks {
+		switch t.Status {
+		case entity.StatusCancelled:
+			continue
+		case entity.StatusDone:
+			done++
+		}
+		total++
+	}
+
+	progress := 100
+	if total > 0 {
+		progress = done * 100 / total
+	}
+
+	return &progress
+}
+
+func validPriority(priority int) bool {
+	return priority >= entity.PriorityHighest && priority <= entity.PriorityLowest
+}
+
+// normalizeDue проверяет срок и напоминание и приводит их к UTC. По его
+// ошибкам PatchTask понимает, к какому полю относится ошибка.
+// Смещение из запроса учитывается при конвертации, поэтому
+// 18:00+03:00 и 15:00Z считаются одним и тем же моментом.
+func normalizeDue(dueAt, remindAt *time.Time) (*time.Time, *time.Time, error) {
+	if remindAt != nil && dueAt == nil {
+		return nil, nil, entity.ErrReminderNoDue
+	}
+
+	if dueAt != nil {
+		if dueAt.Year() < 1970 || dueAt.Year() > 9999 {
+			return nil, nil, entity.ErrInvalidDue
+		}
+		utc := dueAt.UTC()
+		dueAt = &utc
+	}
+
+	if remindAt != nil {
+		utc := remindAt.UTC()
+		if utc.After(*dueAt) {
+			return nil, nil, entity.ErrReminderAfterDue
+		}
+		remindAt = &utc
+	}
+
+	return dueAt, remindAt, nil
+}
+
+// startOfDay возвращает полночь дня t в часовом поясе loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// startOfWeek возвращает полночь понедельника недели, в которую попадает t (ISO 8601).
+func startOfWeek(t time.Time, loc *time.Location) time.Time {
+	day := startOfDay(t, loc)
+	offset := (int(day.Weekday()) + 6) % 7 // понедельник = 0
+	return day.AddDate(0, 0, -offset)
+}
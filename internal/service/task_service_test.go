@@ -0,0 +1,562 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/AronditFire/todo-app/internal/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDue(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	dueAt := time.Date(2025, 5, 1, 18, 0, 0, 0, msk)
+	remindAt := time.Date(2025, 5, 1, 17, 0, 0, 0, msk)
+	lateRemind := time.Date(2025, 5, 1, 16, 0, 0, 0, time.UTC) // 19:00 MSK
+	ancient := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		dueAt         *time.Time
+		remindAt      *time.Time
+		wantDueAt     *time.Time
+		expectedError string
+	}{
+		{
+			name:      "Converted To UTC",
+			dueAt:     &dueAt,
+			remindAt:  &remindAt,
+			wantDueAt: func() *time.Time { t := time.Date(2025, 5, 1, 15, 0, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			name: "Both Empty",
+		},
+		{
+			name:          "Reminder Without Due Date",
+			remindAt:      &remindAt,
+			expectedError: "Reminder requires a due date",
+		},
+		{
+			name:          "Reminder After Due Date In Other Zone",
+			dueAt:         &dueAt,
+			remindAt:      &lateRemind,
+			expectedError: "Reminder must not be later than due date",
+		},
+		{
+			name:          "Zero Due Date",
+			dueAt:         &ancient,
+			expectedError: "Invalid due date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDue, _, err := normalizeDue(tt.dueAt, tt.remindAt)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDueAt, gotDue)
+		})
+	}
+}
+
+func TestDueWindows(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// четверг 1 мая 2025, 22:30 UTC = пятница 2 мая 01:30 MSK
+	now := time.Date(2025, 5, 1, 22, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), startOfDay(now, time.UTC))
+	assert.Equal(t, time.Date(2025, 5, 2, 0, 0, 0, 0, msk), startOfDay(now, msk))
+
+	assert.Equal(t, time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC), startOfWeek(now, time.UTC))
+	assert.Equal(t, time.Date(2025, 4, 28, 0, 0, 0, 0, msk), startOfWeek(now, msk))
+
+	sunday := time.Date(2025, 5, 4, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC), startOfWeek(sunday, time.UTC))
+}
+
+func TestSubtaskProgress(t *testing.T) {
+	progress := func(p int) *int { return &p }
+
+	tests := []struct {
+		name     string
+		statuses []entity.TaskStatus
+		want     *int
+	}{
+		{
+			name: "No Subtasks",
+		},
+		{
+			name:     "Half Done",
+			statuses: []entity.TaskStatus{entity.StatusDone, entity.StatusTodo, entity.StatusInProgress, entity.StatusDone},
+			want:     progress(50),
+		},
+		{
+			name:     "Cancelled Are Skipped",
+			statuses: []entity.TaskStatus{entity.StatusDone, entity.StatusCancelled, entity.StatusTodo},
+			want:     progress(50),
+		},
+		{
+			name:     "Rounded Down",
+			statuses: []entity.TaskStatus{entity.StatusDone, entity.StatusTodo, entity.StatusTodo},
+			want:     progress(33),
+		},
+		{
+			name:     "All Cancelled",
+			statuses: []entity.TaskStatus{entity.StatusCancelled},
+			want:     progress(100),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subtasks []entity.Task
+			for _, status := range tt.statuses {
+				subtasks = append(subtasks, entity.Task{Status: status})
+			}
+
+			assert.Equal(t, tt.want, SubtaskProgress(subtasks))
+		})
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().Batch(1, gomock.Any(), true).DoAndReturn(func(_ int, ops []repository.TaskOp, _ bool) ([]repository.TaskOpResult, error) {
+		// следующее повторение строится в транзакции по заблокированной строке
+		assert.Len(t, ops, 1)
+		assert.Equal(t, repository.TaskOp{Op: entity.BatchComplete, TaskID: 10, CompletedAt: now, Subtasks: true}, repository.TaskOp{
+			Op: ops[0].Op, TaskID: ops[0].TaskID, CompletedAt: ops[0].CompletedAt, Subtasks: ops[0].Subtasks,
+		})
+		assert.NotNil(t, ops[0].Next)
+		return []repository.TaskOpResult{{ID: 11}}, nil
+	})
+	crepo.EXPECT().Batch(1, gomock.Any(), true).Return([]repository.TaskOpResult{{Err: errors.New("unsupported frequency SOMETIMES")}}, nil)
+
+	s := NewTaskService(crepo)
+	s.now = func() time.Time { return now }
+
+	nextID, err := s.CompleteTask(1, 10, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, nextID)
+
+	_, err = s.CompleteTask(1, 10, false)
+	assert.EqualError(t, err, "unsupported frequency SOMETIMES")
+
+	_, err = s.CompleteTask(1, 0, false)
+	assert.Error(t, err)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	// 1 мая 2025 - четверг, выполняем с опозданием во вторник 6 мая
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	remindAt := dueAt.Add(-time.Hour)
+	nextDue := time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC)
+	nextRemind := nextDue.Add(-time.Hour)
+	projectID := 3
+
+	tests := []struct {
+		name          string
+		task          entity.Task
+		want          *entity.Task
+		expectedError string
+	}{
+		{
+			// 5 мая пропущено, в серии остаются 8 и 12 мая
+			name: "Next Occurrence",
+			task: entity.Task{
+				ID:          10,
+				Description: "Gym",
+				Status:      entity.StatusTodo,
+				DueAt:       &dueAt,
+				RemindAt:    &remindAt,
+				Priority:    2,
+				ProjectID:   &projectID,
+				Recurrence:  "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4",
+			},
+			want: &entity.Task{
+				Description: "Gym",
+				Status:      entity.StatusTodo,
+				DueAt:       &nextDue,
+				RemindAt:    &nextRemind,
+				Priority:    2,
+				ProjectID:   &projectID,
+				Recurrence:  "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=2",
+				CreatedAt:   now,
+			},
+		},
+		{
+			name: "Series Is Over",
+			task: entity.Task{
+				ID:         10,
+				DueAt:      &dueAt,
+				Recurrence: "FREQ=DAILY;UNTIL=20250505",
+			},
+		},
+		{
+			name: "Broken Rule",
+			task: entity.Task{
+				ID:         10,
+				DueAt:      &dueAt,
+				Recurrence: "FREQ=SOMETIMES",
+			},
+			expectedError: "unsupported frequency SOMETIMES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTaskService(nil)
+			s.now = func() time.Time { return now }
+
+			next, err := s.nextOccurrence(tt.task)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestPatchAndCompleteTask(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	desc := "Gym"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().Batch(1, gomock.Any(), true).DoAndReturn(func(_ int, ops []repository.TaskOp, _ bool) ([]repository.TaskOpResult, error) {
+		// правка и выполнение - один атомарный пакет
+		assert.Len(t, ops, 2)
+		assert.Equal(t, entity.BatchUpdate, ops[0].Op)
+		assert.Equal(t, 2, ops[0].Version)
+		assert.Equal(t, repository.TaskOp{Op: entity.BatchComplete, TaskID: 10, CompletedAt: now}, repository.TaskOp{
+			Op: ops[1].Op, TaskID: ops[1].TaskID, CompletedAt: ops[1].CompletedAt,
+		})
+		assert.NotNil(t, ops[1].Next)
+
+		task := entity.Task{ID: 10}
+		assert.NoError(t, ops[0].Apply(&task))
+		assert.Equal(t, "Gym", task.Description)
+
+		return []repository.TaskOpResult{{}, {ID: 11}}, nil
+	})
+	crepo.EXPECT().Batch(1, gomock.Any(), true).Return([]repository.TaskOpResult{{Err: entity.ErrVersionMismatch}}, nil)
+
+	s := NewTaskService(crepo)
+	s.now = func() time.Time { return now }
+
+	patch := entity.TaskPatch{Description: entity.PatchField[string]{Set: true, Value: &desc}}
+
+	nextID, err := s.PatchAndCompleteTask(1, 10, patch, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, nextID)
+
+	_, err = s.PatchAndCompleteTask(1, 10, patch, 1)
+	assert.ErrorIs(t, err, entity.ErrVersionMismatch)
+}
+
+func TestCreateCalendarTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().Batch(1, gomock.Any(), true).DoAndReturn(func(_ int, ops []repository.TaskOp, _ bool) ([]repository.TaskOpResult, error) {
+		assert.Len(t, ops, 1)
+		assert.Equal(t, entity.BatchCreate, ops[0].Op)
+		assert.Equal(t, entity.StatusTodo, ops[0].Task.Status)
+		assert.Equal(t, &entity.CalendarObject{UserID: 1, Name: "abc.ics", UID: "abc"}, ops[0].Object)
+		return []repository.TaskOpResult{{ID: 9}}, nil
+	})
+
+	s := NewTaskService(crepo)
+
+	id, err := s.CreateCalendarTask(1, entity.Task{Description: "Buy milk"}, entity.CalendarObject{Name: "abc.ics", UID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+
+	_, err = s.CreateCalendarTask(1, entity.Task{Description: "Buy milk"}, entity.CalendarObject{Name: "abc.ics"})
+	assert.Error(t, err)
+}
+
+func TestGetOccurrences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dueAt := time.Date(2025, 5, 31, 9, 0, 0, 0, time.UTC)
+	crepo := mock_cache.NewMockTaskList(ctrl)
+	crepo.EXPECT().GetTaskByID(1, 10).Return(entity.Task{
+		ID:         10,
+		DueAt:      &dueAt,
+		Recurrence: "FREQ=MONTHLY;BYMONTHDAY=-1",
+	}, nil)
+
+	s := NewTaskService(crepo)
+	s.now = func() time.Time { return time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC) }
+
+	occurrences, err := s.GetOccurrences(1, 10, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 6, 30, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 8, 31, 9, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestGetTaskPage(t *testing.T) {
+	tasks := []entity.Task{
+		{ID: 1, Position: 1},
+		{ID: 2, Position: 2},
+		{ID: 3, Position: 3},
+	}
+
+	tests := []struct {
+		name          string
+		filter        entity.TaskFilter
+		mockBehavior  func(crepo *mock_cache.MockTaskList)
+		wantIDs       []int
+		wantCursor    *entity.TaskCursor
+		expectedError string
+	}{
+		{
+			name:   "Has Next Page",
+			filter: entity.TaskFilter{Limit: 2},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				// просим на одну задачу больше, чтобы узнать про следующую страницу
+				crepo.EXPECT().GetAllTask(1, entity.TaskFilter{Limit: 3}).Return(tasks, nil)
+			},
+			wantIDs:    []int{1, 2},
+			wantCursor: &entity.TaskCursor{Order: entity.OrderPosition, ID: 2, Position: 2},
+		},
+		{
+			name:   "Last Page",
+			filter: entity.TaskFilter{Limit: 5},
+			mockBehavior: func(crepo *mock_cache.MockTaskList) {
+				crepo.EXPECT().GetAllTask(1, entity.TaskFilter{Limit: 6}).Return(tasks, nil)
+			},
+			wantIDs: []int{1, 2, 3},
+		},
+		{
+			name: "Cursor Of Other Order",
+			filter: entity.TaskFilter{
+				Limit: 2,
+				Order: entity.OrderPriority,
+				After: &entity.TaskCursor{Order: entity.OrderPosition, ID: 2},
+			},
+			mockBehavior:  func(crepo *mock_cache.MockTaskList) {},
+			expectedError: "Cursor does not match sort order",
+		},
+		{
+			name:          "Too Big Page",
+			filter:        entity.TaskFilter{Limit: 1000},
+			mockBehavior:  func(crepo *mock_cache.MockTaskList) {},
+			expectedError: "Invalid page size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockTaskList(ctrl)
+			tt.mockBehavior(crepo)
+
+			page, err := NewTaskService(crepo).GetTaskPage(1, tt.filter)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var ids []int
+			for _, task := range page.Tasks {
+				ids = append(ids, task.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+
+			if tt.wantCursor == nil {
+				assert.Empty(t, page.NextCursor)
+				return
+			}
+			cursor, err := entity.DecodeTaskCursor(page.NextCursor)
+			assert.NoError(t, err)
+			assert.Equal(t, *tt.wantCursor, cursor)
+		})
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC)
+	remindAt := dueAt.Add(-time.Hour)
+	projectID := 3
+
+	current := func() entity.Task {
+		due, remind := dueAt, remindAt
+		return entity.Task{
+			ID:          10,
+			Description: "Gym",
+			Status:      entity.StatusTodo,
+			DueAt:       &due,
+			RemindAt:    &remind,
+			Priority:    4,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		doc        string
+		task       func() entity.Task
+		want       func() entity.Task
+		wantFields entity.FieldErrors
+	}{
+		{
+			name: "Several Fields",
+			doc:  `{"description":"Swim","priority":1,"project_id":3,"due_at":"2025-05-08T15:00:00+03:00","remind_at":null,"status":"done"}`,
+			task: current,
+			want: func() entity.Task {
+				task := current()
+				task.Description = "Swim"
+				task.Priority = 1
+				task.ProjectID = &projectID
+				due := time.Date(2025, 5, 8, 12, 0, 0, 0, time.UTC)
+				task.DueAt = &due
+				task.RemindAt = nil
+				task.Status = entity.StatusDone
+				task.CompletedAt = &now
+				return task
+			},
+		},
+		{
+			name: "Recurrence Normalized",
+			doc:  `{"recurrence":"byday=MO;freq=WEEKLY"}`,
+			task: current,
+			want: func() entity.Task {
+				task := current()
+				task.Recurrence = "FREQ=WEEKLY;BYDAY=MO"
+				return task
+			},
+		},
+		{
+			name: "Reopen Clears Completion",
+			doc:  `{"status":"todo"}`,
+			task: func() entity.Task {
+				task := current()
+				task.Status = entity.StatusDone
+				task.CompletedAt = &now
+				return task
+			},
+			want: current,
+		},
+		{
+			name: "All Invalid Fields Reported",
+			doc:  `{"description":"","priority":7,"status":null,"parent_id":10,"remind_at":"2025-05-09T00:00:00Z","recurrence":"FREQ=SOMETIMES"}`,
+			task: current,
+			wantFields: entity.FieldErrors{
+				"description": "must be 1 to 999 bytes long",
+				"priority":    "must be from 1 to 4",
+				"status":      "must not be null",
+				"parent_id":   "must be an id of another task",
+				"remind_at":   "reminder must not be later than due date",
+				"recurrence":  "invalid rule: unsupported frequency SOMETIMES",
+			},
+		},
+		{
+			name: "Due Date Of Recurring Task Cleared",
+			doc:  `{"due_at":null,"remind_at":null}`,
+			task: func() entity.Task {
+				task := current()
+				task.Recurrence = "FREQ=DAILY"
+				return task
+			},
+			wantFields: entity.FieldErrors{"due_at": "recurring task requires a due date"},
+		},
+		{
+			name:       "Due Date Cleared Under Reminder",
+			doc:        `{"due_at":null}`,
+			task:       current,
+			wantFields: entity.FieldErrors{"due_at": "reminder requires a due date"},
+		},
+		{
+			name: "Recurring Task Done",
+			doc:  `{"status":"done"}`,
+			task: func() entity.Task {
+				task := current()
+				task.Recurrence = "FREQ=DAILY"
+				return task
+			},
+			wantFields: entity.FieldErrors{"status": "recurring task is completed with POST /api/{id}/complete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			patch, err := entity.ParseTaskPatch([]byte(tt.doc))
+			assert.NoError(t, err)
+
+			task := tt.task()
+			crepo := mock_cache.NewMockTaskList(ctrl)
+			crepo.EXPECT().PatchTask(1, 10, 2, gomock.Any()).DoAndReturn(
+				func(_, _, _ int, apply func(task *entity.Task) error) error {
+					return apply(&task)
+				})
+
+			s := NewTaskService(crepo)
+			s.now = func() time.Time { return now }
+
+			err = s.PatchTask(1, 10, patch, 2)
+
+			if tt.wantFields != nil {
+				assert.Equal(t, tt.wantFields, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want(), task)
+		})
+	}
+}
+
+func TestParseTaskPatch(t *testing.T) {
+	patch, err := entity.ParseTaskPatch([]byte(`{"description":"Swim","due_at":null}`))
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PatchField[string]{Set: true, Value: func() *string { s := "Swim"; return &s }()}, patch.Description)
+	assert.Equal(t, entity.PatchField[time.Time]{Set: true}, patch.DueAt)
+	assert.False(t, patch.Priority.Set)
+
+	_, err = entity.ParseTaskPatch([]byte(`{"priority":"high","version":3,"colour":"red"}`))
+	assert.Equal(t, entity.FieldErrors{
+		"priority": "invalid value type",
+		"version":  "field is read-only",
+		"colour":   "unknown field",
+	}, err)
+
+	_, err = entity.ParseTaskPatch([]byte(`[{"op":"replace"}]`))
+	assert.Equal(t, entity.ErrPatchDocument, err)
+}
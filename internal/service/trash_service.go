@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AronditFire/todo-app/entity"
+	"github.com/AronditFire/todo-app/internal/cache"
+)
+
+// DefaultTrashRetention is how long deleted tasks stay in the trash
+// when TRASH_RETENTION is not set.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashService struct {
+	crepo     cache.Trash
+	blobs     BlobCleaner // файлы вложений удаляются после удаления задач
+	retention time.Duration
+	now       func() time.Time // подменяется в тестах
+}
+
+func NewTrashService(crepo cache.Trash, blobs BlobCleaner, retention time.Duration) *TrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+
+	return &TrashService{
+		crepo:     crepo,
+		blobs:     blobs,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func (s *TrashService) GetTrash(userID int) ([]entity.Task, error) {
+	return s.crepo.GetTrash(userID)
+}
+
+func (s *TrashService) RestoreTask(userID, taskID int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to restore task")
+	}
+
+	return s.crepo.RestoreTask(userID, taskID)
+}
+
+func (s *TrashService) PurgeTask(userID, taskID int) error {
+	if taskID <= 0 {
+		return errors.New("Invalid id while trying to purge task")
+	}
+
+	if err := s.crepo.PurgeTask(userID, taskID); err != nil {
+		return err
+	}
+
+	cleanupBlobs(s.blobs)
+	return nil
+}
+
+func (s *TrashService) EmptyTrash(userID int) error {
+	if err := s.crepo.EmptyTrash(userID); err != nil {
+		return err
+	}
+
+	cleanupBlobs(s.blobs)
+	return nil
+}
+
+// PurgeExpired deletes tasks that have been in the trash longer than the retention
+// period. Blobs left queued by earlier failures are retried here as well.
+func (s *TrashService) PurgeExpired() (int64, error) {
+	purged, err := s.crepo.PurgeExpired(s.now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	cleanupBlobs(s.blobs)
+	return purged, nil
+}
+
+// RunTrashPurge empties expired trash right away and then every interval
+// until ctx is cancelled. Errors are only logged, the next run tries again.
+func RunTrashPurge(ctx context.Context, trash Trash, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := trash.PurgeExpired()
+		if err != nil {
+			log.Printf("Could not purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d tasks from trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
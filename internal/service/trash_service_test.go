@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	mock_cache "github.com/AronditFire/todo-app/internal/cache/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeExpired(t *testing.T) {
+	now := time.Date(2025, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		retention  time.Duration
+		wantBefore time.Time
+	}{
+		{
+			name:       "Configured Retention",
+			retention:  7 * 24 * time.Hour,
+			wantBefore: time.Date(2025, 5, 24, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Default Retention",
+			retention:  0,
+			wantBefore: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crepo := mock_cache.NewMockTrash(ctrl)
+			crepo.EXPECT().PurgeExpired(tt.wantBefore).Return(int64(2), nil)
+			blobs := &countingCleaner{}
+
+			s := NewTrashService(crepo, blobs, tt.retention)
+			s.now = func() time.Time { return now }
+
+			purged, err := s.PurgeExpired()
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), purged)
+			assert.Equal(t, 1, blobs.calls, "queued blobs are deleted after a purge")
+		})
+	}
+}
+
+type countingCleaner struct {
+	calls int
+}
+
+func (c *countingCleaner) CleanupBlobs() (int, error) {
+	c.calls++
+	return 0, nil
+}
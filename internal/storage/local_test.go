@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "tasks/5/abc", strings.NewReader("hello"), 5, "text/plain"))
+
+	body, err := s.Get(ctx, "tasks/5/abc")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, s.Delete(ctx, "tasks/5/abc"))
+	require.NoError(t, s.Delete(ctx, "tasks/5/abc"), "deleting a missing blob is not an error")
+
+	_, err = s.Get(ctx, "tasks/5/abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "tasks//x"} {
+		assert.Error(t, s.Put(ctx, key, strings.NewReader(""), 0, ""), key)
+	}
+}
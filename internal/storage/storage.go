@@ -0,0 +1,27 @@
+// Package storage keeps blobs of task attachments outside of the database.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ErrNotFound is returned by Get when there is no blob with the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Storage is a flat key-value store for blobs. Keys are slash separated
+// paths like "tasks/5/3f2a...", deleting a missing blob is not an error.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func validKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return errors.New("invalid blob key")
+	}
+	return nil
+}